@@ -0,0 +1,448 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/maxshend/grader/pkg/assignments"
+)
+
+const apiPrefix = "/api/v1"
+
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+type Assignment struct {
+	ID          int64                       `json:"id"`
+	CourseID    int64                       `json:"course_id"`
+	Title       string                      `json:"title"`
+	Description string                      `json:"description"`
+	Files       assignments.FileSpecs       `json:"files"`
+	Archive     bool                        `json:"archive"`
+	AllowGit    bool                        `json:"allow_git"`
+	MaxAttempts int                         `json:"max_attempts"`
+	MaxScore    float64                     `json:"max_score"`
+	Published   bool                        `json:"published"`
+	Open        bool                        `json:"open"`
+	DueAt       *time.Time                  `json:"due_at"`
+	LateDueAt   *time.Time                  `json:"late_due_at"`
+	Limits      *assignments.ResourceLimits `json:"limits"`
+}
+
+type CatalogEntry struct {
+	Assignment     *Assignment `json:"assignment"`
+	CourseTitle    string      `json:"course_title"`
+	Attempts       int         `json:"attempts"`
+	LastSubmission *Submission `json:"last_submission"`
+}
+
+type TestResult struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration"`
+	Message  string  `json:"message"`
+}
+
+type Submission struct {
+	ID           int64         `json:"id"`
+	AssignmentID int64         `json:"assignment_id"`
+	Status       int           `json:"status"`
+	StatusName   string        `json:"status_name"`
+	Finished     bool          `json:"finished"`
+	Score        float64       `json:"score"`
+	MaxScore     float64       `json:"max_score"`
+	Late         bool          `json:"late"`
+	CommitSHA    string        `json:"commit_sha"`
+	Details      string        `json:"details"`
+	ExitCode     *int          `json:"exit_code"`
+	Duration     float64       `json:"duration"`
+	Stdout       string        `json:"stdout"`
+	Stderr       string        `json:"stderr"`
+	TestResults  []*TestResult `json:"test_results"`
+}
+
+type Validation struct {
+	Kind       string      `json:"kind"`
+	Passed     bool        `json:"passed"`
+	Submission *Submission `json:"submission"`
+}
+
+type Validations struct {
+	Validated bool          `json:"validated"`
+	Data      []*Validation `json:"data"`
+}
+
+// Finished reports whether all the validation runs got their results
+func (v *Validations) Finished() bool {
+	for _, validation := range v.Data {
+		if !validation.Submission.Finished {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Pagination struct {
+	CurrentPage int  `json:"current_page"`
+	MaxPage     int  `json:"max_page"`
+	LastPage    bool `json:"last_page"`
+}
+
+type apiError struct {
+	Error *struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Assignments returns all published assignments of the courses of the user
+func (c *Client) Assignments() ([]*CatalogEntry, error) {
+	result := []*CatalogEntry{}
+	for page := 1; ; page++ {
+		list := &struct {
+			Data       []*CatalogEntry `json:"data"`
+			Pagination *Pagination     `json:"pagination"`
+		}{}
+		err := c.do("GET", fmt.Sprintf("%s/assignments?page=%d", apiPrefix, page), nil, "", list)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list.Data...)
+
+		if list.Pagination == nil || list.Pagination.LastPage {
+			return result, nil
+		}
+	}
+}
+
+func (c *Client) Assignment(id int64) (*Assignment, error) {
+	assignment := &Assignment{}
+	err := c.do("GET", fmt.Sprintf("%s/assignments/%d", apiPrefix, id), nil, "", assignment)
+
+	return assignment, err
+}
+
+// Submit uploads the files of the assignment from the directory
+func (c *Client) Submit(assignment *Assignment, dir string) (*Submission, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	if err := addSolutionParts(form, assignment, "", dir); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	submission := &Submission{}
+	err := c.do(
+		"POST",
+		fmt.Sprintf("%s/assignments/%d/submissions", apiPrefix, assignment.ID),
+		body,
+		form.FormDataContentType(),
+		submission,
+	)
+
+	return submission, err
+}
+
+// SubmitGit asks the server to fetch the files of the assignment from the
+// repository at the ref, the default branch is used when the ref is empty
+func (c *Client) SubmitGit(assignmentID int64, repositoryURL, ref string) (*Submission, error) {
+	data, err := json.Marshal(map[string]string{"repository_url": repositoryURL, "ref": ref})
+	if err != nil {
+		return nil, err
+	}
+
+	submission := &Submission{}
+	err = c.do(
+		"POST",
+		fmt.Sprintf("%s/assignments/%d/submissions", apiPrefix, assignmentID),
+		bytes.NewReader(data),
+		"application/json",
+		submission,
+	)
+
+	return submission, err
+}
+
+func (c *Client) Submission(assignmentID, id int64) (*Submission, error) {
+	submission := &Submission{}
+	err := c.do("GET", fmt.Sprintf("%s/assignments/%d/submissions/%d", apiPrefix, assignmentID, id), nil, "", submission)
+
+	return submission, err
+}
+
+// Wait polls the submission until it gets graded or the timeout expires
+func (c *Client) Wait(assignmentID, id int64, interval, timeout time.Duration) (*Submission, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		submission, err := c.Submission(assignmentID, id)
+		if err != nil {
+			return nil, err
+		}
+		if submission.Finished {
+			return submission, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return submission, fmt.Errorf("submission #%d isn't graded after %s", id, timeout)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// UploadSolutions sends the assignment files from the directories of the
+// reference and the known-bad solutions, an empty directory is skipped
+func (c *Client) UploadSolutions(assignment *Assignment, referenceDir, badDir string) (*Validations, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	for field, dir := range map[string]string{"reference_files": referenceDir, "bad_files": badDir} {
+		if len(dir) == 0 {
+			continue
+		}
+		if err := addSolutionParts(form, assignment, field, dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	validations := &Validations{}
+	err := c.do(
+		"POST",
+		fmt.Sprintf("%s/assignments/%d/validations", apiPrefix, assignment.ID),
+		body,
+		form.FormDataContentType(),
+		validations,
+	)
+
+	return validations, err
+}
+
+func (c *Client) Validations(assignmentID int64) (*Validations, error) {
+	validations := &Validations{}
+	err := c.do("GET", fmt.Sprintf("%s/assignments/%d/validations", apiPrefix, assignmentID), nil, "", validations)
+
+	return validations, err
+}
+
+// WaitValidations polls the validations until all of them get their results or the timeout expires
+func (c *Client) WaitValidations(assignmentID int64, interval, timeout time.Duration) (*Validations, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		validations, err := c.Validations(assignmentID)
+		if err != nil {
+			return nil, err
+		}
+		if validations.Finished() {
+			return validations, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return validations, fmt.Errorf("validations of assignment #%d aren't finished after %s", assignmentID, timeout)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// SaveAssignment creates the assignment of the spec or updates it when the spec has an id
+func (c *Client) SaveAssignment(spec *AssignmentSpec) (*Assignment, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	method, path := "POST", apiPrefix+"/assignments"
+	if spec.ID != 0 {
+		method, path = "PUT", fmt.Sprintf("%s/assignments/%d", apiPrefix, spec.ID)
+	}
+	assignment := &Assignment{}
+	err = c.do(method, path, bytes.NewReader(data), "application/json", assignment)
+
+	return assignment, err
+}
+
+func (c *Client) do(method, path string, body io.Reader, contentType string, result any) error {
+	request, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+c.Token)
+	request.Header.Set("Accept", "application/json")
+	if len(contentType) > 0 {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := c.HTTP.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		failure := &apiError{}
+		if err = json.NewDecoder(response.Body).Decode(failure); err != nil || failure.Error == nil {
+			return fmt.Errorf("%s %s: unexpected status %d", method, path, response.StatusCode)
+		}
+
+		return fmt.Errorf("%s (%d)", failure.Error.Message, failure.Error.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// addSolutionParts adds the files of the assignment from the directory, the
+// directory is packed into a zip archive for archive assignments. Parts are
+// named as the files unless the field is set.
+func addSolutionParts(form *multipart.Writer, assignment *Assignment, field, dir string) error {
+	if assignment.Archive {
+		if len(field) == 0 {
+			field = "archive"
+		}
+		data, err := zipDir(dir, assignment.Files)
+		if err != nil {
+			return err
+		}
+		part, err := form.CreateFormFile(field, "submission.zip")
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(data)
+
+		return err
+	}
+
+	names, err := matchingFiles(dir, assignment.Files)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		partField := field
+		if len(partField) == 0 {
+			partField = name
+		}
+		if err := addFilePart(form, partField, name, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchingFiles lists the files of the directory matching the specs, other
+// problems of the files are reported by the server
+func matchingFiles(dir string, specs assignments.FileSpecs) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	found := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || !entry.Type().IsRegular() {
+			continue
+		}
+		if len(specs) == 0 || specs.Find(name) != nil {
+			names = append(names, name)
+			found[name] = true
+		}
+	}
+	for _, spec := range specs {
+		if !spec.Optional && !spec.IsGlob() && !found[spec.Pattern] {
+			return nil, fmt.Errorf("assignment file %q: %w", spec.Pattern, fs.ErrNotExist)
+		}
+	}
+
+	return names, nil
+}
+
+// zipDir packs regular files of the directory matching any of the specs,
+// hidden files and directories like .git are skipped
+func zipDir(dir string, specs assignments.FileSpecs) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+		if len(specs) > 0 && specs.Find(name) == nil {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		part, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(content)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addFilePart adds the file of the assignment as a part of the form field
+func addFilePart(form *multipart.Writer, field, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("assignment file %q: %w", name, err)
+	}
+	defer file.Close()
+
+	part, err := form.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+
+	return err
+}
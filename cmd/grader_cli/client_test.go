@@ -0,0 +1,120 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maxshend/grader/pkg/assignments"
+	"github.com/maxshend/grader/pkg/submissions"
+)
+
+func TestClientSubmitAndWait(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"status":401,"message":"invalid token"}}`))
+			return
+		}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/assignments/1/submissions":
+			file, header, err := r.FormFile("main.go")
+			if err != nil {
+				t.Errorf("expected to have main.go in the form, got %v", err)
+			} else {
+				file.Close()
+				if header.Filename != "main.go" {
+					t.Errorf("expected to have filename main.go, got %s", header.Filename)
+				}
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(&Submission{ID: 3, AssignmentID: 1})
+		case r.Method == "GET" && r.URL.Path == "/api/v1/assignments/1/submissions/3":
+			polls++
+			submission := &Submission{ID: 3, AssignmentID: 1, StatusName: "In progress"}
+			if polls > 1 {
+				submission.Status = submissions.Success
+				submission.Finished = true
+				submission.Score = 1
+			}
+			json.NewEncoder(w).Encode(submission)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "secret")
+	assignment := &Assignment{ID: 1, Files: assignments.FileSpecs{{Pattern: "main.go"}}}
+	submission, err := client.Submit(assignment, dir)
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if submission.ID != 3 {
+		t.Errorf("expected to have submission #3, got #%d", submission.ID)
+	}
+
+	submission, err = client.Wait(1, 3, time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if !submission.Finished || submission.Status != submissions.Success {
+		t.Errorf("expected to have a graded submission, got %+v", submission)
+	}
+
+	_, err = NewClient(server.URL, "wrong").Submission(1, 3)
+	if err == nil || err.Error() != "invalid token (401)" {
+		t.Errorf("expected to have the API error, got %v", err)
+	}
+}
+
+func TestClientSubmitMissingFile(t *testing.T) {
+	client := NewClient("http://localhost", "secret")
+	_, err := client.Submit(&Assignment{ID: 1, Files: assignments.FileSpecs{{Pattern: "main.go"}}}, t.TempDir())
+	if err == nil {
+		t.Errorf("expected to have errors")
+	}
+}
+
+func TestZipDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "pkg/util.go", "notes.txt", ".git/config"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := zipDir(dir, assignments.FileSpecs{{Pattern: "**/*.go"}, {Pattern: "*/config"}})
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"main.go", "pkg/util.go"}) {
+		t.Errorf("expected to pack matching files only, got %v", names)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maxshend/grader/pkg/submissions"
+)
+
+const (
+	defaultURL          = "http://localhost:8080"
+	defaultWaitInterval = 2 * time.Second
+	defaultWaitTimeout  = 10 * time.Minute
+)
+
+const usage = `Usage: grader_cli [-url URL] [-token TOKEN] <command> [arguments]
+
+Commands:
+  assignments                                   list available assignments
+  submit -assignment ID [-dir DIR] [-wait]      submit files of the assignment from DIR
+         [-repo URL [-ref REF]]                 or from the git repository at REF
+  apply -f SPEC                                 create or update an assignment from a YAML spec
+  validate -assignment ID -reference DIR        validate the grader with the reference solution
+           [-bad DIR] [-wait]                   and optionally with a known-bad one
+
+The token and the url can be set with GRADER_TOKEN and GRADER_URL.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("grader_cli", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	baseURL := flags.String("url", envOrDefault("GRADER_URL", defaultURL), "grader URL")
+	token := flags.String("token", os.Getenv("GRADER_TOKEN"), "API token")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	if len(*token) == 0 {
+		fmt.Fprintln(stderr, "API token should be set with -token or GRADER_TOKEN")
+		return 2
+	}
+
+	client := NewClient(*baseURL, *token)
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	var err error
+	switch command {
+	case "assignments":
+		err = listAssignments(client, stdout)
+	case "submit":
+		return submit(client, commandArgs, stdout, stderr)
+	case "apply":
+		err = apply(client, commandArgs, stdout, stderr)
+	case "validate":
+		return validate(client, commandArgs, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", command)
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func listAssignments(client *Client, stdout io.Writer) error {
+	entries, err := client.Assignments()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		a := entry.Assignment
+		due := "no deadline"
+		if a.DueAt != nil {
+			due = "due " + a.DueAt.Local().Format(time.RFC1123)
+		}
+		last := "not submitted"
+		if entry.LastSubmission != nil {
+			last = fmt.Sprintf("last: %s %g/%g", entry.LastSubmission.StatusName, entry.LastSubmission.Score, entry.LastSubmission.MaxScore)
+		}
+		if a.MaxAttempts > 0 {
+			last += fmt.Sprintf(", attempts: %d/%d", entry.Attempts, a.MaxAttempts)
+		}
+		patterns := make([]string, len(a.Files))
+		for i, file := range a.Files {
+			patterns[i] = file.Pattern
+		}
+		fmt.Fprintf(stdout, "%d\t%s\t%s\t%s\t%s\tfiles: %s\n", a.ID, entry.CourseTitle, a.Title, due, last, strings.Join(patterns, ", "))
+	}
+
+	return nil
+}
+
+// submit returns a non-zero exit code unless the submission was accepted
+// (or graded as a success when waiting for the verdict)
+func submit(client *Client, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("submit", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	assignmentID := flags.Int64("assignment", 0, "assignment ID")
+	dir := flags.String("dir", ".", "directory with the assignment files")
+	repositoryURL := flags.String("repo", "", "git repository with the assignment files, used instead of -dir")
+	ref := flags.String("ref", "", "branch, tag or full commit hash of the repository, the default branch when empty")
+	wait := flags.Bool("wait", false, "wait for the verdict")
+	interval := flags.Duration("interval", defaultWaitInterval, "polling interval when waiting")
+	timeout := flags.Duration("timeout", defaultWaitTimeout, "maximum time to wait for the verdict")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *assignmentID <= 0 {
+		fmt.Fprintln(stderr, "-assignment should be set")
+		return 2
+	}
+
+	var submission *Submission
+	var err error
+	if len(*repositoryURL) > 0 {
+		submission, err = client.SubmitGit(*assignmentID, *repositoryURL, *ref)
+	} else {
+		var assignment *Assignment
+		if assignment, err = client.Assignment(*assignmentID); err == nil {
+			submission, err = client.Submit(assignment, *dir)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Submission #%d created\n", submission.ID)
+	if !*wait {
+		return 0
+	}
+
+	submission, err = client.Wait(*assignmentID, submission.ID, *interval, *timeout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	printSubmission(stdout, submission)
+	if submission.Status != submissions.Success {
+		return 1
+	}
+
+	return 0
+}
+
+func printSubmission(w io.Writer, submission *Submission) {
+	fmt.Fprintf(w, "Status: %s\n", submission.StatusName)
+	fmt.Fprintf(w, "Score: %g/%g\n", submission.Score, submission.MaxScore)
+	if submission.Late {
+		fmt.Fprintln(w, "Submitted late")
+	}
+	if len(submission.CommitSHA) > 0 {
+		fmt.Fprintf(w, "Commit: %s\n", submission.CommitSHA)
+	}
+	if len(submission.Details) > 0 {
+		fmt.Fprintf(w, "Details: %s\n", submission.Details)
+	}
+	if submission.ExitCode != nil {
+		fmt.Fprintf(w, "Exit code: %d (%.2fs)\n", *submission.ExitCode, submission.Duration)
+	}
+	if len(submission.TestResults) > 0 {
+		fmt.Fprintln(w, "Tests:")
+		for _, test := range submission.TestResults {
+			fmt.Fprintf(w, "  [%s] %s", test.Status, test.Name)
+			if len(test.Message) > 0 {
+				fmt.Fprintf(w, ": %s", test.Message)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if len(submission.Stdout) > 0 {
+		fmt.Fprintf(w, "Stdout:\n%s\n", submission.Stdout)
+	}
+	if len(submission.Stderr) > 0 {
+		fmt.Fprintf(w, "Stderr:\n%s\n", submission.Stderr)
+	}
+}
+
+func apply(client *Client, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	path := flags.String("f", "", "path to the assignment spec")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*path) == 0 {
+		return fmt.Errorf("-f should be set")
+	}
+
+	data, err := os.ReadFile(*path)
+	if err != nil {
+		return err
+	}
+	spec, err := ParseAssignmentSpec(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *path, err)
+	}
+	assignment, err := client.SaveAssignment(spec)
+	if err != nil {
+		return err
+	}
+
+	if spec.ID == 0 {
+		fmt.Fprintf(stdout, "Assignment #%d created, add \"id: %d\" to %s to update it later\n", assignment.ID, assignment.ID, *path)
+	} else {
+		fmt.Fprintf(stdout, "Assignment #%d updated\n", assignment.ID)
+	}
+
+	return nil
+}
+
+// validate returns a non-zero exit code unless the assignment can be published
+// (or the solutions were accepted when not waiting for the results)
+func validate(client *Client, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	assignmentID := flags.Int64("assignment", 0, "assignment ID")
+	referenceDir := flags.String("reference", "", "directory with the reference solution")
+	badDir := flags.String("bad", "", "directory with a solution which has to fail")
+	wait := flags.Bool("wait", false, "wait for the results")
+	interval := flags.Duration("interval", defaultWaitInterval, "polling interval when waiting")
+	timeout := flags.Duration("timeout", defaultWaitTimeout, "maximum time to wait for the results")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *assignmentID <= 0 || len(*referenceDir)+len(*badDir) == 0 {
+		fmt.Fprintln(stderr, "-assignment and -reference or -bad should be set")
+		return 2
+	}
+
+	assignment, err := client.Assignment(*assignmentID)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	validations, err := client.UploadSolutions(assignment, *referenceDir, *badDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if *wait {
+		validations, err = client.WaitValidations(assignment.ID, *interval, *timeout)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	for _, validation := range validations.Data {
+		result := "unexpected"
+		if validation.Passed {
+			result = "expected"
+		}
+		fmt.Fprintf(
+			stdout,
+			"%s solution: %s (%s), submission #%d\n",
+			validation.Kind,
+			validation.Submission.StatusName,
+			result,
+			validation.Submission.ID,
+		)
+	}
+	if !*wait {
+		return 0
+	}
+	if !validations.Validated {
+		fmt.Fprintln(stdout, "Assignment can't be published yet")
+		return 1
+	}
+	fmt.Fprintln(stdout, "Assignment can be published")
+
+	return 0
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); len(value) > 0 {
+		return value
+	}
+
+	return defaultValue
+}
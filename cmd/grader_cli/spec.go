@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxshend/grader/pkg/assignments"
+)
+
+// AssignmentSpec describes an assignment in a YAML file, the assignment is
+// created when the spec has no id and updated otherwise
+type AssignmentSpec struct {
+	ID              int64                       `json:"id,omitempty"`
+	CourseID        int64                       `json:"course_id"`
+	Title           string                      `json:"title"`
+	Description     string                      `json:"description"`
+	GraderURL       string                      `json:"grader_url"`
+	Container       string                      `json:"container"`
+	PartID          string                      `json:"part_id"`
+	Files           []SpecFile                  `json:"files"`
+	Archive         bool                        `json:"archive"`
+	AllowGit        bool                        `json:"allow_git"`
+	AllowParallel   bool                        `json:"allow_parallel"`
+	MaxAttempts     int                         `json:"max_attempts"`
+	CooldownSeconds int64                       `json:"cooldown_seconds"`
+	MaxScore        float64                     `json:"max_score"`
+	Limits          *assignments.ResourceLimits `json:"limits,omitempty"`
+	HideStderr      bool                        `json:"hide_stderr"`
+	Published       bool                        `json:"published"`
+	OpensAt         *time.Time                  `json:"opens_at,omitempty"`
+	DueAt           *time.Time                  `json:"due_at,omitempty"`
+	LateDueAt       *time.Time                  `json:"late_due_at,omitempty"`
+}
+
+// SpecFile is a file spec written as a line of the assignment form, for
+// example "*_test.go optional max=10KB ext=.go"
+type SpecFile assignments.FileSpec
+
+func (f *SpecFile) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err != nil {
+		return err
+	}
+	spec, err := assignments.ParseFileSpec(line)
+	if err != nil {
+		return err
+	}
+	*f = SpecFile(*spec)
+
+	return nil
+}
+
+func (f SpecFile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(assignments.FileSpec(f))
+}
+
+// ParseAssignmentSpec reads a spec, unknown keys are reported to catch typos
+func ParseAssignmentSpec(data []byte) (*AssignmentSpec, error) {
+	values, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &AssignmentSpec{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("invalid assignment spec: %w", err)
+	}
+
+	return spec, nil
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML supports the subset of YAML used by assignment specs: keys with
+// scalar values, inline ([a, b]) and block (- a) lists of scalars, nested maps
+// and literal (|) or folded (>) block scalars. Anchors, flow maps and
+// multi-document files aren't supported.
+func parseYAML(data []byte) (map[string]any, error) {
+	lines := []*yamlLine{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't be used for indentation", i+1)
+		}
+		lines = append(lines, &yamlLine{number: i + 1, indent: len(raw) - len(trimmed), text: trimmed})
+	}
+
+	p := &yamlParser{lines: lines}
+	p.skipBlank()
+	if p.pos < len(p.lines) && p.lines[p.pos].text == "---" {
+		p.pos++
+	}
+
+	result, err := p.parseMap(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+	}
+
+	return result, nil
+}
+
+type yamlParser struct {
+	lines []*yamlLine
+	pos   int
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) {
+		text := stripComment(p.lines[p.pos].text)
+		if len(strings.TrimSpace(text)) > 0 {
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *yamlParser) parseMap(indent int) (map[string]any, error) {
+	result := map[string]any{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			return result, nil
+		}
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			return result, nil
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+
+		text := strings.TrimSpace(stripComment(line.text))
+		if strings.HasPrefix(text, "- ") || text == "-" {
+			return nil, fmt.Errorf("line %d: unexpected list item", line.number)
+		}
+		colon := strings.Index(text, ":")
+		if colon <= 0 || (colon+1 < len(text) && text[colon+1] != ' ') {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		key := strings.TrimSpace(text[:colon])
+		rest := strings.TrimSpace(text[colon+1:])
+		if _, ok := result[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicated key %q", line.number, key)
+		}
+		p.pos++
+
+		var err error
+		switch {
+		case rest == "":
+			result[key], err = p.parseNested(indent)
+		case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+			result[key], err = p.parseBlockScalar(indent, rest)
+		default:
+			result[key], err = parseScalar(rest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+	}
+}
+
+// parseNested parses the value of a key without an inline value: a list,
+// a map or nothing when the next line isn't indented deeper
+func (p *yamlParser) parseNested(indent int) (any, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	line := p.lines[p.pos]
+	text := strings.TrimSpace(stripComment(line.text))
+	if strings.HasPrefix(text, "- ") || text == "-" {
+		if line.indent < indent {
+			return nil, nil
+		}
+
+		return p.parseList(line.indent)
+	}
+	if line.indent <= indent {
+		return nil, nil
+	}
+
+	return p.parseMap(line.indent)
+}
+
+func (p *yamlParser) parseList(indent int) ([]any, error) {
+	result := []any{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			return result, nil
+		}
+		line := p.lines[p.pos]
+		text := strings.TrimSpace(stripComment(line.text))
+		if line.indent != indent || !(strings.HasPrefix(text, "- ") || text == "-") {
+			return result, nil
+		}
+
+		item := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+		if strings.HasSuffix(item, ":") || strings.Contains(item, ": ") {
+			return nil, fmt.Errorf("line %d: lists of maps aren't supported", line.number)
+		}
+		value, err := parseScalar(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		result = append(result, value)
+		p.pos++
+	}
+}
+
+func (p *yamlParser) parseBlockScalar(indent int, header string) (string, error) {
+	folded := header[0] == '>'
+	chomping := strings.TrimSpace(header[1:])
+	if chomping != "" && chomping != "-" && chomping != "+" {
+		return "", fmt.Errorf("unsupported block scalar header %q", header)
+	}
+
+	lines := []string{}
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if len(line.text) == 0 {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if line.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = line.indent
+		}
+		if line.indent < blockIndent {
+			return "", fmt.Errorf("line %d: block is less indented than its first line", line.number)
+		}
+		lines = append(lines, strings.Repeat(" ", line.indent-blockIndent)+line.text)
+		p.pos++
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var value string
+	if folded {
+		value = foldLines(lines)
+	} else {
+		value = strings.Join(lines, "\n")
+	}
+	switch chomping {
+	case "":
+		value += "\n"
+	case "+":
+		value += strings.Repeat("\n", trailing+1)
+	}
+
+	return value, nil
+}
+
+// foldLines joins lines with spaces, empty lines become line breaks
+func foldLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		switch {
+		case line == "":
+			b.WriteString("\n")
+		case i > 0 && lines[i-1] != "":
+			b.WriteString(" ")
+			b.WriteString(line)
+		default:
+			b.WriteString(line)
+		}
+	}
+
+	return b.String()
+}
+
+func parseScalar(value string) (any, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return nil, fmt.Errorf("unterminated string %s", value)
+		}
+
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	case strings.HasPrefix(value, "["):
+		if !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("unterminated list %s", value)
+		}
+		result := []any{}
+		inner := strings.TrimSpace(value[1 : len(value)-1])
+		if len(inner) == 0 {
+			return result, nil
+		}
+		for _, item := range strings.Split(inner, ",") {
+			parsed, err := parseScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, parsed)
+		}
+
+		return result, nil
+	case strings.HasPrefix(value, "{"):
+		return nil, fmt.Errorf("flow maps aren't supported")
+	}
+
+	switch value {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, nil
+	}
+
+	return value, nil
+}
+
+// stripComment removes a trailing comment which is outside of quotes
+func stripComment(text string) string {
+	var quote rune
+	for i, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maxshend/grader/pkg/assignments"
+)
+
+func TestParseAssignmentSpec(t *testing.T) {
+	dueAt := time.Date(2023, 5, 1, 23, 59, 0, 0, time.UTC)
+
+	type testCase struct {
+		Title    string
+		Spec     string
+		Expected *AssignmentSpec
+		Error    bool
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "full spec",
+			Spec: `---
+# homework
+id: 7
+course_id: 2
+title: "Linked lists: part 1"
+description: |
+  Implement a linked list.
+
+  Use "main.go".
+grader_url: http://grader.local/run # the runner
+container: golang:1.19
+part_id: lists
+files:
+  - main.go
+  - 'list.go'
+  - "*_test.go optional max=10KB ext=.go"
+max_score: 10.5
+limits:
+  cpus: 0.5
+  memory_mb: 128
+  timeout_seconds: 30
+hide_stderr: true
+published: false
+due_at: 2023-05-01T23:59:00Z
+late_due_at: ~
+`,
+			Expected: &AssignmentSpec{
+				ID:          7,
+				CourseID:    2,
+				Title:       "Linked lists: part 1",
+				Description: "Implement a linked list.\n\nUse \"main.go\".\n",
+				GraderURL:   "http://grader.local/run",
+				Container:   "golang:1.19",
+				PartID:      "lists",
+				Files: []SpecFile{
+					{Pattern: "main.go"},
+					{Pattern: "list.go"},
+					{Pattern: "*_test.go", Optional: true, MaxSize: 10 * 1024, Extensions: []string{".go"}},
+				},
+				MaxScore:   10.5,
+				Limits:     &assignments.ResourceLimits{CPUs: 0.5, MemoryMB: 128, TimeoutSeconds: 30},
+				HideStderr: true,
+				DueAt:      &dueAt,
+			},
+		},
+		{
+			Title: "inline list and folded description",
+			Spec:  "title: Sorting\ndescription: >-\n  Sort the\n  numbers\nfiles: [main.go, \"sort.go\"]\n",
+			Expected: &AssignmentSpec{
+				Title:       "Sorting",
+				Description: "Sort the numbers",
+				Files:       []SpecFile{{Pattern: "main.go"}, {Pattern: "sort.go"}},
+			},
+		},
+		{Title: "unknown key", Spec: "titel: Sorting\n", Error: true},
+		{Title: "wrong type", Spec: "course_id: first\n", Error: true},
+		{Title: "duplicated key", Spec: "title: a\ntitle: b\n", Error: true},
+		{Title: "unexpected indentation", Spec: "title: a\n  course_id: 1\n", Error: true},
+		{Title: "list of maps", Spec: "files:\n  - name: main.go\n", Error: true},
+		{Title: "invalid file option", Spec: "files: [\"main.go max=big\"]\n", Error: true},
+		{Title: "tab indentation", Spec: "limits:\n\tcpus: 1\n", Error: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			spec, err := ParseAssignmentSpec([]byte(testCase.Spec))
+			if testCase.Error {
+				if err == nil {
+					t.Errorf("expected to have errors")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+
+			if !reflect.DeepEqual(spec, testCase.Expected) {
+				t.Errorf("expected to have %+v, got %+v", testCase.Expected, spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/sandbox"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/services"
+)
+
+// DryRun grades files of a local directory with the configured sandbox and
+// prints the results, it exits with 1 unless the submission passes
+func DryRun(args []string) int {
+	flags := flag.NewFlagSet("dry-run", flag.ContinueOnError)
+	image := flags.String("image", "", "grading container image")
+	partID := flags.String("part", "", "part ID passed to the grading script")
+	dir := flags.String("dir", ".", "directory with the submission files")
+	maxScore := flags.Float64("max-score", 0, "maximum score of the assignment")
+	limits := submission_tasks.ResourceLimits{}
+	flags.Int64Var(&limits.MemoryMB, "memory", 0, "memory limit in MB")
+	flags.Float64Var(&limits.CPUs, "cpus", 0, "CPU limit")
+	flags.Int64Var(&limits.Pids, "pids", 0, "processes limit")
+	flags.Int64Var(&limits.TmpfsMB, "tmpfs", 0, "size of /tmp in MB")
+	flags.Int64Var(&limits.TimeoutSeconds, "timeout", 0, "timeout in seconds")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if len(*image) == 0 {
+		fmt.Fprintln(os.Stderr, "-image should be set")
+		return 2
+	}
+	if info, err := os.Stat(*dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "%q is not a directory\n", *dir)
+		return 2
+	}
+
+	outputLimit := intFromEnv("RUNNER_OUTPUT_LIMIT", sandbox.DefaultOutputLimit)
+	backend, err := sandboxFromEnv(outputLimit)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	// Interrupted runs still have to release their containers
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	task := &submission_tasks.SubmissionTask{
+		Container: *image,
+		PartID:    *partID,
+		MaxScore:  *maxScore,
+		Limits:    limits,
+	}
+	service := services.NewSubmissionTaskService(backend, outputLimit)
+	result, err := service.DryRun(ctx, task, *dir)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Print(err)
+		return 1
+	}
+	if !result.Pass {
+		return 1
+	}
+
+	return 0
+}
@@ -1,30 +1,98 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 
 	"github.com/docker/docker/client"
 	"github.com/gorilla/mux"
+	jobsDelivery "github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/delivery"
+	jobsServices "github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/services"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/sandbox"
 	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/delivery"
 	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/services"
 )
 
-var dockerClient *client.Client
+const (
+	defaultWorkersCount = 2
+	defaultQueueSize    = 100
+)
 
 func Run() {
-	var err error
-	dockerClient, err = client.NewClientWithOpts(client.FromEnv)
+	workersCount := intFromEnv("RUNNER_WORKERS", defaultWorkersCount)
+	queueSize := intFromEnv("RUNNER_QUEUE_SIZE", defaultQueueSize)
+
+	outputLimit := intFromEnv("RUNNER_OUTPUT_LIMIT", sandbox.DefaultOutputLimit)
+
+	backend, err := sandboxFromEnv(outputLimit)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := backend.Prune(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 
-	service := services.NewSubmissionTaskService(dockerClient)
-	handler := delivery.NewSubmissionTasksHandler(service)
+	service := services.NewSubmissionTaskService(backend, outputLimit)
+	jobsService := jobsServices.NewJobsService(service, workersCount, queueSize)
+	handler := delivery.NewSubmissionTasksHandler(jobsService)
+	jobsHandler := jobsDelivery.NewJobsHandler(jobsService)
 
 	router := mux.NewRouter()
 
 	router.HandleFunc("/api/v1/grader", handler.Grade).Methods("POST")
+	router.HandleFunc("/api/v1/jobs/{id}", jobsHandler.Show).Methods("GET")
+	router.HandleFunc("/api/v1/jobs/{id}", jobsHandler.Cancel).Methods("DELETE")
 
 	log.Fatal(http.ListenAndServe(":8021", router))
 }
+
+// sandboxFromEnv selects the backend by RUNNER_SANDBOX, the local one runs
+// scripts from RUNNER_LOCAL_DIR instead of container images
+func sandboxFromEnv(outputLimit int) (sandbox.Sandbox, error) {
+	// RUNNER_ID has to be unique for runners sharing a Docker host or a temp dir
+	instanceID := os.Getenv("RUNNER_ID")
+	if len(instanceID) == 0 {
+		instanceID = sandbox.DefaultInstanceID()
+	}
+	if err := sandbox.ValidateInstanceID(instanceID); err != nil {
+		return nil, err
+	}
+
+	switch backend := os.Getenv("RUNNER_SANDBOX"); backend {
+	case "", "docker":
+		dockerClient, err := client.NewClientWithOpts(client.FromEnv)
+		if err != nil {
+			return nil, err
+		}
+
+		return sandbox.NewDockerSandbox(dockerClient, outputLimit, instanceID), nil
+	case "local":
+		dir := os.Getenv("RUNNER_LOCAL_DIR")
+		if len(dir) == 0 {
+			return nil, fmt.Errorf("RUNNER_LOCAL_DIR is required for the local sandbox")
+		}
+		log.Printf("Using the local sandbox, submissions are not isolated")
+
+		return sandbox.NewLocalSandbox(dir, outputLimit, instanceID)
+	default:
+		return nil, fmt.Errorf("unknown RUNNER_SANDBOX %q", backend)
+	}
+}
+
+func intFromEnv(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return defaultValue
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil || result <= 0 {
+		log.Fatalf("%s should be a positive integer", name)
+	}
+
+	return result
+}
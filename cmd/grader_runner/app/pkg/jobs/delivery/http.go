@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/services"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+type JobsHandler struct {
+	Service services.JobsServiceInterface
+}
+
+func NewJobsHandler(service services.JobsServiceInterface) *JobsHandler {
+	return &JobsHandler{
+		Service: service,
+	}
+}
+
+func (h *JobsHandler) Show(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.authorizedJob(w, r)
+	if !ok {
+		return
+	}
+
+	RenderJob(w, r, http.StatusOK, job)
+}
+
+func (h *JobsHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorizedJob(w, r); !ok {
+		return
+	}
+
+	job, err := h.Service.Cancel(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if job == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	RenderJob(w, r, http.StatusOK, job)
+}
+
+// authorizedJob finds the job and checks that the request carries the access token
+// of its submission task, otherwise it renders the error response
+func (h *JobsHandler) authorizedJob(w http.ResponseWriter, r *http.Request) (*jobs.Job, bool) {
+	job, err := h.Service.GetByID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return nil, false
+	}
+	if job == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+
+	token := r.Header.Get("Authorization")
+	if len(token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(job.AccessToken)) != 1 {
+		http.Error(w, utils.ErrInvalidAccessToken.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+
+	return job, true
+}
+
+func RenderJob(w http.ResponseWriter, r *http.Request, statusCode int, job *jobs.Job) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Can't write response: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/services"
+)
+
+func TestJobsAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := services.NewMockJobsServiceInterface(ctrl)
+	handler := NewJobsHandler(service)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/jobs/{id}", handler.Show).Methods("GET")
+	router.HandleFunc("/api/v1/jobs/{id}", handler.Cancel).Methods("DELETE")
+
+	job := &jobs.Job{ID: "1", Status: jobs.Running, AccessToken: "token"}
+
+	type testCase struct {
+		Title              string
+		Method             string
+		Token              string
+		SetupService       func()
+		ExpectedStatusCode int
+	}
+
+	testCases := []*testCase{
+		{
+			Title:              "show",
+			Method:             "GET",
+			Token:              "token",
+			SetupService:       func() { service.EXPECT().GetByID("1").Return(job, nil) },
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Title:              "show without token",
+			Method:             "GET",
+			SetupService:       func() { service.EXPECT().GetByID("1").Return(job, nil) },
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Title:              "show unknown job",
+			Method:             "GET",
+			Token:              "token",
+			SetupService:       func() { service.EXPECT().GetByID("1").Return(nil, nil) },
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Title:  "cancel",
+			Method: "DELETE",
+			Token:  "token",
+			SetupService: func() {
+				service.EXPECT().GetByID("1").Return(job, nil)
+				service.EXPECT().Cancel("1").Return(&jobs.Job{ID: "1", Status: jobs.Cancelled}, nil)
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Title:              "cancel with invalid token",
+			Method:             "DELETE",
+			Token:              "other",
+			SetupService:       func() { service.EXPECT().GetByID("1").Return(job, nil) },
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			req := httptest.NewRequest(testCase.Method, "/api/v1/jobs/1", nil)
+			if len(testCase.Token) != 0 {
+				req.Header.Set("Authorization", testCase.Token)
+			}
+			w := httptest.NewRecorder()
+
+			testCase.SetupService()
+
+			router.ServeHTTP(w, req)
+
+			statusCode := w.Result().StatusCode
+			if statusCode != testCase.ExpectedStatusCode {
+				t.Errorf("expected to have status code %d, got %d", testCase.ExpectedStatusCode, statusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import "time"
+
+const (
+	Queued    = "queued"
+	Running   = "running"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+	Cancelled = "cancelled"
+)
+
+type Job struct {
+	ID           string     `json:"id"`
+	SubmissionID int64      `json:"submission_id"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	// AccessToken of the submission task, required to poll or cancel the job
+	AccessToken string `json:"-"`
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == Succeeded || j.Status == Failed || j.Status == Cancelled
+}
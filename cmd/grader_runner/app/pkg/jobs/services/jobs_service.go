@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+	submissionTasksServices "github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/services"
+)
+
+const DefaultRetention = time.Hour
+
+var ErrQueueFull = errors.New("grading queue is full")
+
+type JobsServiceInterface interface {
+	Enqueue(*submission_tasks.SubmissionTask) (*jobs.Job, error)
+	GetByID(string) (*jobs.Job, error)
+	Cancel(string) (*jobs.Job, error)
+}
+
+// JobsService runs submission tasks in a bounded pool of workers and keeps
+// the state of finished jobs around for Retention so they can be polled.
+type JobsService struct {
+	Runner    submissionTasksServices.SubmissionTaskServiceInterface
+	Retention time.Duration
+	queue     chan *jobEntry
+	jobs      map[string]*jobEntry
+	mx        sync.Mutex
+}
+
+type jobEntry struct {
+	job    *jobs.Job
+	task   *submission_tasks.SubmissionTask
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewJobsService(
+	runner submissionTasksServices.SubmissionTaskServiceInterface,
+	workersCount int,
+	queueSize int,
+) *JobsService {
+	s := &JobsService{
+		Runner:    runner,
+		Retention: DefaultRetention,
+		queue:     make(chan *jobEntry, queueSize),
+		jobs:      make(map[string]*jobEntry),
+	}
+
+	for i := 0; i < workersCount; i++ {
+		go s.work()
+	}
+
+	return s
+}
+
+func (s *JobsService) Enqueue(task *submission_tasks.SubmissionTask) (*jobs.Job, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	entry := &jobEntry{
+		job: &jobs.Job{
+			ID:           uuid.NewString(),
+			SubmissionID: task.SubmissionID,
+			Status:       jobs.Queued,
+			CreatedAt:    time.Now(),
+			AccessToken:  task.AccessToken,
+		},
+		task:   task,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.prune()
+
+	select {
+	case s.queue <- entry:
+		s.jobs[entry.job.ID] = entry
+	default:
+		cancel()
+		return nil, ErrQueueFull
+	}
+
+	return copyJob(entry.job), nil
+}
+
+func (s *JobsService) GetByID(id string) (*jobs.Job, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyJob(entry.job), nil
+}
+
+func (s *JobsService) Cancel(id string) (*jobs.Job, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	if !entry.job.IsFinished() {
+		s.finish(entry, jobs.Cancelled, nil)
+	}
+
+	return copyJob(entry.job), nil
+}
+
+func (s *JobsService) work() {
+	for entry := range s.queue {
+		s.run(entry)
+	}
+}
+
+func (s *JobsService) run(entry *jobEntry) {
+	s.mx.Lock()
+	if entry.job.Status != jobs.Queued {
+		s.mx.Unlock()
+		return
+	}
+	now := time.Now()
+	entry.job.Status = jobs.Running
+	entry.job.StartedAt = &now
+	s.mx.Unlock()
+
+	err := s.Runner.RunSubmission(entry.ctx, entry.task)
+	if err != nil {
+		log.Printf("Job %s for submission #%d failed: %v", entry.job.ID, entry.job.SubmissionID, err)
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	// The job could have been cancelled while running
+	if entry.job.IsFinished() {
+		return
+	}
+	if err != nil {
+		s.finish(entry, jobs.Failed, err)
+	} else {
+		s.finish(entry, jobs.Succeeded, nil)
+	}
+}
+
+// finish should be called with the lock held
+func (s *JobsService) finish(entry *jobEntry, status string, err error) {
+	now := time.Now()
+	entry.job.Status = status
+	entry.job.FinishedAt = &now
+	if err != nil {
+		entry.job.Error = err.Error()
+	}
+	entry.cancel()
+}
+
+// prune should be called with the lock held
+func (s *JobsService) prune() {
+	for id, entry := range s.jobs {
+		if entry.job.IsFinished() && time.Since(*entry.job.FinishedAt) > s.Retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func copyJob(job *jobs.Job) *jobs.Job {
+	result := *job
+
+	return &result
+}
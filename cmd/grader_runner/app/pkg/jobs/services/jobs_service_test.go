@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+	submissionTasksServices "github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/services"
+)
+
+func TestJobsService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	runner := submissionTasksServices.NewMockSubmissionTaskServiceInterface(ctrl)
+
+	t.Run("success", func(t *testing.T) {
+		service := NewJobsService(runner, 1, 1)
+		task := &submission_tasks.SubmissionTask{SubmissionID: 1}
+		runner.EXPECT().RunSubmission(gomock.Any(), task).Return(nil)
+
+		job, err := service.Enqueue(task)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		if job.Status != jobs.Queued || job.SubmissionID != task.SubmissionID {
+			t.Errorf("expected queued job for submission #%d, got %+v", task.SubmissionID, job)
+		}
+
+		job = waitForJob(t, service, job.ID)
+		if job.Status != jobs.Succeeded {
+			t.Errorf("expected job to succeed, got %+v", job)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		service := NewJobsService(runner, 1, 1)
+		task := &submission_tasks.SubmissionTask{SubmissionID: 2}
+		runner.EXPECT().RunSubmission(gomock.Any(), task).Return(errors.New("docker error"))
+
+		job, err := service.Enqueue(task)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+
+		job = waitForJob(t, service, job.ID)
+		if job.Status != jobs.Failed || job.Error != "docker error" {
+			t.Errorf("expected job to fail, got %+v", job)
+		}
+	})
+
+	t.Run("cancel running job", func(t *testing.T) {
+		service := NewJobsService(runner, 1, 1)
+		task := &submission_tasks.SubmissionTask{SubmissionID: 3}
+		started := make(chan struct{})
+		runner.EXPECT().RunSubmission(gomock.Any(), task).DoAndReturn(
+			func(ctx context.Context, task *submission_tasks.SubmissionTask) error {
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+
+		job, err := service.Enqueue(task)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		<-started
+
+		job, err = service.Cancel(job.ID)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		if job.Status != jobs.Cancelled {
+			t.Errorf("expected job to be cancelled, got %+v", job)
+		}
+
+		job = waitForJob(t, service, job.ID)
+		if job.Status != jobs.Cancelled {
+			t.Errorf("expected job to stay cancelled, got %+v", job)
+		}
+	})
+
+	t.Run("queue is full", func(t *testing.T) {
+		service := NewJobsService(runner, 0, 1)
+
+		queued, err := service.Enqueue(&submission_tasks.SubmissionTask{})
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		_, err = service.Enqueue(&submission_tasks.SubmissionTask{})
+		if err != ErrQueueFull {
+			t.Errorf("expected to have %v, got %v", ErrQueueFull, err)
+		}
+
+		job, err := service.Cancel(queued.ID)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		if job.Status != jobs.Cancelled {
+			t.Errorf("expected job to be cancelled, got %+v", job)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := NewJobsService(runner, 0, 1)
+
+		job, err := service.GetByID("unknown")
+		if err != nil || job != nil {
+			t.Errorf("expected to have no job and no errors, got %+v, %v", job, err)
+		}
+	})
+}
+
+func waitForJob(t *testing.T, service *JobsService, id string) *jobs.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		job, err := service.GetByID(id)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		if job.IsFinished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s didn't finish in time", id)
+	return nil
+}
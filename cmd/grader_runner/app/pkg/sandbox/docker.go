@@ -0,0 +1,265 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+const (
+	containerSrcDir     = "/app/src"
+	containerReportsDir = "/app/reports"
+	containerTmpfsDir   = "/tmp"
+	followGrace         = time.Second
+	// Containers of the runner are labeled to find them after crashes
+	managedLabel      = "grader.managed"
+	instanceLabel     = "grader.instance"
+	submissionIDLabel = "grader.submission_id"
+)
+
+type DockerClientInterface interface {
+	client.CommonAPIClient
+}
+
+// DockerSandbox runs grading scripts inside of the task container image
+type DockerSandbox struct {
+	DockerClient DockerClientInterface
+	OutputLimit  int
+	// InstanceID labels containers of the runner, only they are pruned
+	InstanceID string
+}
+
+func NewDockerSandbox(dockerClient DockerClientInterface, outputLimit int, instanceID string) *DockerSandbox {
+	return &DockerSandbox{
+		DockerClient: dockerClient,
+		OutputLimit:  outputLimit,
+		InstanceID:   instanceID,
+	}
+}
+
+func (s *DockerSandbox) Prepare(ctx context.Context, task *submission_tasks.SubmissionTask) (*Workspace, error) {
+	return newWorkspace(s.InstanceID, task)
+}
+
+func (s *DockerSandbox) Run(ctx context.Context, ws *Workspace) (*RunResult, error) {
+	resp, err := s.createContainer(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+	ws.containerID = resp.ID
+
+	if err := s.DockerClient.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, err
+	}
+	startedAt := time.Now()
+	defer s.followLogs(ctx, ws)()
+
+	statusCh, errCh := s.DockerClient.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	containerTimer := time.NewTimer(Timeout(ws.Task.Limits))
+	defer containerTimer.Stop()
+
+	select {
+	case err := <-errCh:
+		return nil, err
+	case status := <-statusCh:
+		result := &RunResult{ExitCode: int(status.StatusCode), Duration: time.Since(startedAt)}
+		if result.ExitCode == 0 {
+			return result, nil
+		}
+
+		inspect, err := s.DockerClient.ContainerInspect(ctx, resp.ID)
+		if err != nil {
+			return nil, err
+		}
+		if inspect.ContainerJSONBase != nil && inspect.State != nil && inspect.State.OOMKilled {
+			result.Verdict = submission_tasks.VerdictOOM
+		}
+
+		return result, nil
+	case <-containerTimer.C:
+		return &RunResult{ExitCode: -1, Duration: time.Since(startedAt), Verdict: submission_tasks.VerdictTimeout}, nil
+	}
+}
+
+func (s *DockerSandbox) Output(ctx context.Context, ws *Workspace) (*Output, error) {
+	containerOut, err := s.DockerClient.ContainerLogs(
+		ctx,
+		ws.containerID,
+		types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer containerOut.Close()
+
+	// Containers run without a TTY so both streams are multiplexed into the logs
+	stdout, stderr := newLimitedBuffer(s.OutputLimit), newLimitedBuffer(s.OutputLimit)
+	if _, err := stdcopy.StdCopy(stdout, stderr, containerOut); err != nil {
+		return nil, err
+	}
+
+	return &Output{Stdout: stdout.String(), Stderr: stderr.String()}, nil
+}
+
+// followLogs copies stdout of the running container into the LogWriter,
+// the returned function waits for the rest of the output for followGrace and
+// stops following
+func (s *DockerSandbox) followLogs(ctx context.Context, ws *Workspace) (stop func()) {
+	if ws.LogWriter == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		logs, err := s.DockerClient.ContainerLogs(
+			ctx,
+			ws.containerID,
+			types.ContainerLogsOptions{ShowStdout: true, Follow: true},
+		)
+		if err != nil {
+			log.Printf("Can't follow logs of container %s: %v", ws.containerID, err)
+			return
+		}
+		defer logs.Close()
+
+		if _, err := stdcopy.StdCopy(ws.LogWriter, io.Discard, logs); err != nil && ctx.Err() == nil {
+			log.Printf("Can't follow logs of container %s: %v", ws.containerID, err)
+		}
+	}()
+
+	return func() {
+		timer := time.NewTimer(followGrace)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+		}
+		cancel()
+		<-done
+	}
+}
+
+func (s *DockerSandbox) Cleanup(ws *Workspace) error {
+	defer removeWorkspace(ws)
+
+	if len(ws.containerID) == 0 {
+		return nil
+	}
+
+	// The context of the run may be already cancelled at this point but the container still has to be removed
+	return s.removeContainer(context.Background(), ws.containerID)
+}
+
+// Prune removes containers and workspaces of the instance, other runners on the
+// same Docker host keep their runs
+func (s *DockerSandbox) Prune(ctx context.Context) error {
+	containers, err := s.DockerClient.ContainerList(ctx, types.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", managedLabel+"=true"),
+			filters.Arg("label", instanceLabel+"="+s.InstanceID),
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, orphan := range containers {
+		log.Printf("Removing orphaned container %s of submission #%s", orphan.ID, orphan.Labels[submissionIDLabel])
+		if err := s.removeContainer(ctx, orphan.ID); err != nil {
+			return err
+		}
+	}
+
+	return pruneWorkspaces(s.InstanceID)
+}
+
+// removeContainer kills the container if it is still running
+func (s *DockerSandbox) removeContainer(ctx context.Context, id string) error {
+	return s.DockerClient.ContainerRemove(ctx, id, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+}
+
+func (s *DockerSandbox) createContainer(ctx context.Context, ws *Workspace) (container.CreateResponse, error) {
+	task := ws.Task
+	containerName := fmt.Sprintf("run_submission_%d_%s", task.SubmissionID, uuid.NewString())
+
+	return s.DockerClient.ContainerCreate(
+		ctx,
+		&container.Config{
+			User:            "1000",
+			NetworkDisabled: true,
+			Image:           task.Container,
+			Cmd:             []string{"sh", fmt.Sprintf("%s.sh", task.PartID)},
+			Labels: map[string]string{
+				managedLabel:      "true",
+				instanceLabel:     s.InstanceID,
+				submissionIDLabel: strconv.FormatInt(task.SubmissionID, 10),
+			},
+			Env: []string{
+				fmt.Sprintf("%s=%s", SrcDirEnv, containerSrcDir),
+				fmt.Sprintf("%s=%s", ReportsDirEnv, containerReportsDir),
+			},
+		},
+		&container.HostConfig{
+			Resources: containerResources(task.Limits),
+			Tmpfs:     containerTmpfs(task.Limits),
+			Mounts: []mount.Mount{
+				{
+					Type:   mount.TypeBind,
+					Source: ws.SrcDir,
+					Target: containerSrcDir,
+				},
+				{
+					Type:   mount.TypeBind,
+					Source: ws.ReportsDir,
+					Target: containerReportsDir,
+				},
+			},
+		},
+		nil,
+		nil,
+		containerName,
+	)
+}
+
+func containerResources(limits submission_tasks.ResourceLimits) container.Resources {
+	resources := container.Resources{}
+	if limits.MemoryMB > 0 {
+		resources.Memory = limits.MemoryMB << 20
+		// Equal to Memory to disable swap, otherwise the limit is easy to bypass
+		resources.MemorySwap = resources.Memory
+	}
+	if limits.CPUs > 0 {
+		resources.NanoCPUs = int64(limits.CPUs * 1e9)
+	}
+	if limits.Pids > 0 {
+		pids := limits.Pids
+		resources.PidsLimit = &pids
+	}
+
+	return resources
+}
+
+func containerTmpfs(limits submission_tasks.ResourceLimits) map[string]string {
+	if limits.TmpfsMB <= 0 {
+		return nil
+	}
+
+	return map[string]string{containerTmpfsDir: fmt.Sprintf("rw,size=%dm", limits.TmpfsMB)}
+}
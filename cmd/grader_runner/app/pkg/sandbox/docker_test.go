@@ -0,0 +1,211 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+func TestDockerSandboxRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dockerCli := NewMockDockerClientInterface(ctrl)
+	backend := NewDockerSandbox(dockerCli, DefaultOutputLimit, "runner-1")
+	ctx := context.Background()
+	dockerErr := errors.New("docker err")
+	containerID := "container_id"
+
+	type testCase struct {
+		Title       string
+		DockerSetup func(chan container.WaitResponse, chan error)
+		Want        *RunResult
+		WantErr     error
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "success",
+			DockerSetup: func(statusCh chan container.WaitResponse, errCh chan error) {
+				statusCh <- container.WaitResponse{StatusCode: 0}
+			},
+			Want: &RunResult{ExitCode: 0},
+		},
+		{
+			Title: "failure",
+			DockerSetup: func(statusCh chan container.WaitResponse, errCh chan error) {
+				dockerCli.
+					EXPECT().
+					ContainerInspect(ctx, containerID).
+					Return(types.ContainerJSON{
+						ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{}},
+					}, nil)
+				statusCh <- container.WaitResponse{StatusCode: 1}
+			},
+			Want: &RunResult{ExitCode: 1},
+		},
+		{
+			Title: "out of memory",
+			DockerSetup: func(statusCh chan container.WaitResponse, errCh chan error) {
+				dockerCli.
+					EXPECT().
+					ContainerInspect(ctx, containerID).
+					Return(types.ContainerJSON{
+						ContainerJSONBase: &types.ContainerJSONBase{
+							State: &types.ContainerState{OOMKilled: true},
+						},
+					}, nil)
+				statusCh <- container.WaitResponse{StatusCode: 137}
+			},
+			Want: &RunResult{ExitCode: 137, Verdict: submission_tasks.VerdictOOM},
+		},
+		{
+			Title: "docker error",
+			DockerSetup: func(statusCh chan container.WaitResponse, errCh chan error) {
+				errCh <- dockerErr
+			},
+			WantErr: dockerErr,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			statusCh := make(chan container.WaitResponse, 1)
+			errCh := make(chan error, 1)
+			ws := &Workspace{
+				SrcDir:     t.TempDir(),
+				ReportsDir: t.TempDir(),
+				Task: &submission_tasks.SubmissionTask{
+					SubmissionID: 42,
+					Container:    "container_name",
+					PartID:       "part_id",
+					Limits:       submission_tasks.ResourceLimits{MemoryMB: 64, Pids: 16},
+				},
+			}
+
+			dockerCli.
+				EXPECT().
+				ContainerCreate(ctx, gomock.Any(), gomock.Any(), nil, nil, gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					config *container.Config,
+					hostConfig *container.HostConfig,
+					_, _ interface{},
+					_ string,
+				) (container.CreateResponse, error) {
+					if hostConfig.Memory != 64<<20 || *hostConfig.PidsLimit != 16 {
+						t.Errorf("expected to have resource limits, got %+v", hostConfig.Resources)
+					}
+					if config.Labels[managedLabel] != "true" || config.Labels[instanceLabel] != "runner-1" ||
+						config.Labels[submissionIDLabel] != "42" {
+						t.Errorf("expected to have labels, got %+v", config.Labels)
+					}
+
+					return container.CreateResponse{ID: containerID}, nil
+				})
+			dockerCli.
+				EXPECT().
+				ContainerStart(ctx, containerID, types.ContainerStartOptions{}).
+				Return(nil)
+			dockerCli.
+				EXPECT().
+				ContainerWait(ctx, containerID, container.WaitConditionNotRunning).
+				Return(statusCh, errCh)
+			testCase.DockerSetup(statusCh, errCh)
+
+			got, err := backend.Run(ctx, ws)
+			if err != testCase.WantErr {
+				t.Fatalf("expected to have %v, got %v", testCase.WantErr, err)
+			}
+			if testCase.Want != nil && (got.ExitCode != testCase.Want.ExitCode || got.Verdict != testCase.Want.Verdict) {
+				t.Errorf("expected %+v, got %+v", testCase.Want, got)
+			}
+
+			dockerCli.
+				EXPECT().
+				ContainerRemove(gomock.Any(), containerID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}).
+				Return(nil)
+			if err := backend.Cleanup(ws); err != nil {
+				t.Errorf("expected to not have errors, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDockerSandboxOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dockerCli := NewMockDockerClientInterface(ctrl)
+	backend := NewDockerSandbox(dockerCli, 4, "runner-1")
+	ctx := context.Background()
+	ws := &Workspace{containerID: "container_id"}
+
+	logs := &bytes.Buffer{}
+	if _, err := stdcopy.NewStdWriter(logs, stdcopy.Stdout).Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdcopy.NewStdWriter(logs, stdcopy.Stderr).Write([]byte("err")); err != nil {
+		t.Fatal(err)
+	}
+	dockerCli.
+		EXPECT().
+		ContainerLogs(ctx, ws.containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true}).
+		Return(io.NopCloser(logs), nil)
+
+	got, err := backend.Output(ctx, ws)
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	want := &Output{Stdout: "hell\n... [truncated, output exceeded 4 bytes]", Stderr: "err"}
+	if *got != *want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDockerSandboxPrune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Setenv("TMPDIR", t.TempDir())
+	dockerCli := NewMockDockerClientInterface(ctrl)
+	backend := NewDockerSandbox(dockerCli, DefaultOutputLimit, "runner-1")
+	ctx := context.Background()
+
+	ws, err := backend.Prepare(ctx, &submission_tasks.SubmissionTask{SubmissionID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dockerCli.
+		EXPECT().
+		ContainerList(ctx, types.ContainerListOptions{
+			All: true,
+			Filters: filters.NewArgs(
+				filters.Arg("label", managedLabel+"=true"),
+				filters.Arg("label", instanceLabel+"=runner-1"),
+			),
+		}).
+		Return([]types.Container{{ID: "orphan"}}, nil)
+	dockerCli.
+		EXPECT().
+		ContainerRemove(ctx, "orphan", types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}).
+		Return(nil)
+
+	if err := backend.Prune(ctx); err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if _, err := os.Stat(ws.Dir); !os.IsNotExist(err) {
+		t.Errorf("expected to remove orphaned workspace, got %v", err)
+	}
+}
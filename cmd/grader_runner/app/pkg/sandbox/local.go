@@ -0,0 +1,209 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+// LocalSandbox runs grading scripts as plain processes for development and
+// tests on machines without Docker. The contents of Dir are copied into a
+// separate working directory for every run the way an image WORKDIR would be.
+// Limits are applied with ulimit: the process limit counts all processes of
+// the user and doesn't apply to root, the file size limit applies to every
+// file instead of the whole directory. There is no network isolation, so it
+// must never be used for untrusted submissions.
+type LocalSandbox struct {
+	Dir         string
+	OutputLimit int
+	// InstanceID names workspaces of the runner, only they are pruned
+	InstanceID string
+	// nprocOption is the ulimit option of the process limit, -u in bash and -p in dash
+	nprocOption string
+}
+
+// NewLocalSandbox fails when the shell can't limit processes of the scripts
+func NewLocalSandbox(dir string, outputLimit int, instanceID string) (*LocalSandbox, error) {
+	nprocOption, err := shellNprocOption()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalSandbox{
+		Dir:         dir,
+		OutputLimit: outputLimit,
+		InstanceID:  instanceID,
+		nprocOption: nprocOption,
+	}, nil
+}
+
+func (s *LocalSandbox) Prepare(ctx context.Context, task *submission_tasks.SubmissionTask) (*Workspace, error) {
+	ws, err := newWorkspace(s.InstanceID, task)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.workDir = filepath.Join(ws.Dir, "work")
+	err = copyDir(s.Dir, ws.workDir)
+	if err != nil {
+		removeWorkspace(ws)
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func (s *LocalSandbox) Run(ctx context.Context, ws *Workspace) (*RunResult, error) {
+	script := fmt.Sprintf("%s.sh", ws.Task.PartID)
+	if _, err := os.Stat(filepath.Join(ws.workDir, script)); err != nil {
+		return nil, err
+	}
+
+	ws.stdout, ws.stderr = newLimitedBuffer(s.OutputLimit), newLimitedBuffer(s.OutputLimit)
+	cmd := exec.Command("sh", "-c", rlimitsScript(ws.Task.Limits, s.nprocOption)+"exec sh "+script)
+	cmd.Dir = ws.workDir
+	cmd.Stdout = ws.stdout
+	cmd.Stderr = ws.stderr
+	if ws.LogWriter != nil {
+		cmd.Stdout = io.MultiWriter(ws.stdout, ws.LogWriter)
+	}
+	cmd.Env = append(
+		os.Environ(),
+		fmt.Sprintf("%s=%s", SrcDirEnv, ws.SrcDir),
+		fmt.Sprintf("%s=%s", ReportsDirEnv, ws.ReportsDir),
+	)
+	// The whole process group is killed on timeout, not only the shell
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	startedAt := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(Timeout(ws.Task.Limits))
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		result := &RunResult{Duration: time.Since(startedAt)}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		} else if err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	case <-timer.C:
+		killProcessGroup(cmd, done)
+
+		return &RunResult{ExitCode: -1, Duration: time.Since(startedAt), Verdict: submission_tasks.VerdictTimeout}, nil
+	case <-ctx.Done():
+		killProcessGroup(cmd, done)
+
+		return nil, ctx.Err()
+	}
+}
+
+func (s *LocalSandbox) Output(ctx context.Context, ws *Workspace) (*Output, error) {
+	if ws.stdout == nil {
+		return &Output{}, nil
+	}
+
+	return &Output{Stdout: ws.stdout.String(), Stderr: ws.stderr.String()}, nil
+}
+
+func (s *LocalSandbox) Cleanup(ws *Workspace) error {
+	removeWorkspace(ws)
+
+	return nil
+}
+
+// Prune only removes workspaces, processes of the previous runner are not tracked
+func (s *LocalSandbox) Prune(ctx context.Context) error {
+	return pruneWorkspaces(s.InstanceID)
+}
+
+// rlimitsScript limits the script the way the container would be limited, the
+// CPU time can't exceed the timeout and files are written in 512-byte blocks
+func rlimitsScript(limits submission_tasks.ResourceLimits, nprocOption string) string {
+	commands := []string{
+		"ulimit -c 0",
+		fmt.Sprintf("ulimit -t %d", int64(Timeout(limits)/time.Second)),
+	}
+	if limits.MemoryMB > 0 {
+		commands = append(commands, fmt.Sprintf("ulimit -v %d", limits.MemoryMB<<10))
+	}
+	if limits.Pids > 0 {
+		commands = append(commands, fmt.Sprintf("ulimit %s %d", nprocOption, limits.Pids))
+	}
+	if limits.TmpfsMB > 0 {
+		commands = append(commands, fmt.Sprintf("ulimit -f %d", limits.TmpfsMB<<11))
+	}
+
+	return strings.Join(commands, " && ") + " && "
+}
+
+// shellNprocOption tries -u first since -p is the read-only pipe size in bash
+func shellNprocOption() (string, error) {
+	for _, option := range []string{"-u", "-p"} {
+		if err := exec.Command("sh", "-c", "ulimit "+option+" >/dev/null").Run(); err == nil {
+			return option, nil
+		}
+	}
+
+	return "", errors.New("sh can't limit the number of processes, the local sandbox can't be used")
+}
+
+func killProcessGroup(cmd *exec.Cmd, done chan error) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		_ = cmd.Process.Kill()
+	}
+	<-done
+}
+
+func copyDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+}
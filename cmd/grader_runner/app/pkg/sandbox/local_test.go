@@ -0,0 +1,174 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+func TestLocalSandbox(t *testing.T) {
+	ctx := context.Background()
+
+	type testCase struct {
+		Title   string
+		Script  string
+		Limits  submission_tasks.ResourceLimits
+		Want    *RunResult
+		Output  *Output
+		Logs    string
+		Reports []string
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "success",
+			Script: `cp "$GRADER_SRC_DIR/main.go" ./main.go
+cat ./main.go > "$GRADER_REPORTS_DIR/report.tap"`,
+			Want:    &RunResult{ExitCode: 0},
+			Reports: []string{"report.tap"},
+		},
+		{
+			Title:  "failure",
+			Script: "echo FAIL\necho oops >&2\nexit 3",
+			Want:   &RunResult{ExitCode: 3},
+			Output: &Output{Stdout: "FAIL\n", Stderr: "oops\n"},
+			Logs:   "FAIL\n",
+		},
+		{
+			Title:  "timeout",
+			Script: "sleep 10 & wait",
+			Limits: submission_tasks.ResourceLimits{TimeoutSeconds: 1},
+			Want:   &RunResult{ExitCode: -1, Verdict: submission_tasks.VerdictTimeout},
+		},
+		{
+			Title:  "file size limit",
+			Script: "head -c 2097152 /dev/zero > ./big || exit 4",
+			Limits: submission_tasks.ResourceLimits{TmpfsMB: 1},
+			Want:   &RunResult{ExitCode: 4},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "part_id.sh"), []byte(testCase.Script), 0644); err != nil {
+				t.Fatal(err)
+			}
+			backend, err := NewLocalSandbox(dir, DefaultOutputLimit, "runner-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			task := &submission_tasks.SubmissionTask{
+				SubmissionID: 1,
+				PartID:       "part_id",
+				Limits:       testCase.Limits,
+			}
+
+			ws, err := backend.Prepare(ctx, task)
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			logs := &bytes.Buffer{}
+			ws.LogWriter = logs
+			defer func() {
+				if err := backend.Cleanup(ws); err != nil {
+					t.Errorf("expected to not have errors, got %v", err)
+				}
+			}()
+			if err := os.WriteFile(filepath.Join(ws.SrcDir, "main.go"), []byte("ok 1 - hello\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := backend.Run(ctx, ws)
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if got.ExitCode != testCase.Want.ExitCode || got.Verdict != testCase.Want.Verdict {
+				t.Errorf("expected %+v, got %+v", testCase.Want, got)
+			}
+
+			output, err := backend.Output(ctx, ws)
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if testCase.Output != nil && *output != *testCase.Output {
+				t.Errorf("expected output %+v, got %+v", testCase.Output, output)
+			}
+			if len(testCase.Logs) > 0 && logs.String() != testCase.Logs {
+				t.Errorf("expected logs %q, got %q", testCase.Logs, logs.String())
+			}
+			for _, report := range testCase.Reports {
+				if _, err := os.Stat(filepath.Join(ws.ReportsDir, report)); err != nil {
+					t.Errorf("expected to have report %q, got %v", report, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalSandboxWorkspaces(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	backend, err := NewLocalSandbox(t.TempDir(), DefaultOutputLimit, "runner-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewLocalSandbox(t.TempDir(), DefaultOutputLimit, "runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	task := &submission_tasks.SubmissionTask{SubmissionID: 1}
+
+	first, err := backend.Prepare(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := backend.Prepare(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Dir == second.Dir {
+		t.Errorf("expected to have unique workspaces, got %q twice", first.Dir)
+	}
+	running, err := other.Prepare(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Prune(ctx); err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	for _, ws := range []*Workspace{first, second} {
+		if _, err := os.Stat(ws.Dir); !os.IsNotExist(err) {
+			t.Errorf("expected to remove workspace %q, got %v", ws.Dir, err)
+		}
+	}
+	if _, err := os.Stat(running.Dir); err != nil {
+		t.Errorf("expected to keep the workspace of another instance, got %v", err)
+	}
+}
+
+func TestValidateInstanceID(t *testing.T) {
+	if err := ValidateInstanceID("runner-1.example.com"); err != nil {
+		t.Errorf("expected the host name to be valid, got %v", err)
+	}
+	for _, instanceID := range []string{"", "runner_1", "../runner"} {
+		if err := ValidateInstanceID(instanceID); err == nil {
+			t.Errorf("expected %q to be invalid", instanceID)
+		}
+	}
+}
+
+func TestRlimitsScript(t *testing.T) {
+	limits := submission_tasks.ResourceLimits{MemoryMB: 256, Pids: 64, TmpfsMB: 16, TimeoutSeconds: 30}
+
+	got := rlimitsScript(limits, "-p")
+	want := "ulimit -c 0 && ulimit -t 30 && ulimit -v 262144 && ulimit -p 64 && ulimit -f 32768 && "
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const DefaultOutputLimit = 64 * 1024
+
+type Output struct {
+	Stdout string
+	Stderr string
+}
+
+// limitedBuffer keeps the first limit bytes written into it and silently
+// drops the rest, so a noisy submission can't exhaust the runner memory
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func newLimitedBuffer(limit int) *limitedBuffer {
+	return &limitedBuffer{limit: limit}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+
+	available := b.limit - b.buf.Len()
+	if len(p) > available {
+		b.truncated = true
+		b.buf.Write(p[:available])
+	} else {
+		b.buf.Write(p)
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	if !b.truncated {
+		return b.buf.String()
+	}
+
+	// The limit could have split a multibyte character
+	return strings.ToValidUTF8(b.buf.String(), "") + fmt.Sprintf("\n... [truncated, output exceeded %d bytes]", b.limit)
+}
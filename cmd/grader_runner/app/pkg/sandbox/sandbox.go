@@ -0,0 +1,146 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+const (
+	DefaultTimeout = 5 * time.Minute
+	// Grading scripts can rely on these variables instead of hardcoded paths
+	// to work with any backend
+	SrcDirEnv     = "GRADER_SRC_DIR"
+	ReportsDirEnv = "GRADER_REPORTS_DIR"
+	// Every run gets its own workspace in the temp dir named with this prefix
+	// followed by the instance ID of the runner
+	workspacePrefix = "grader_submission_"
+)
+
+// instanceIDPattern excludes underscores which separate the instance ID in
+// workspace names, so prefixes of different instances never match each other
+var instanceIDPattern = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+// Sandbox runs grading scripts of submission tasks in isolation
+type Sandbox interface {
+	// Prepare creates a workspace, submission files should be saved into its SrcDir
+	Prepare(context.Context, *submission_tasks.SubmissionTask) (*Workspace, error)
+	Run(context.Context, *Workspace) (*RunResult, error)
+	// Output returns stdout and stderr of the run capped at the configured limit
+	Output(context.Context, *Workspace) (*Output, error)
+	// Cleanup releases all the resources of the workspace, it has to be called
+	// even if the context of the run was cancelled
+	Cleanup(*Workspace) error
+	// Prune releases resources left after crashed runs of the same runner
+	// instance, it has to be called before any runs are started
+	Prune(context.Context) error
+}
+
+type Workspace struct {
+	Dir        string
+	SrcDir     string
+	ReportsDir string
+	Task       *submission_tasks.SubmissionTask
+	// LogWriter receives stdout while the run is in progress, stderr isn't
+	// streamed since it can be hidden from students
+	LogWriter   io.Writer
+	containerID string
+	workDir     string
+	stdout      *limitedBuffer
+	stderr      *limitedBuffer
+}
+
+type RunResult struct {
+	ExitCode int
+	Duration time.Duration
+	// Verdict is set when the run was stopped by one of its limits
+	Verdict string
+}
+
+func Timeout(limits submission_tasks.ResourceLimits) time.Duration {
+	if limits.TimeoutSeconds <= 0 {
+		return DefaultTimeout
+	}
+
+	return time.Duration(limits.TimeoutSeconds) * time.Second
+}
+
+// ValidateInstanceID checks that the ID can be used in workspace names and container labels
+func ValidateInstanceID(instanceID string) error {
+	if !instanceIDPattern.MatchString(instanceID) {
+		return fmt.Errorf("runner instance id %q should contain only letters, digits, dots and dashes", instanceID)
+	}
+
+	return nil
+}
+
+// DefaultInstanceID is the host name, so runners sharing a Docker host or a
+// temp dir don't remove resources of each other
+func DefaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || ValidateInstanceID(hostname) != nil {
+		return "runner"
+	}
+
+	return hostname
+}
+
+// newWorkspace creates a unique directory for the run, so redelivered tasks
+// of the same submission never collide with each other
+func newWorkspace(instanceID string, task *submission_tasks.SubmissionTask) (*Workspace, error) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("%s%s_%d_", workspacePrefix, instanceID, task.SubmissionID))
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{
+		Dir:        dir,
+		SrcDir:     filepath.Join(dir, "src"),
+		ReportsDir: filepath.Join(dir, "reports"),
+		Task:       task,
+	}
+
+	err = os.Mkdir(ws.SrcDir, 0755)
+	if err == nil {
+		// Grading processes run as an unprivileged user and umask may strip write permissions
+		err = os.Mkdir(ws.ReportsDir, 0777)
+	}
+	if err == nil {
+		err = os.Chmod(ws.ReportsDir, 0777)
+	}
+	if err != nil {
+		removeWorkspace(ws)
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func removeWorkspace(ws *Workspace) {
+	if err := os.RemoveAll(ws.Dir); err != nil {
+		log.Printf("Error while removing dir: %v", err)
+	}
+}
+
+// pruneWorkspaces removes workspaces of the previous runs of the instance
+func pruneWorkspaces(instanceID string) error {
+	dirs, err := filepath.Glob(filepath.Join(os.TempDir(), workspacePrefix+instanceID+"_*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		log.Printf("Removing orphaned workspace %s", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
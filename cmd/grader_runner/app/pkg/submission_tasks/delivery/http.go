@@ -1,20 +1,20 @@
 package delivery
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 
+	jobsDelivery "github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/delivery"
+	jobsServices "github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/services"
 	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
-	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/services"
 	"github.com/maxshend/grader/pkg/utils"
 )
 
 type SubmissionTasksHandler struct {
-	Service services.SubmissionTaskServiceInterface
+	Service jobsServices.JobsServiceInterface
 }
 
-func NewSubmissionTasksHandler(service services.SubmissionTaskServiceInterface) *SubmissionTasksHandler {
+func NewSubmissionTasksHandler(service jobsServices.JobsServiceInterface) *SubmissionTasksHandler {
 	return &SubmissionTasksHandler{
 		Service: service,
 	}
@@ -28,11 +28,16 @@ func (h *SubmissionTasksHandler) Grade(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.Service.RunSubmission(context.Background(), task)
+	job, err := h.Service.Enqueue(task)
 	if err != nil {
-		utils.RenderInternalError(w, r, err)
+		if err == jobsServices.ErrQueueFull {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		} else {
+			utils.RenderInternalError(w, r, err)
+		}
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	jobsDelivery.RenderJob(w, r, http.StatusAccepted, job)
 }
@@ -9,15 +9,16 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/jobs/services"
 	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
-	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks/services"
 )
 
 func TestGrade(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	service := services.NewMockSubmissionTaskServiceInterface(ctrl)
+	service := services.NewMockJobsServiceInterface(ctrl)
 	handler := NewSubmissionTasksHandler(service)
 
 	type testCase struct {
@@ -30,12 +31,22 @@ func TestGrade(t *testing.T) {
 	testCases := []*testCase{
 		{
 			Title:              "success",
-			ExpectedStatusCode: http.StatusOK,
+			ExpectedStatusCode: http.StatusAccepted,
 			SubmissionTask:     &submission_tasks.SubmissionTask{},
 			SetupService: func(t *testing.T, task *submission_tasks.SubmissionTask) {
 				t.Helper()
 
-				service.EXPECT().RunSubmission(gomock.Any(), task).Return(nil)
+				service.EXPECT().Enqueue(task).Return(&jobs.Job{ID: "1", Status: jobs.Queued}, nil)
+			},
+		},
+		{
+			Title:              "queue is full",
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			SubmissionTask:     &submission_tasks.SubmissionTask{},
+			SetupService: func(t *testing.T, task *submission_tasks.SubmissionTask) {
+				t.Helper()
+
+				service.EXPECT().Enqueue(task).Return(nil, services.ErrQueueFull)
 			},
 		},
 		{
@@ -45,7 +56,7 @@ func TestGrade(t *testing.T) {
 			SetupService: func(t *testing.T, task *submission_tasks.SubmissionTask) {
 				t.Helper()
 
-				service.EXPECT().RunSubmission(gomock.Any(), task).Return(fmt.Errorf("error"))
+				service.EXPECT().Enqueue(task).Return(nil, fmt.Errorf("error"))
 			},
 		},
 	}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logsFlushInterval = time.Second
+	logsBatchSize     = 100
+)
+
+type logsRequest struct {
+	Lines []string `json:"lines"`
+}
+
+// logStreamer splits the output of a running submission into lines and sends
+// them to grader_web in batches. Streaming is best effort: the first failed
+// request disables it and the full output is still reported with the result.
+type logStreamer struct {
+	url      string
+	token    string
+	limit    int
+	client   *http.Client
+	mx       sync.Mutex
+	partial  []byte
+	pending  []string
+	written  int
+	disabled bool
+	flushCh  chan struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func newLogStreamer(url string, token string, limit int) *logStreamer {
+	s := &logStreamer{
+		url:     url,
+		token:   token,
+		limit:   limit,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		flushCh: make(chan struct{}, 1),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go s.loop()
+
+	return s
+}
+
+func (s *logStreamer) Write(p []byte) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.disabled {
+		return len(p), nil
+	}
+	if s.limit > 0 && s.written+len(p) > s.limit {
+		s.disabled = true
+		if len(s.partial) > 0 {
+			s.pending = append(s.pending, string(s.partial))
+			s.partial = nil
+		}
+		s.pending = append(s.pending, fmt.Sprintf("... [truncated, output exceeded %d bytes]", s.limit))
+		s.signal()
+
+		return len(p), nil
+	}
+	s.written += len(p)
+
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		s.pending = append(s.pending, strings.TrimRight(string(s.partial[:i]), "\r"))
+		s.partial = s.partial[i+1:]
+	}
+	if len(s.pending) >= logsBatchSize {
+		s.signal()
+	}
+
+	return len(p), nil
+}
+
+// Close sends the rest of the lines and stops streaming
+func (s *logStreamer) Close() {
+	close(s.stopCh)
+	<-s.doneCh
+}
+
+// signal should be called with the lock held
+func (s *logStreamer) signal() {
+	select {
+	case s.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *logStreamer) loop() {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(logsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(false)
+		case <-s.flushCh:
+			s.flush(false)
+		case <-s.stopCh:
+			s.flush(true)
+			return
+		}
+	}
+}
+
+func (s *logStreamer) flush(final bool) {
+	s.mx.Lock()
+	lines := s.pending
+	if final && len(s.partial) > 0 {
+		lines = append(lines, string(s.partial))
+		s.partial = nil
+	}
+	s.pending = nil
+	s.mx.Unlock()
+
+	if len(lines) == 0 {
+		return
+	}
+	if err := s.send(lines); err != nil {
+		log.Printf("Can't stream logs to %q, streaming is disabled: %v", s.url, err)
+
+		s.mx.Lock()
+		s.disabled = true
+		s.pending = nil
+		s.mx.Unlock()
+	}
+}
+
+func (s *logStreamer) send(lines []string) error {
+	body, err := json.Marshal(&logsRequest{Lines: lines})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", s.url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Authorization", s.token)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLogStreamer(t *testing.T) {
+	type testCase struct {
+		Title  string
+		Limit  int
+		Writes []string
+		Want   []string
+	}
+
+	testCases := []*testCase{
+		{
+			Title:  "lines",
+			Writes: []string{"hello\nwor", "ld\r\n", "bye"},
+			Want:   []string{"hello", "world", "bye"},
+		},
+		{
+			Title:  "limit",
+			Limit:  8,
+			Writes: []string{"hello\n", "world\n", "bye\n"},
+			Want:   []string{"hello", "... [truncated, output exceeded 8 bytes]"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			var mx sync.Mutex
+			got := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "token" {
+					t.Errorf("expected to have the access token")
+				}
+
+				request := &logsRequest{}
+				if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+					t.Errorf("can't decode logs: %v", err)
+				}
+				mx.Lock()
+				got = append(got, request.Lines...)
+				mx.Unlock()
+			}))
+			defer server.Close()
+
+			streamer := newLogStreamer(server.URL, "token", testCase.Limit)
+			for _, chunk := range testCase.Writes {
+				if _, err := streamer.Write([]byte(chunk)); err != nil {
+					t.Fatalf("expected to not have errors, got %v", err)
+				}
+			}
+			streamer.Close()
+
+			mx.Lock()
+			defer mx.Unlock()
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Errorf("expected %q, got %q", testCase.Want, got)
+			}
+		})
+	}
+}
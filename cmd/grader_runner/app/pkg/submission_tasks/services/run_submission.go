@@ -7,108 +7,145 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+	"github.com/maxshend/grader/pkg/archives"
 	"golang.org/x/sync/errgroup"
 )
 
 type ContainerResponse struct {
-	Pass bool   `json:"pass"`
-	Text string `json:"text"`
+	Pass     bool                           `json:"pass"`
+	Text     string                         `json:"text"`
+	Verdict  string                         `json:"verdict,omitempty"`
+	Score    *float64                       `json:"score,omitempty"`
+	Tests    []*submission_tasks.TestResult `json:"tests"`
+	Stdout   string                         `json:"stdout"`
+	Stderr   string                         `json:"stderr"`
+	ExitCode *int                           `json:"exit_code,omitempty"`
+	Duration float64                        `json:"duration"`
 }
 
 const (
-	successMsg         = "Поздравляем! Вы успешно сделали задание"
-	submissionFilesDir = "/app/src"
-	MaxWaitMinutes     = 5
-	TimeoutMsg         = "Timeout"
+	successMsg = "Поздравляем! Вы успешно сделали задание"
+	TimeoutMsg = "Timeout"
+	OOMMsg     = "Memory limit exceeded"
+	failMsg    = "Exited with code %d"
 )
 
+// maxArchiveSize limits downloaded archives, the web app accepts smaller uploads
+const maxArchiveSize = 20 * 1024 * 1024
+
 var (
 	ErrSubmissionFileDonwload = errors.New("can't download submission file")
 	ErrSendResults            = errors.New("can't send submission results")
+	ErrArchiveTooLarge        = errors.New("submission archive is too large")
 )
 
 func (s *SubmissionTaskService) RunSubmission(ctx context.Context, task *submission_tasks.SubmissionTask) error {
-	dir, rmDir, err := tmpSaveAttachments(ctx, task)
+	containerResponse, err := s.grade(ctx, task, func(dir string) error {
+		return saveAttachments(ctx, task, dir)
+	})
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := rmDir(); err != nil {
-			log.Printf("Error while removing dir: %q\n", err)
-		}
-	}()
 
-	resp, err := s.createContainer(ctx, task, dir)
+	httpResponse, err := sendResults(task.WebhookURL, task.AccessToken, containerResponse)
 	if err != nil {
 		return err
 	}
-
-	if err := s.DockerClient.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
 		return err
 	}
+	log.Printf("Webhook %q Response %d\n%s\n", task.WebhookURL, httpResponse.StatusCode, responseBody)
+
+	return nil
+}
+
+// DryRun grades files of the local directory the same way as RunSubmission
+// but returns the results instead of sending them to the webhook
+func (s *SubmissionTaskService) DryRun(ctx context.Context, task *submission_tasks.SubmissionTask, dir string) (*ContainerResponse, error) {
+	return s.grade(ctx, task, func(srcDir string) error {
+		return copyDir(dir, srcDir)
+	})
+}
+
+// grade runs the task in the sandbox, saveFiles puts the submission files into its source dir
+func (s *SubmissionTaskService) grade(
+	ctx context.Context,
+	task *submission_tasks.SubmissionTask,
+	saveFiles func(string) error,
+) (*ContainerResponse, error) {
+	ws, err := s.Sandbox.Prepare(ctx, task)
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
-		noWaitTimeout := 0 // to not wait for the container to exit gracefully
-		if err := s.DockerClient.ContainerStop(ctx, resp.ID, container.StopOptions{Timeout: &noWaitTimeout}); err != nil {
-			log.Printf("Can't stop docker container: %v", err)
+		if err := s.Sandbox.Cleanup(ws); err != nil {
+			log.Printf("Can't clean up sandbox: %v", err)
 		}
 	}()
 
-	containerResponse := &ContainerResponse{}
-	statusCh, errCh := s.DockerClient.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
-	containerTimer := time.NewTimer(MaxWaitMinutes * time.Minute)
-	defer containerTimer.Stop()
+	if err := saveFiles(ws.SrcDir); err != nil {
+		return nil, err
+	}
+
+	var streamer *logStreamer
+	if len(task.LogsURL) != 0 {
+		streamer = newLogStreamer(task.LogsURL, task.AccessToken, s.LogsLimit)
+		ws.LogWriter = streamer
+	}
+	result, err := s.Sandbox.Run(ctx, ws)
+	if streamer != nil {
+		// The rest of the lines have to be delivered before the result
+		streamer.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	select {
-	case err := <-errCh:
-		return err
-	case status := <-statusCh:
-		if status.StatusCode == 0 {
-			containerResponse.Pass = true
-			containerResponse.Text = successMsg
-		} else {
-			containerOut, err := s.DockerClient.ContainerLogs(
-				ctx,
-				resp.ID,
-				types.ContainerLogsOptions{ShowStdout: true},
-			)
-			if err != nil {
-				return err
-			}
-
-			out, err := io.ReadAll(containerOut)
-			if err != nil {
-				return err
-			}
-
-			containerResponse.Pass = false
-			containerResponse.Text = string(out)
-		}
-	case <-containerTimer.C:
-		containerResponse.Pass = false
+	output, err := s.Sandbox.Output(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	containerResponse := &ContainerResponse{
+		Verdict:  result.Verdict,
+		Stdout:   output.Stdout,
+		Stderr:   output.Stderr,
+		Duration: result.Duration.Seconds(),
+	}
+	if result.Verdict != submission_tasks.VerdictTimeout {
+		containerResponse.ExitCode = &result.ExitCode
+	}
+	switch {
+	case result.Verdict == submission_tasks.VerdictTimeout:
 		containerResponse.Text = TimeoutMsg
+	case result.Verdict == submission_tasks.VerdictOOM:
+		containerResponse.Text = OOMMsg
+	case result.ExitCode == 0:
+		containerResponse.Pass = true
+		containerResponse.Text = successMsg
+	default:
+		containerResponse.Text = fmt.Sprintf(failMsg, result.ExitCode)
 	}
 
-	httpResponse, err := sendResults(task.WebhookURL, task.AccessToken, containerResponse)
+	containerResponse.Tests, err = collectTestResults(ws.ReportsDir)
 	if err != nil {
-		return err
+		log.Printf("Can't parse test report of submission #%d: %v", task.SubmissionID, err)
 	}
-	responseBody, err := io.ReadAll(httpResponse.Body)
+	containerResponse.Score, err = collectScore(ws.ReportsDir, task.MaxScore, containerResponse.Tests)
 	if err != nil {
-		return err
+		log.Printf("Can't parse score of submission #%d: %v", task.SubmissionID, err)
 	}
-	log.Printf("Webhook %q Response %d\n%s\n", task.WebhookURL, httpResponse.StatusCode, responseBody)
 
-	return nil
+	return containerResponse, nil
 }
 
 func sendResults(graderURL string, authorization string, containerResponse *ContainerResponse) (*http.Response, error) {
@@ -137,61 +174,7 @@ func sendResults(graderURL string, authorization string, containerResponse *Cont
 	return response, nil
 }
 
-func (s *SubmissionTaskService) createContainer(
-	ctx context.Context,
-	task *submission_tasks.SubmissionTask,
-	mountDir string,
-) (resp container.CreateResponse, err error) {
-	containerName := fmt.Sprintf("run_submission_%d", task.SubmissionID)
-	resp, err = s.DockerClient.ContainerCreate(
-		ctx,
-		&container.Config{
-			User:            "1000",
-			NetworkDisabled: true,
-			Image:           task.Container,
-			Cmd:             []string{"sh", fmt.Sprintf("%s.sh", task.PartID)},
-		},
-		&container.HostConfig{
-			Mounts: []mount.Mount{
-				{
-					Type:   mount.TypeBind,
-					Source: mountDir,
-					Target: submissionFilesDir,
-				},
-			},
-		},
-		nil,
-		nil,
-		containerName,
-	)
-
-	return
-}
-
-func tmpSaveAttachments(ctx context.Context, task *submission_tasks.SubmissionTask) (dir string, rmDir func() error, err error) {
-	dir = fmt.Sprintf("/tmp/submission_%d", task.SubmissionID)
-	err = os.Mkdir(dir, 0755)
-	if err != nil {
-		return
-	}
-	rmDir = func() error {
-		return os.RemoveAll(dir)
-	}
-	defer func() {
-		p := recover()
-
-		if p != nil || err != nil {
-			rmErr := rmDir()
-			if rmErr != nil {
-				log.Printf("Error while removing dir: %v", rmErr)
-			}
-		}
-
-		if p != nil {
-			panic(p)
-		}
-	}()
-
+func saveAttachments(ctx context.Context, task *submission_tasks.SubmissionTask, dir string) error {
 	errs, ctx := errgroup.WithContext(ctx)
 
 	for _, file := range task.Files {
@@ -201,25 +184,25 @@ func tmpSaveAttachments(ctx context.Context, task *submission_tasks.SubmissionTa
 				case <-ctx.Done():
 					return ctx.Err()
 				default:
-					var newFile *os.File
-					fullpath := filepath.Join(dir, file.Name)
-
-					newFile, err := os.Create(fullpath)
+					resp, err := http.Get(file.URL)
 					if err != nil {
 						return err
 					}
-					defer newFile.Close()
+					defer resp.Body.Close()
+					if resp.StatusCode != http.StatusOK {
+						log.Printf("Cannot get submission file: %q (%d)", file.Name, resp.StatusCode)
+						return ErrSubmissionFileDonwload
+					}
 
-					var resp *http.Response
+					if file.Archive {
+						return extractArchive(file.Name, resp.Body, dir)
+					}
 
-					resp, err = http.Get(file.URL)
+					newFile, err := os.Create(filepath.Join(dir, file.Name))
 					if err != nil {
 						return err
 					}
-					if resp.StatusCode != http.StatusOK {
-						log.Printf("Cannot get submission file: %q (%d)", file.URL, resp.StatusCode)
-						return ErrSubmissionFileDonwload
-					}
+					defer newFile.Close()
 
 					_, err = io.Copy(newFile, resp.Body)
 					if err != nil {
@@ -232,7 +215,55 @@ func tmpSaveAttachments(ctx context.Context, task *submission_tasks.SubmissionTa
 		}(file)
 	}
 
-	err = errs.Wait()
+	return errs.Wait()
+}
+
+// extractArchive writes files of the archive into dir, the archive was
+// checked by the web app but links and paths out of dir are rejected again
+func extractArchive(name string, content io.Reader, dir string) error {
+	data, err := io.ReadAll(io.LimitReader(content, maxArchiveSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxArchiveSize {
+		return ErrArchiveTooLarge
+	}
+	_, err = archives.Extract(name, data, dir, archives.DefaultLimits)
+
+	return err
+}
+
+// copyDir copies regular files of src into dst keeping the directory structure
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !entry.Type().IsRegular() {
+			log.Printf("Skipping %s, only regular files are copied", path)
+			return nil
+		}
 
-	return
+		source, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		file, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(file, source)
+
+		return err
+	})
 }
@@ -1,15 +1,19 @@
 package services
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	http "net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
-	types "github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
 	gomock "github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/sandbox"
 	submission_tasks "github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
 )
 
@@ -17,17 +21,20 @@ func TestRunSubmission(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	dockerCli := NewMockDockerClientInterface(ctrl)
-	service := NewSubmissionTaskService(dockerCli)
+	backend := sandbox.NewMockSandbox(ctrl)
+	service := NewSubmissionTaskService(backend, sandbox.DefaultOutputLimit)
 	ctx := context.Background()
-	dockerErr := errors.New("docker err")
+	sandboxErr := errors.New("sandbox err")
 
 	type testCase struct {
 		Title            string
 		Success          bool
 		FileServerStatus int
 		WebServerStatus  int
-		DockerSetup      func(*testing.T)
+		Verdict          string
+		Text             string
+		Stdout           string
+		SandboxSetup     func(*sandbox.Workspace)
 		Check            func(*testing.T, error)
 	}
 
@@ -37,29 +44,52 @@ func TestRunSubmission(t *testing.T) {
 			Success:          true,
 			FileServerStatus: http.StatusOK,
 			WebServerStatus:  http.StatusOK,
-			DockerSetup: func(*testing.T) {
-				t.Helper()
-
-				statusCh, _ := setupDockerExpectations(ctx, dockerCli)
-				statusCh <- container.WaitResponse{StatusCode: 0}
+			Text:             successMsg,
+			SandboxSetup: func(ws *sandbox.Workspace) {
+				backend.EXPECT().Run(ctx, ws).Return(&sandbox.RunResult{ExitCode: 0}, nil)
+				backend.EXPECT().Output(ctx, ws).Return(&sandbox.Output{}, nil)
+			},
+		},
+		{
+			Title:            "failure",
+			Success:          true,
+			FileServerStatus: http.StatusOK,
+			WebServerStatus:  http.StatusOK,
+			Text:             "Exited with code 1",
+			Stdout:           "FAIL",
+			SandboxSetup: func(ws *sandbox.Workspace) {
+				backend.EXPECT().Run(ctx, ws).Return(&sandbox.RunResult{ExitCode: 1}, nil)
+				backend.EXPECT().Output(ctx, ws).Return(&sandbox.Output{Stdout: "FAIL"}, nil)
 			},
 		},
 		{
-			Title:            "docker error",
+			Title:            "out of memory",
+			Success:          true,
+			FileServerStatus: http.StatusOK,
+			WebServerStatus:  http.StatusOK,
+			Verdict:          submission_tasks.VerdictOOM,
+			Text:             OOMMsg,
+			SandboxSetup: func(ws *sandbox.Workspace) {
+				backend.EXPECT().Run(ctx, ws).Return(
+					&sandbox.RunResult{ExitCode: 137, Verdict: submission_tasks.VerdictOOM},
+					nil,
+				)
+				backend.EXPECT().Output(ctx, ws).Return(&sandbox.Output{}, nil)
+			},
+		},
+		{
+			Title:            "sandbox error",
 			Success:          false,
 			FileServerStatus: http.StatusOK,
 			WebServerStatus:  http.StatusOK,
-			DockerSetup: func(*testing.T) {
-				t.Helper()
-
-				_, errCh := setupDockerExpectations(ctx, dockerCli)
-				errCh <- dockerErr
+			SandboxSetup: func(ws *sandbox.Workspace) {
+				backend.EXPECT().Run(ctx, ws).Return(nil, sandboxErr)
 			},
 			Check: func(t *testing.T, err error) {
 				t.Helper()
 
-				if err != dockerErr {
-					t.Errorf("expected to have %v, got %v", dockerErr, err)
+				if err != sandboxErr {
+					t.Errorf("expected to have %v, got %v", sandboxErr, err)
 				}
 			},
 		},
@@ -74,18 +104,23 @@ func TestRunSubmission(t *testing.T) {
 			Success:          false,
 			FileServerStatus: http.StatusOK,
 			WebServerStatus:  http.StatusInternalServerError,
-			DockerSetup: func(*testing.T) {
-				t.Helper()
-
-				statusCh, _ := setupDockerExpectations(ctx, dockerCli)
-				statusCh <- container.WaitResponse{StatusCode: 0}
+			SandboxSetup: func(ws *sandbox.Workspace) {
+				backend.EXPECT().Run(ctx, ws).Return(&sandbox.RunResult{ExitCode: 0}, nil)
+				backend.EXPECT().Output(ctx, ws).Return(&sandbox.Output{}, nil)
 			},
 		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.Title, func(t *testing.T) {
-			webServer := createTestServer(testCase.WebServerStatus)
+			var got *ContainerResponse
+			webServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = &ContainerResponse{}
+				if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+					t.Errorf("can't decode submission results: %v", err)
+				}
+				w.WriteHeader(testCase.WebServerStatus)
+			}))
 			defer webServer.Close()
 
 			fileServer := createTestServer(testCase.FileServerStatus)
@@ -102,8 +137,11 @@ func TestRunSubmission(t *testing.T) {
 				AccessToken: "foobar123",
 			}
 
-			if testCase.DockerSetup != nil {
-				testCase.DockerSetup(t)
+			ws := &sandbox.Workspace{SrcDir: t.TempDir(), ReportsDir: t.TempDir(), Task: task}
+			backend.EXPECT().Prepare(ctx, task).Return(ws, nil)
+			backend.EXPECT().Cleanup(ws).Return(nil)
+			if testCase.SandboxSetup != nil {
+				testCase.SandboxSetup(ws)
 			}
 
 			err := service.RunSubmission(ctx, task)
@@ -115,41 +153,100 @@ func TestRunSubmission(t *testing.T) {
 			if testCase.Check != nil {
 				testCase.Check(t, err)
 			}
+			if got != nil && got.Verdict != testCase.Verdict {
+				t.Errorf("expected to have verdict %q, got %q", testCase.Verdict, got.Verdict)
+			}
+			if got != nil && testCase.Success && got.Text != testCase.Text {
+				t.Errorf("expected to have text %q, got %q", testCase.Text, got.Text)
+			}
+			if got != nil && got.Stdout != testCase.Stdout {
+				t.Errorf("expected to have stdout %q, got %q", testCase.Stdout, got.Stdout)
+			}
 		})
 	}
 }
 
-func setupDockerExpectations(ctx context.Context, dockerCli *MockDockerClientInterface) (chan container.WaitResponse, chan error) {
-	statusCh := make(chan container.WaitResponse, 1)
-	errCh := make(chan error, 1)
-
-	createResponse := container.CreateResponse{}
-	dockerCli.
-		EXPECT().
-		ContainerCreate(ctx, gomock.Any(), gomock.Any(), nil, nil, gomock.Any()).
-		Return(createResponse, nil)
-
-	dockerCli.
-		EXPECT().
-		ContainerStart(ctx, createResponse.ID, types.ContainerStartOptions{}).
-		Return(nil)
-
-	dockerCli.
-		EXPECT().
-		ContainerWait(ctx, createResponse.ID, container.WaitConditionNotRunning).
-		Return(statusCh, errCh)
-
-	noWaitTimeout := 0
-	dockerCli.
-		EXPECT().
-		ContainerStop(ctx, createResponse.ID, container.StopOptions{Timeout: &noWaitTimeout}).
-		Return(nil)
-
-	return statusCh, errCh
-}
-
 func createTestServer(statusCode int) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(statusCode)
 	}))
 }
+
+func TestDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backend := sandbox.NewMockSandbox(ctrl)
+	service := NewSubmissionTaskService(backend, sandbox.DefaultOutputLimit)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"main.go", filepath.Join("pkg", "util.go")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task := &submission_tasks.SubmissionTask{Container: "container_name", PartID: "part_id"}
+	ws := &sandbox.Workspace{SrcDir: t.TempDir(), ReportsDir: t.TempDir(), Task: task}
+	backend.EXPECT().Prepare(ctx, task).Return(ws, nil)
+	backend.EXPECT().Run(ctx, ws).Return(&sandbox.RunResult{ExitCode: 1}, nil)
+	backend.EXPECT().Output(ctx, ws).Return(&sandbox.Output{Stdout: "FAIL"}, nil)
+	backend.EXPECT().Cleanup(ws).Return(nil)
+
+	result, err := service.DryRun(ctx, task, dir)
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if result.Pass || result.Text != "Exited with code 1" || result.Stdout != "FAIL" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	content, err := os.ReadFile(filepath.Join(ws.SrcDir, "pkg", "util.go"))
+	if err != nil || string(content) != filepath.Join("pkg", "util.go") {
+		t.Errorf("expected to have nested files copied, got %q (%v)", content, err)
+	}
+}
+
+func TestSaveAttachments(t *testing.T) {
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	for _, name := range []string{"main.go", "pkg/util.go"} {
+		part, _ := archive.Create(name)
+		part.Write([]byte(name))
+	}
+	archive.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/project.zip":
+			w.Write(buf.Bytes())
+		case "/README":
+			w.Write([]byte("README"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	task := &submission_tasks.SubmissionTask{Files: []*submission_tasks.SubmissionFile{
+		{URL: server.URL + "/project.zip", Name: "project.zip", Archive: true},
+		{URL: server.URL + "/README", Name: "README"},
+	}}
+	if err := saveAttachments(context.Background(), task, dir); err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	for _, name := range []string{"main.go", "pkg/util.go", "README"} {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil || string(content) != name {
+			t.Errorf("expected to have %s saved, got %q (%v)", name, content, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "project.zip")); !os.IsNotExist(err) {
+		t.Errorf("expected the archive itself not to be saved")
+	}
+}
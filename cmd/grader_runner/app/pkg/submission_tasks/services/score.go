@@ -0,0 +1,73 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+// scoreReport is written by grading containers into reportsDir. Score is
+// reported as is, Weights are applied to the parsed test results instead.
+const scoreReport = "score.json"
+
+type scoreData struct {
+	Score   *float64           `json:"score"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+func collectScore(dir string, maxScore float64, tests []*submission_tasks.TestResult) (*float64, error) {
+	if maxScore <= 0 {
+		return nil, nil
+	}
+
+	data := &scoreData{}
+	content, err := os.ReadFile(filepath.Join(dir, scoreReport))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.Score != nil {
+		score := roundScore(math.Max(0, math.Min(*data.Score, maxScore)))
+		return &score, nil
+	}
+
+	return weightedScore(maxScore, tests, data.Weights), nil
+}
+
+func weightedScore(maxScore float64, tests []*submission_tasks.TestResult, weights map[string]float64) *float64 {
+	var total, passed float64
+	for _, test := range tests {
+		if test.Status == submission_tasks.TestSkipped {
+			continue
+		}
+
+		weight, ok := weights[test.Name]
+		if !ok || weight < 0 {
+			weight = 1
+		}
+		total += weight
+		if test.Status == submission_tasks.TestPassed {
+			passed += weight
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	score := roundScore(maxScore * passed / total)
+
+	return &score
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	submission_tasks "github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+func TestCollectScore(t *testing.T) {
+	tests := []*submission_tasks.TestResult{
+		{Name: "TestHello", Status: submission_tasks.TestPassed},
+		{Name: "TestBye", Status: submission_tasks.TestFailed},
+		{Name: "TestSkip", Status: submission_tasks.TestSkipped},
+	}
+
+	type testCase struct {
+		Title    string
+		MaxScore float64
+		Report   string
+		Tests    []*submission_tasks.TestResult
+		Want     *float64
+	}
+
+	score := func(value float64) *float64 { return &value }
+
+	testCases := []*testCase{
+		{Title: "no max score", MaxScore: 0, Report: `{"score": 5}`, Tests: tests, Want: nil},
+		{Title: "no report and tests", MaxScore: 10, Want: nil},
+		{Title: "reported score", MaxScore: 10, Report: `{"score": 7.5}`, Tests: tests, Want: score(7.5)},
+		{Title: "reported score above max", MaxScore: 10, Report: `{"score": 12}`, Want: score(10)},
+		{Title: "tests", MaxScore: 10, Tests: tests, Want: score(5)},
+		{
+			Title:    "weighted tests",
+			MaxScore: 10,
+			Report:   `{"weights": {"TestHello": 1, "TestBye": 2}}`,
+			Tests:    tests,
+			Want:     score(3.33),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			dir := t.TempDir()
+			if len(testCase.Report) != 0 {
+				err := os.WriteFile(filepath.Join(dir, scoreReport), []byte(testCase.Report), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := collectScore(dir, testCase.MaxScore, testCase.Tests)
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if (got == nil) != (testCase.Want == nil) || (got != nil && *got != *testCase.Want) {
+				t.Errorf("expected %v, got %v", testCase.Want, got)
+			}
+		})
+	}
+}
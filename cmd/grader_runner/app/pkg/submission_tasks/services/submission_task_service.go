@@ -3,24 +3,23 @@ package services
 import (
 	"context"
 
-	"github.com/docker/docker/client"
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/sandbox"
 	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
 )
 
-type DockerClientInterface interface {
-	client.CommonAPIClient
-}
-
 type SubmissionTaskService struct {
-	DockerClient DockerClientInterface
+	Sandbox sandbox.Sandbox
+	// LogsLimit caps the amount of output streamed live to grader_web
+	LogsLimit int
 }
 
 type SubmissionTaskServiceInterface interface {
 	RunSubmission(context.Context, *submission_tasks.SubmissionTask) error
 }
 
-func NewSubmissionTaskService(dockerClient DockerClientInterface) *SubmissionTaskService {
+func NewSubmissionTaskService(backend sandbox.Sandbox, logsLimit int) *SubmissionTaskService {
 	return &SubmissionTaskService{
-		DockerClient: dockerClient,
+		Sandbox:   backend,
+		LogsLimit: logsLimit,
 	}
 }
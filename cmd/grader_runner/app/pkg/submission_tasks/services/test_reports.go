@@ -0,0 +1,272 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+// Grading containers may write a test report into reportsDir, the runner
+// picks the first report it finds in the order of reportParsers.
+const (
+	reportsDir   = "/app/reports"
+	junitReport  = "junit.xml"
+	goTestReport = "go_test.json"
+	tapReport    = "report.tap"
+)
+
+type reportParser struct {
+	Filename string
+	Parse    func(io.Reader) ([]*submission_tasks.TestResult, error)
+}
+
+var reportParsers = []*reportParser{
+	{Filename: junitReport, Parse: parseJUnitReport},
+	{Filename: goTestReport, Parse: parseGoTestReport},
+	{Filename: tapReport, Parse: parseTAPReport},
+}
+
+var tapLineRegexp = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*)(?:#\s*(\S+)\s*(.*))?$`)
+
+func collectTestResults(dir string) ([]*submission_tasks.TestResult, error) {
+	for _, parser := range reportParsers {
+		file, err := os.Open(filepath.Join(dir, parser.Filename))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+		defer file.Close()
+
+		return parser.Parse(file)
+	}
+
+	return nil, nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	XMLName xml.Name          `xml:"testsuite"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+	Cases   []*junitTestCase  `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *junitProblem `xml:"skipped"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (p *junitProblem) String() string {
+	return strings.TrimSpace(strings.Join([]string{p.Message, strings.TrimSpace(p.Text)}, "\n"))
+}
+
+func parseJUnitReport(r io.Reader) ([]*submission_tasks.TestResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	suites := &junitTestSuites{}
+	if err := xml.Unmarshal(data, suites); err != nil {
+		suite := &junitTestSuite{}
+		if err := xml.Unmarshal(data, suite); err != nil {
+			return nil, err
+		}
+		suites.Suites = []*junitTestSuite{suite}
+	}
+
+	result := []*submission_tasks.TestResult{}
+	for _, suite := range suites.Suites {
+		result = appendJUnitSuite(result, suite)
+	}
+
+	return result, nil
+}
+
+func appendJUnitSuite(result []*submission_tasks.TestResult, suite *junitTestSuite) []*submission_tasks.TestResult {
+	for _, testCase := range suite.Cases {
+		testResult := &submission_tasks.TestResult{
+			Name:     testCase.Name,
+			Status:   submission_tasks.TestPassed,
+			Duration: testCase.Time,
+		}
+		if len(testCase.ClassName) != 0 {
+			testResult.Name = testCase.ClassName + "." + testCase.Name
+		}
+
+		switch {
+		case testCase.Failure != nil:
+			testResult.Status = submission_tasks.TestFailed
+			testResult.Message = testCase.Failure.String()
+		case testCase.Error != nil:
+			testResult.Status = submission_tasks.TestFailed
+			testResult.Message = testCase.Error.String()
+		case testCase.Skipped != nil:
+			testResult.Status = submission_tasks.TestSkipped
+			testResult.Message = testCase.Skipped.String()
+		}
+
+		result = append(result, testResult)
+	}
+
+	for _, nested := range suite.Suites {
+		result = appendJUnitSuite(result, nested)
+	}
+
+	return result
+}
+
+type goTestEvent struct {
+	Action  string  `json:"Action"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test"`
+	Elapsed float64 `json:"Elapsed"`
+	Output  string  `json:"Output"`
+}
+
+func parseGoTestReport(r io.Reader) ([]*submission_tasks.TestResult, error) {
+	result := []*submission_tasks.TestResult{}
+	tests := make(map[string]*submission_tasks.TestResult)
+	outputs := make(map[string]*strings.Builder)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+
+		event := &goTestEvent{}
+		if err := json.Unmarshal(line, event); err != nil {
+			return nil, err
+		}
+		if len(event.Test) == 0 {
+			continue
+		}
+
+		key := event.Package + "/" + event.Test
+		testResult, ok := tests[key]
+		if !ok {
+			testResult = &submission_tasks.TestResult{Name: event.Test}
+			tests[key] = testResult
+			outputs[key] = &strings.Builder{}
+			result = append(result, testResult)
+		}
+
+		switch event.Action {
+		case "output":
+			outputs[key].WriteString(event.Output)
+		case "pass":
+			testResult.Status = submission_tasks.TestPassed
+			testResult.Duration = event.Elapsed
+		case "fail":
+			testResult.Status = submission_tasks.TestFailed
+			testResult.Duration = event.Elapsed
+			testResult.Message = strings.TrimSpace(outputs[key].String())
+		case "skip":
+			testResult.Status = submission_tasks.TestSkipped
+			testResult.Duration = event.Elapsed
+			testResult.Message = strings.TrimSpace(outputs[key].String())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, testResult := range tests {
+		// Tests without a final action were interrupted, e.g. by a panic or a timeout
+		if len(testResult.Status) == 0 {
+			testResult.Status = submission_tasks.TestFailed
+			testResult.Message = strings.TrimSpace(outputs[key].String())
+		}
+	}
+
+	return result, nil
+}
+
+func parseTAPReport(r io.Reader) ([]*submission_tasks.TestResult, error) {
+	result := []*submission_tasks.TestResult{}
+	var current *submission_tasks.TestResult
+	diagnostics := []string{}
+
+	flushDiagnostics := func() {
+		if current != nil && len(diagnostics) > 0 {
+			current.Message = strings.TrimSpace(
+				strings.Join(append([]string{current.Message}, diagnostics...), "\n"),
+			)
+		}
+		diagnostics = diagnostics[:0]
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		indented := line != strings.TrimLeft(line, " \t")
+		matches := tapLineRegexp.FindStringSubmatch(trimmed)
+		if matches == nil || indented {
+			if current == nil {
+				continue
+			}
+			// Diagnostics are either comments or an indented YAML block
+			if strings.HasPrefix(trimmed, "#") {
+				diagnostics = append(diagnostics, strings.TrimSpace(trimmed[1:]))
+			} else if indented && len(trimmed) != 0 && trimmed != "---" && trimmed != "..." {
+				diagnostics = append(diagnostics, trimmed)
+			}
+			continue
+		}
+
+		flushDiagnostics()
+
+		current = &submission_tasks.TestResult{
+			Name:   strings.TrimSpace(matches[3]),
+			Status: submission_tasks.TestPassed,
+		}
+		if len(current.Name) == 0 {
+			current.Name = "test " + matches[2]
+		}
+		if matches[1] == "not ok" {
+			current.Status = submission_tasks.TestFailed
+		}
+
+		switch strings.ToUpper(matches[4]) {
+		case "SKIP", "TODO":
+			current.Status = submission_tasks.TestSkipped
+			current.Message = strings.TrimSpace(matches[5])
+		}
+
+		result = append(result, current)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flushDiagnostics()
+
+	return result, nil
+}
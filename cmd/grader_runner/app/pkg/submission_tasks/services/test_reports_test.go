@@ -0,0 +1,145 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	submission_tasks "github.com/maxshend/grader/cmd/grader_runner/app/pkg/submission_tasks"
+)
+
+func TestParseReports(t *testing.T) {
+	type testCase struct {
+		Title  string
+		Parse  func(string) ([]*submission_tasks.TestResult, error)
+		Report string
+		Want   []*submission_tasks.TestResult
+	}
+
+	parse := func(parser *reportParser) func(string) ([]*submission_tasks.TestResult, error) {
+		return func(report string) ([]*submission_tasks.TestResult, error) {
+			return parser.Parse(strings.NewReader(report))
+		}
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "junit",
+			Parse: parse(reportParsers[0]),
+			Report: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="main">
+    <testcase classname="main" name="TestHello" time="0.5"></testcase>
+    <testcase classname="main" name="TestBye" time="0.25">
+      <failure message="expected bye">main_test.go:10</failure>
+    </testcase>
+    <testcase name="TestSkip"><skipped/></testcase>
+  </testsuite>
+</testsuites>`,
+			Want: []*submission_tasks.TestResult{
+				{Name: "main.TestHello", Status: submission_tasks.TestPassed, Duration: 0.5},
+				{
+					Name:     "main.TestBye",
+					Status:   submission_tasks.TestFailed,
+					Duration: 0.25,
+					Message:  "expected bye\nmain_test.go:10",
+				},
+				{Name: "TestSkip", Status: submission_tasks.TestSkipped},
+			},
+		},
+		{
+			Title:  "junit single testsuite",
+			Parse:  parse(reportParsers[0]),
+			Report: `<testsuite><testcase name="TestHello"><error message="panic"/></testcase></testsuite>`,
+			Want: []*submission_tasks.TestResult{
+				{Name: "TestHello", Status: submission_tasks.TestFailed, Message: "panic"},
+			},
+		},
+		{
+			Title: "go test json",
+			Parse: parse(reportParsers[1]),
+			Report: `{"Action":"run","Package":"main","Test":"TestHello"}
+{"Action":"output","Package":"main","Test":"TestHello","Output":"=== RUN   TestHello\n"}
+{"Action":"pass","Package":"main","Test":"TestHello","Elapsed":0.1}
+{"Action":"run","Package":"main","Test":"TestBye"}
+{"Action":"output","Package":"main","Test":"TestBye","Output":"    main_test.go:10: expected bye\n"}
+{"Action":"fail","Package":"main","Test":"TestBye","Elapsed":0.2}
+{"Action":"run","Package":"main","Test":"TestPanic"}
+{"Action":"output","Package":"main","Test":"TestPanic","Output":"panic: boom\n"}
+{"Action":"fail","Package":"main","Elapsed":0.3}`,
+			Want: []*submission_tasks.TestResult{
+				{Name: "TestHello", Status: submission_tasks.TestPassed, Duration: 0.1},
+				{
+					Name:     "TestBye",
+					Status:   submission_tasks.TestFailed,
+					Duration: 0.2,
+					Message:  "main_test.go:10: expected bye",
+				},
+				{Name: "TestPanic", Status: submission_tasks.TestFailed, Message: "panic: boom"},
+			},
+		},
+		{
+			Title: "tap",
+			Parse: parse(reportParsers[2]),
+			Report: `TAP version 13
+1..4
+ok 1 - hello
+not ok 2 - bye
+  ---
+  message: expected bye
+  ...
+ok 3 - skipped # SKIP not implemented
+not ok 4
+# got nil`,
+			Want: []*submission_tasks.TestResult{
+				{Name: "hello", Status: submission_tasks.TestPassed},
+				{Name: "bye", Status: submission_tasks.TestFailed, Message: "message: expected bye"},
+				{Name: "skipped", Status: submission_tasks.TestSkipped, Message: "not implemented"},
+				{Name: "test 4", Status: submission_tasks.TestFailed, Message: "got nil"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			got, err := testCase.Parse(testCase.Report)
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Errorf("expected %+v, got %+v", testCase.Want, got)
+			}
+		})
+	}
+}
+
+func TestCollectTestResults(t *testing.T) {
+	t.Run("no report", func(t *testing.T) {
+		got, err := collectTestResults(t.TempDir())
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		if got != nil {
+			t.Errorf("expected to have no results, got %+v", got)
+		}
+	})
+
+	t.Run("tap report", func(t *testing.T) {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, tapReport), []byte("ok 1 - hello\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := collectTestResults(dir)
+		if err != nil {
+			t.Fatalf("expected to not have errors, got %v", err)
+		}
+		want := []*submission_tasks.TestResult{{Name: "hello", Status: submission_tasks.TestPassed}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	})
+}
@@ -1,15 +1,48 @@
 package submission_tasks
 
+const (
+	TestPassed  = "passed"
+	TestFailed  = "failed"
+	TestSkipped = "skipped"
+)
+
 type SubmissionTask struct {
 	WebhookURL   string            `json:"webhook_url"`
+	LogsURL      string            `json:"logs_url"`
 	Container    string            `json:"container"`
 	PartID       string            `json:"part_id"`
 	Files        []*SubmissionFile `json:"files"`
 	SubmissionID int64             `json:"submission_id"`
 	AccessToken  string            `json:"access_token"`
+	MaxScore     float64           `json:"max_score"`
+	Limits       ResourceLimits    `json:"limits"`
+}
+
+// ResourceLimits are applied to the grading container, zero values mean no limit
+type ResourceLimits struct {
+	MemoryMB       int64   `json:"memory_mb"`
+	CPUs           float64 `json:"cpus"`
+	Pids           int64   `json:"pids"`
+	TmpfsMB        int64   `json:"tmpfs_mb"`
+	TimeoutSeconds int64   `json:"timeout_seconds"`
 }
 
+// Verdicts describe why a submission was stopped before it finished
+const (
+	VerdictTimeout = "timeout"
+	VerdictOOM     = "oom"
+)
+
 type SubmissionFile struct {
 	URL  string `json:"url"`
 	Name string `json:"name"`
+	// Archive files are extracted into the submission directory keeping their directories
+	Archive bool `json:"archive"`
+}
+
+type TestResult struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration"`
+	Message  string  `json:"message"`
 }
@@ -1,7 +1,15 @@
 package main
 
-import "github.com/maxshend/grader/cmd/grader_runner/app"
+import (
+	"os"
+
+	"github.com/maxshend/grader/cmd/grader_runner/app"
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "dry-run" {
+		os.Exit(app.DryRun(os.Args[2:]))
+	}
+
 	app.Run()
 }
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maxshend/grader/pkg/attachments"
+	attachmentsRepo "github.com/maxshend/grader/pkg/attachments/repo"
+	"github.com/maxshend/grader/pkg/submissions"
+	submissionsRepo "github.com/maxshend/grader/pkg/submissions/repo"
+)
+
+const (
+	uploadsPath             = "./uploads"
+	migrateAttachmentsBatch = 100
+)
+
+// attachmentsRepoFromEnv returns the storage selected with ATTACHMENTS_STORAGE:
+// local files served by the web app (default) or an S3-compatible bucket
+func attachmentsRepoFromEnv(hostURL, secret string, taskLifetime time.Duration) (attachments.RepositoryInterface, error) {
+	urlExpiry, err := attachmentsURLExpiry(taskLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	switch storage := os.Getenv("ATTACHMENTS_STORAGE"); storage {
+	case "", "local":
+		return attachmentsRepo.NewAttachmentsInmemRepo(hostURL, uploadsPath, secret, urlExpiry), nil
+	case "s3":
+		return s3RepoFromEnv(urlExpiry)
+	default:
+		return nil, fmt.Errorf("unknown ATTACHMENTS_STORAGE %q", storage)
+	}
+}
+
+// attachmentsURLExpiry defaults to the task lifetime since download URLs are
+// signed once when the task is queued, ATTACHMENTS_URL_EXPIRY may only extend it
+func attachmentsURLExpiry(taskLifetime time.Duration) (time.Duration, error) {
+	value := os.Getenv("ATTACHMENTS_URL_EXPIRY")
+	if len(value) == 0 {
+		return taskLifetime, nil
+	}
+	urlExpiry, err := time.ParseDuration(value)
+	if err != nil || urlExpiry <= 0 {
+		return 0, fmt.Errorf("invalid ATTACHMENTS_URL_EXPIRY %q", value)
+	}
+	if urlExpiry < taskLifetime {
+		return 0, fmt.Errorf("ATTACHMENTS_URL_EXPIRY should not be shorter than the task lifetime %s", taskLifetime)
+	}
+
+	return urlExpiry, nil
+}
+
+func s3RepoFromEnv(urlExpiry time.Duration) (*attachmentsRepo.AttachmentsS3Repo, error) {
+	return attachmentsRepo.NewAttachmentsS3Repo(
+		os.Getenv("S3_ENDPOINT"),
+		os.Getenv("S3_REGION"),
+		os.Getenv("S3_BUCKET"),
+		os.Getenv("S3_ACCESS_KEY_ID"),
+		os.Getenv("S3_SECRET_ACCESS_KEY"),
+		urlExpiry,
+	)
+}
+
+// migrateAttachments copies uploads of submissions stored as local files to
+// the S3 bucket and points the attachments to their copies. It can be run again
+// after a failure since migrated attachments are skipped.
+func migrateAttachments(args []string) int {
+	flags := flag.NewFlagSet("migrate-attachments", flag.ContinueOnError)
+	deleteLocal := flags.Bool("delete", false, "delete local files after copying them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	hostURL := os.Getenv("HOST")
+	if len(hostURL) == 0 {
+		log.Fatal("HOST should be set")
+	}
+	dbURL := os.Getenv("DATABASE_URL")
+	if len(dbURL) == 0 {
+		log.Fatal("DATABASE_URL should be set")
+	}
+	dbConn, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer dbConn.Close()
+	if err = dbConn.Ping(); err != nil {
+		log.Fatal(err)
+	}
+	lifetime, err := taskLifetime()
+	if err != nil {
+		log.Fatal(err)
+	}
+	urlExpiry, err := attachmentsURLExpiry(lifetime)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s3Repo, err := s3RepoFromEnv(urlExpiry)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	submRepo := submissionsRepo.NewSubmissionsSQLRepo(dbConn)
+	// Only reads and deletes local files, download URLs aren't needed
+	localRepo := attachmentsRepo.NewAttachmentsInmemRepo(hostURL, uploadsPath, "", 0)
+	migrated, failed := 0, 0
+	var lastID int64
+	for {
+		batch, err := submRepo.GetAttachmentsAfter(lastID, migrateAttachmentsBatch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, att := range batch {
+			lastID = att.ID
+			if strings.HasPrefix(att.URL, attachmentsRepo.S3Scheme) {
+				continue
+			}
+			if err = migrateAttachment(submRepo, localRepo, s3Repo, att, *deleteLocal); err != nil {
+				log.Printf("attachment #%d of submission #%d: %v", att.ID, att.SubmissionID, err)
+				failed++
+				continue
+			}
+			migrated++
+		}
+	}
+
+	log.Printf("%d attachments migrated, %d failed", migrated, failed)
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func migrateAttachment(
+	submRepo submissions.RepositoryInterface,
+	from, to attachments.RepositoryInterface,
+	att *submissions.Attachment,
+	deleteSource bool,
+) error {
+	content, err := from.Open(att.URL)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	copied, err := to.Create(fmt.Sprintf("submissions/%d", att.SubmissionID), att.Name, content)
+	if err != nil {
+		return err
+	}
+	if err = submRepo.UpdateAttachmentURL(att.ID, copied.URL); err != nil {
+		if destroyErr := to.Destroy(copied.URL); destroyErr != nil {
+			log.Println(destroyErr)
+		}
+		return err
+	}
+	if deleteSource {
+		return from.Destroy(att.URL)
+	}
+
+	return nil
+}
@@ -8,22 +8,35 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/gorilla/mux"
 
 	assignmentsDelivery "github.com/maxshend/grader/pkg/assignments/delivery"
 	"github.com/maxshend/grader/pkg/assignments/repo"
 	assignmentsServices "github.com/maxshend/grader/pkg/assignments/services"
+	attachmentsDelivery "github.com/maxshend/grader/pkg/attachments/delivery"
 	attachmentsRepo "github.com/maxshend/grader/pkg/attachments/repo"
+	"github.com/maxshend/grader/pkg/courses"
+	coursesDelivery "github.com/maxshend/grader/pkg/courses/delivery"
+	coursesRepo "github.com/maxshend/grader/pkg/courses/repo"
+	coursesServices "github.com/maxshend/grader/pkg/courses/services"
+	"github.com/maxshend/grader/pkg/gitsource"
 	"github.com/maxshend/grader/pkg/sessions"
 
 	submissionsDelivery "github.com/maxshend/grader/pkg/submissions/delivery"
 	submissionsRepo "github.com/maxshend/grader/pkg/submissions/repo"
 	submissionsServices "github.com/maxshend/grader/pkg/submissions/services"
 
+	"github.com/maxshend/grader/pkg/tokens"
+	tokensRepo "github.com/maxshend/grader/pkg/tokens/repo"
+	tokensServices "github.com/maxshend/grader/pkg/tokens/services"
+
+	"github.com/maxshend/grader/pkg/users"
 	usersDelivery "github.com/maxshend/grader/pkg/users/delivery"
 	usersRepo "github.com/maxshend/grader/pkg/users/repo"
 	usersServices "github.com/maxshend/grader/pkg/users/services"
+	"github.com/maxshend/grader/pkg/utils"
 
 	sessionsDelivery "github.com/maxshend/grader/pkg/sessions/delivery"
 	sessionsRepo "github.com/maxshend/grader/pkg/sessions/repo"
@@ -42,9 +55,16 @@ var staticFS embed.FS
 //go:embed all:templates/*
 var templatesFS embed.FS
 
+//go:embed api/openapi.yaml
+var openAPIDoc []byte
+
 const oauthVkPath = "/sessions/oauth/vk"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate-attachments" {
+		os.Exit(migrateAttachments(os.Args[2:]))
+	}
+
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if len(jwtSecret) == 0 {
 		log.Fatal("JWT_SECRET should be set")
@@ -109,10 +129,23 @@ func main() {
 
 	assignmentsRepo := repo.NewAssignmentsSQLRepo(dbConn)
 	submRepo := submissionsRepo.NewSubmissionsSQLRepo(dbConn)
-	attachRepo := attachmentsRepo.NewAttachmentsInmemRepo(os.Getenv("HOST"), "./uploads")
+	lifetime, err := taskLifetime()
+	if err != nil {
+		log.Fatal(err)
+	}
+	attachRepo, err := attachmentsRepoFromEnv(hostURL, jwtSecret, lifetime)
+	if err != nil {
+		log.Fatal(err)
+	}
 	userRepo := usersRepo.NewUsersSQLRepo(dbConn)
 	sessionRepo := sessionsRepo.NewSessionsSQLRepo(dbConn)
+	courseRepo := coursesRepo.NewCoursesSQLRepo(dbConn)
+	tokenRepo := tokensRepo.NewTokensSQLRepo(dbConn)
 
+	rateLimit, err := submissionRateLimit()
+	if err != nil {
+		log.Fatal(err)
+	}
 	assignmentsService := assignmentsServices.NewAssignmentsService(
 		webhookFullURL,
 		assignmentsRepo,
@@ -121,9 +154,14 @@ func main() {
 		rabbitCh,
 		rabbitQueueName,
 		jwtSecret,
+		gitsource.NewFetcher(gitAllowedHosts()),
+		rateLimit,
+		lifetime,
 	)
 	submissionsService := submissionsServices.NewSubmissionsService(submRepo, jwtSecret)
 	usersService := usersServices.NewUsersService(userRepo)
+	coursesService := coursesServices.NewCoursesService(courseRepo, userRepo)
+	tokensService := tokensServices.NewTokensService(tokenRepo)
 
 	sessionManager := sessionsServices.NewHttpSession(sessionRepo)
 
@@ -131,15 +169,27 @@ func main() {
 		assignmentsService,
 		sessionManager,
 		submissionsService,
+		coursesService,
 		templatesFS,
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
-	usersHandler, err := usersDelivery.NewUsersHttpHandler(usersService, sessionManager, templatesFS)
+	assignmentsApiHandler := assignmentsDelivery.NewAssignmentsApiHandler(
+		assignmentsService,
+		sessionManager,
+		submissionsService,
+		coursesService,
+	)
+	coursesHandler, err := coursesDelivery.NewCoursesHttpHandler(coursesService, sessionManager, templatesFS)
 	if err != nil {
 		log.Fatal(err)
 	}
+	usersHandler, err := usersDelivery.NewUsersHttpHandler(usersService, tokensService, sessionManager, templatesFS)
+	if err != nil {
+		log.Fatal(err)
+	}
+	usersApiHandler := usersDelivery.NewUsersApiHandler(usersService, sessionManager)
 	submissionsHandler := submissionsDelivery.NewSubmissionsHttpHandler(submissionsService)
 
 	oauthCreds := map[string]*sessions.OauthCred{
@@ -167,19 +217,45 @@ func main() {
 	router := mux.NewRouter()
 
 	adminPages := router.PathPrefix("/admin").Subrouter()
-	adminPages.HandleFunc("/assignments", assignmentsHandler.GetAll).Methods("GET")
-	adminPages.HandleFunc("/assignments/", assignmentsHandler.Create).Methods("POST")
-	adminPages.HandleFunc("/assignments/new", assignmentsHandler.New).Methods("GET")
-	adminPages.HandleFunc("/assignments/{id}/edit", assignmentsHandler.Edit).Methods("GET")
-	adminPages.HandleFunc("/assignments/{id}", assignmentsHandler.Update).Methods("POST")
-	adminPages.HandleFunc("/assignments/{id}", assignmentsHandler.Show).Methods("GET")
-	adminPages.HandleFunc("/users", usersHandler.GetAll).Methods("GET")
-	adminPages.HandleFunc("/users/{id}/edit", usersHandler.Edit).Methods("GET")
-	adminPages.HandleFunc("/users/{id}", usersHandler.Update).Methods("POST")
-	adminPages.Use(
-		sessions.AuthMiddleware(sessionManager, userRepo),
-		sessions.AdminPolicyMiddleware(sessionManager),
-	)
+	adminPages.Use(sessions.AuthMiddleware(sessionManager, userRepo))
+
+	manageAssignments := adminPages.PathPrefix("/assignments").Subrouter()
+	manageAssignments.HandleFunc("/", assignmentsHandler.Create).Methods("POST")
+	manageAssignments.HandleFunc("/new", assignmentsHandler.New).Methods("GET")
+	manageAssignments.HandleFunc("/{id}/edit", assignmentsHandler.Edit).Methods("GET")
+	manageAssignments.HandleFunc("/{id}", assignmentsHandler.Update).Methods("POST")
+	manageAssignments.Use(sessions.PolicyMiddleware(sessionManager, users.ManageAssignments))
+
+	viewSubmissions := adminPages.PathPrefix("/assignments").Subrouter()
+	viewSubmissions.HandleFunc("", assignmentsHandler.GetAll).Methods("GET")
+	viewSubmissions.HandleFunc("/{id}", assignmentsHandler.Show).Methods("GET")
+	viewSubmissions.Use(sessions.PolicyMiddleware(sessionManager, users.ViewSubmissions))
+
+	regradeSubmissions := adminPages.PathPrefix("/assignments/{id}").Subrouter()
+	regradeSubmissions.HandleFunc("/regrade", assignmentsHandler.Regrade).Methods("POST")
+	regradeSubmissions.HandleFunc(
+		"/submissions/{submission_id}/regrade",
+		assignmentsHandler.RegradeSubmission,
+	).Methods("POST")
+	regradeSubmissions.Use(sessions.PolicyMiddleware(sessionManager, users.RegradeSubmissions))
+
+	manageCourses := adminPages.PathPrefix("/courses").Subrouter()
+	manageCourses.HandleFunc("", coursesHandler.GetAll).Methods("GET")
+	manageCourses.HandleFunc("/", coursesHandler.Create).Methods("POST")
+	manageCourses.HandleFunc("/new", coursesHandler.New).Methods("GET")
+	manageCourses.HandleFunc("/{id}/edit", coursesHandler.Edit).Methods("GET")
+	manageCourses.HandleFunc("/{id}", coursesHandler.Update).Methods("POST")
+	manageCourses.HandleFunc("/{id}", coursesHandler.Show).Methods("GET")
+	manageCourses.HandleFunc("/{id}/invite_code", coursesHandler.ResetInviteCode).Methods("POST")
+	manageCourses.HandleFunc("/{id}/members", coursesHandler.AddMember).Methods("POST")
+	manageCourses.HandleFunc("/{id}/members/{user_id}/delete", coursesHandler.RemoveMember).Methods("POST")
+	manageCourses.Use(sessions.PolicyMiddleware(sessionManager, users.ManageCourses))
+
+	manageUsers := adminPages.PathPrefix("/users").Subrouter()
+	manageUsers.HandleFunc("", usersHandler.GetAll).Methods("GET")
+	manageUsers.HandleFunc("/{id}/edit", usersHandler.Edit).Methods("GET")
+	manageUsers.HandleFunc("/{id}", usersHandler.Update).Methods("POST")
+	manageUsers.Use(sessions.PolicyMiddleware(sessionManager, users.ManageUsers))
 
 	router.HandleFunc("/signup", usersHandler.New).Methods("GET")
 	router.HandleFunc("/users", usersHandler.Create).Methods("POST")
@@ -192,22 +268,111 @@ func main() {
 
 	authPages.HandleFunc("/profile", usersHandler.EditProfile).Methods("GET")
 	authPages.HandleFunc("/profile", usersHandler.UpdateProfile).Methods("POST")
+	authPages.HandleFunc("/profile/tokens", usersHandler.CreateToken).Methods("POST")
+	authPages.HandleFunc("/profile/tokens/{id:[0-9]+}/revoke", usersHandler.RevokeToken).Methods("POST")
 	authPages.HandleFunc("/assignments", assignmentsHandler.PersonalAssignments).Methods("GET")
 	authPages.HandleFunc("/", assignmentsHandler.PersonalAssignments).Methods("GET")
-	authPages.HandleFunc("/assignments/{id}/submissions/new", assignmentsHandler.NewSubmission).Methods("GET")
-	authPages.HandleFunc("/assignments/{id}/submissions", assignmentsHandler.CreateSubmission).Methods("POST")
-	authPages.HandleFunc("/assignments/{id}", assignmentsHandler.ShowPersonal).Methods("GET")
+	authPages.HandleFunc("/courses", coursesHandler.PersonalCourses).Methods("GET")
+	authPages.HandleFunc("/courses/join", coursesHandler.Join).Methods("POST")
 
 	authPages.HandleFunc("/logout", sessionsHandler.Destroy).Methods("POST")
 
 	authPages.Use(sessions.AuthMiddleware(sessionManager, userRepo))
 
+	coursePages := authPages.PathPrefix("/courses/{id:[0-9]+}").Subrouter()
+	coursePages.HandleFunc("", coursesHandler.ShowPersonal).Methods("GET")
+	coursePages.Use(courses.CourseAccessMiddleware(sessionManager, courseRepo))
+
+	assignmentPages := authPages.PathPrefix("/assignments/{id:[0-9]+}").Subrouter()
+	assignmentPages.HandleFunc("/submissions/new", assignmentsHandler.NewSubmission).Methods("GET")
+	assignmentPages.HandleFunc("/submissions", assignmentsHandler.CreateSubmission).Methods("POST")
+	assignmentPages.HandleFunc("/submissions/{submission_id}", assignmentsHandler.ShowSubmission).Methods("GET")
+	assignmentPages.HandleFunc(
+		"/submissions/{submission_id}/attachments/{attachment_id:[0-9]+}",
+		assignmentsHandler.DownloadAttachment,
+	).Methods("GET")
+	assignmentPages.HandleFunc(
+		"/submissions/{submission_id}/events",
+		assignmentsHandler.SubmissionEvents,
+	).Methods("GET")
+	assignmentPages.HandleFunc("", assignmentsHandler.ShowPersonal).Methods("GET")
+	assignmentPages.Use(courses.AssignmentAccessMiddleware(sessionManager, courseRepo))
+
+	apiPages := router.PathPrefix("/api/v1").Subrouter()
+	apiPages.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.RenderAPIError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	})
+	apiPages.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPIDoc)
+	}).Methods("GET")
+	apiPages.Use(utils.APIMiddleware)
+
+	apiAuth := apiPages.NewRoute().Subrouter()
+	apiAuth.Use(tokens.APIAuthMiddleware(sessionManager, userRepo, tokenRepo))
+
+	apiRead := apiAuth.NewRoute().Subrouter()
+	apiRead.HandleFunc("/users/me", usersApiHandler.Me).Methods("GET")
+	apiRead.HandleFunc("/assignments", assignmentsApiHandler.GetAll).Methods("GET")
+	apiRead.Use(tokens.ScopeMiddleware(tokens.ScopeRead))
+
+	apiManageAssignments := apiAuth.PathPrefix("/assignments").Subrouter()
+	apiManageAssignments.HandleFunc("", assignmentsApiHandler.Create).Methods("POST")
+	apiManageAssignments.HandleFunc("/{id:[0-9]+}", assignmentsApiHandler.Update).Methods("PUT")
+	apiManageAssignments.HandleFunc("/{id:[0-9]+}/validations", assignmentsApiHandler.Validations).Methods("GET")
+	apiManageAssignments.HandleFunc("/{id:[0-9]+}/validations", assignmentsApiHandler.UploadSolutions).Methods("POST")
+	apiManageAssignments.Use(
+		tokens.ScopeMiddleware(tokens.ScopeManage),
+		sessions.PolicyMiddleware(sessionManager, users.ManageAssignments),
+	)
+
+	apiManageUsers := apiAuth.PathPrefix("/users").Subrouter()
+	apiManageUsers.HandleFunc("", usersApiHandler.GetAll).Methods("GET")
+	apiManageUsers.HandleFunc("/{id:[0-9]+}", usersApiHandler.Show).Methods("GET")
+	apiManageUsers.Use(
+		tokens.ScopeMiddleware(tokens.ScopeRead),
+		sessions.PolicyMiddleware(sessionManager, users.ManageUsers),
+	)
+
+	apiAssignment := apiAuth.PathPrefix("/assignments/{id:[0-9]+}").Subrouter()
+	apiAssignment.Use(courses.AssignmentAccessMiddleware(sessionManager, courseRepo))
+
+	apiReadAssignment := apiAssignment.NewRoute().Subrouter()
+	apiReadAssignment.HandleFunc("", assignmentsApiHandler.Show).Methods("GET")
+	apiReadAssignment.HandleFunc("/submissions", assignmentsApiHandler.Submissions).Methods("GET")
+	apiReadAssignment.HandleFunc(
+		"/submissions/{submission_id:[0-9]+}",
+		assignmentsApiHandler.ShowSubmission,
+	).Methods("GET")
+	apiReadAssignment.Use(tokens.ScopeMiddleware(tokens.ScopeRead))
+
+	apiSubmit := apiAssignment.NewRoute().Subrouter()
+	apiSubmit.HandleFunc("/submissions", assignmentsApiHandler.Submit).Methods("POST")
+	apiSubmit.Use(tokens.ScopeMiddleware(tokens.ScopeSubmit))
+
 	router.HandleFunc(webhookURL+"{id}", submissionsHandler.Webhook).Methods("POST")
+	router.HandleFunc(webhookURL+"{id}/logs", submissionsHandler.Logs).Methods("POST")
 
 	router.PathPrefix("/static/").Handler(http.FileServer(http.FS(staticFS)))
 
-	uploadsFs := http.FileServer(http.Dir("./uploads"))
-	router.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", uploadsFs))
+	// Local uploads are available to the runner only with signed URLs of the tasks
+	if localRepo, ok := attachRepo.(*attachmentsRepo.AttachmentsInmemRepo); ok {
+		attachmentsHandler := attachmentsDelivery.NewAttachmentsHttpHandler(localRepo)
+		router.PathPrefix("/uploads/").HandlerFunc(attachmentsHandler.Download).Methods("GET")
+	}
 
 	log.Fatal(http.ListenAndServe(":8080", router))
 }
+
+// gitAllowedHosts reads the comma separated GIT_ALLOWED_HOSTS, git submissions
+// of other hosts are rejected when it's set
+func gitAllowedHosts() []string {
+	hosts := []string{}
+	for _, host := range strings.Split(os.Getenv("GIT_ALLOWED_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); len(host) > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
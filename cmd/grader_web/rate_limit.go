@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	assignmentsServices "github.com/maxshend/grader/pkg/assignments/services"
+)
+
+// submissionRateLimit reads SUBMISSION_RATE_LIMIT written as COUNT/WINDOW,
+// for example 60/1h, 0 turns the limit off
+func submissionRateLimit() (assignmentsServices.RateLimit, error) {
+	value := os.Getenv("SUBMISSION_RATE_LIMIT")
+	if len(value) == 0 {
+		return assignmentsServices.DefaultRateLimit, nil
+	}
+	if value == "0" {
+		return assignmentsServices.RateLimit{}, nil
+	}
+
+	count, window, found := strings.Cut(value, "/")
+	submissions, err := strconv.Atoi(count)
+	if !found || err != nil || submissions <= 0 {
+		return assignmentsServices.RateLimit{}, fmt.Errorf("invalid SUBMISSION_RATE_LIMIT %q", value)
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return assignmentsServices.RateLimit{}, fmt.Errorf("invalid SUBMISSION_RATE_LIMIT %q", value)
+	}
+
+	return assignmentsServices.RateLimit{Submissions: submissions, Window: duration}, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+// taskLifetime reads the settings of the grader worker, WORKER_MAX_RETRIES,
+// WORKER_MAX_RETRY_DELAY and WORKER_JOB_TIMEOUT, so that tokens of the tasks
+// outlive every attempt of the worker
+func taskLifetime() (time.Duration, error) {
+	maxRetries := utils.DefaultWorkerMaxRetries
+	if value := os.Getenv("WORKER_MAX_RETRIES"); len(value) != 0 {
+		var err error
+		maxRetries, err = strconv.Atoi(value)
+		if err != nil || maxRetries < 0 {
+			return 0, fmt.Errorf("invalid WORKER_MAX_RETRIES %q", value)
+		}
+	}
+	maxRetryDelay, err := durationFromEnv("WORKER_MAX_RETRY_DELAY", utils.DefaultWorkerMaxRetryDelay)
+	if err != nil {
+		return 0, err
+	}
+	jobTimeout, err := durationFromEnv("WORKER_JOB_TIMEOUT", utils.DefaultWorkerJobTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	return utils.TaskLifetime(maxRetries, maxRetryDelay, jobTimeout), nil
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	result, err := time.ParseDuration(value)
+	if err != nil || result <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+
+	return result, nil
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -30,7 +33,40 @@ type SubmissionFile struct {
 	Name string `json:"name"`
 }
 
+type TaskQueue struct {
+	Channel      *amqp.Channel
+	QueueName    string
+	Policy       *RetryPolicy
+	PollInterval time.Duration
+	JobTimeout   time.Duration
+}
+
+type gradingErrorResponse struct {
+	Pass  bool   `json:"pass"`
+	Error bool   `json:"error"`
+	Text  string `json:"text"`
+}
+
+const msgGradingError = "Submission can't be graded, please contact the course staff"
+
+// errTaskRejected means the grader answered with a client error, the same task
+// would be rejected again so it's not retried
+var errTaskRejected = errors.New("grader rejected the task")
+
 func main() {
+	retryPolicy, err := NewRetryPolicyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	pollInterval, err := durationFromEnv("WORKER_POLL_INTERVAL", defaultPollInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobTimeout, err := durationFromEnv("WORKER_JOB_TIMEOUT", defaultJobTimeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	rabbitURL := os.Getenv("RABBITMQ_URL")
 	if len(rabbitURL) == 0 {
 		log.Fatal("RABBITMQ_URL should be set")
@@ -61,6 +97,10 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	err = declareRetryQueues(rabbitCh, rabbitQueueName, retryPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	err = rabbitCh.Qos(
 		1,     // prefetch count
@@ -84,64 +124,195 @@ func main() {
 		log.Fatal(err)
 	}
 
+	queue := &TaskQueue{
+		Channel:      rabbitCh,
+		QueueName:    rabbitQueueName,
+		Policy:       retryPolicy,
+		PollInterval: pollInterval,
+		JobTimeout:   jobTimeout,
+	}
+
 	wg := &sync.WaitGroup{}
 	wg.Add(workersCount)
 
-	for i := 0; i <= workersCount; i++ {
-		go submitWorker(wg, tasks)
+	for i := 0; i < workersCount; i++ {
+		go submitWorker(wg, queue, tasks)
 	}
 
 	log.Println("Grader Worker started...")
 	wg.Wait()
 }
 
-func submitWorker(wg *sync.WaitGroup, tasks <-chan amqp.Delivery) {
+func submitWorker(wg *sync.WaitGroup, queue *TaskQueue, tasks <-chan amqp.Delivery) {
 	defer wg.Done()
 	for taskItem := range tasks {
 		log.Printf("Incoming Task: %+v\n", taskItem)
 
-		func(taskItem amqp.Delivery) {
-			defer func() {
-				err := taskItem.Ack(false)
-
-				if err != nil {
-					log.Println(err)
-				}
-			}()
-
-			task := &SubmissionTask{}
-			err := json.Unmarshal(taskItem.Body, task)
-			if err != nil {
-				log.Printf("Can't unpack json: %q\n", err)
-				return
-			}
-
-			response, err := sendGraderRequest(task.GraderURL, taskItem.Body)
-			if err != nil {
-				log.Printf(
-					"Error while sending submission #%d to the grader (%s): %q\n",
-					task.SubmissionID,
-					task.GraderURL,
-					err,
-				)
-				return
-			}
-
-			responseBody, err := io.ReadAll(response.Body)
-			if err != nil {
-				log.Printf("Error while reading response body: %q\n", err)
-				return
-			}
-
-			log.Printf(
-				"Grader response (%s) for submission #%d status %d\n%s\n",
-				task.GraderURL,
-				task.SubmissionID,
-				response.StatusCode,
-				responseBody,
-			)
-		}(taskItem)
+		queue.Handle(taskItem)
+	}
+}
+
+func (q *TaskQueue) Handle(taskItem amqp.Delivery) {
+	task := &SubmissionTask{}
+	err := json.Unmarshal(taskItem.Body, task)
+	if err != nil {
+		log.Printf("Can't unpack json: %q\n", err)
+		q.settle(taskItem, q.deadLetter(taskItem, err))
+		return
+	}
+
+	err = q.gradeSubmission(task, taskItem.Body)
+	if err == nil {
+		q.settle(taskItem, nil)
+		return
+	}
+
+	log.Printf(
+		"Error while sending submission #%d to the grader (%s): %q\n",
+		task.SubmissionID,
+		task.GraderURL,
+		err,
+	)
+
+	attempt := retryCount(taskItem.Headers) + 1
+	// A lost job never ran to the end, so it's sent again without waiting
+	if attempt <= q.Policy.MaxRetries && errors.Is(err, errJobLost) {
+		log.Printf("Resending delivery (attempt %d of %d)\n", attempt, q.Policy.MaxRetries)
+		q.settle(taskItem, q.publish(q.QueueName, taskItem, attempt, err))
+		return
+	}
+	if attempt <= q.Policy.MaxRetries && !errors.Is(err, errJobCancelled) && !errors.Is(err, errTaskRejected) {
+		q.settle(taskItem, q.retry(taskItem, attempt, err))
+		return
+	}
+
+	log.Printf("Submission #%d can't be graded after %d attempts\n", task.SubmissionID, attempt)
+	err = q.deadLetter(taskItem, err)
+	if err == nil {
+		notifyGradingError(task)
+	}
+	q.settle(taskItem, err)
+}
+
+// settle acks the delivery once it has been handled or moved to another queue,
+// otherwise it's requeued so the task is not lost.
+func (q *TaskQueue) settle(taskItem amqp.Delivery, err error) {
+	if err != nil {
+		log.Printf("Can't reschedule delivery: %q\n", err)
+		if err := taskItem.Nack(false, true); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	if err := taskItem.Ack(false); err != nil {
+		log.Println(err)
+	}
+}
+
+func (q *TaskQueue) retry(taskItem amqp.Delivery, attempt int, cause error) error {
+	delay := q.Policy.Delay(attempt)
+	log.Printf("Retrying delivery in %s (attempt %d of %d)\n", delay, attempt, q.Policy.MaxRetries)
+
+	return q.publish(retryQueueName(q.QueueName, delay), taskItem, attempt, cause)
+}
+
+func (q *TaskQueue) deadLetter(taskItem amqp.Delivery, cause error) error {
+	return q.publish(deadLetterQueueName(q.QueueName), taskItem, retryCount(taskItem.Headers), cause)
+}
+
+func (q *TaskQueue) publish(queueName string, taskItem amqp.Delivery, attempt int, cause error) error {
+	return q.Channel.PublishWithContext(
+		context.Background(),
+		"",
+		queueName,
+		false,
+		false,
+		amqp.Publishing{
+			Headers: amqp.Table{
+				retryCountHeader: int32(attempt),
+				lastErrorHeader:  cause.Error(),
+			},
+			DeliveryMode: amqp.Persistent,
+			ContentType:  taskItem.ContentType,
+			Body:         taskItem.Body,
+		},
+	)
+}
+
+func (q *TaskQueue) gradeSubmission(task *SubmissionTask, body []byte) error {
+	response, err := sendGraderRequest(task.GraderURL, body)
+	if err != nil {
+		return err
 	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	log.Printf(
+		"Grader response (%s) for submission #%d status %d\n%s\n",
+		task.GraderURL,
+		task.SubmissionID,
+		response.StatusCode,
+		responseBody,
+	)
+	if isClientError(response.StatusCode) {
+		return fmt.Errorf("%w: status %d", errTaskRejected, response.StatusCode)
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("grader responded with status %d", response.StatusCode)
+	}
+	if response.StatusCode != http.StatusAccepted {
+		return nil
+	}
+
+	job := &GraderJob{}
+	err = json.Unmarshal(responseBody, job)
+	if err != nil {
+		return err
+	}
+	statusURL, err := jobURL(task.GraderURL, response, job)
+	if err != nil {
+		return err
+	}
+
+	return q.waitForJob(statusURL, task.AccessToken)
+}
+
+// isClientError is true for 4xx statuses except the ones which may pass on retry
+func isClientError(statusCode int) bool {
+	return statusCode >= 400 && statusCode < 500 &&
+		statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests
+}
+
+func notifyGradingError(task *SubmissionTask) {
+	requestBody, err := json.Marshal(&gradingErrorResponse{Pass: false, Error: true, Text: msgGradingError})
+	if err != nil {
+		log.Printf("Can't pack json: %q\n", err)
+		return
+	}
+	request, err := http.NewRequest("POST", task.WebhookURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		log.Printf("Can't create webhook request: %q\n", err)
+		return
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Authorization", task.AccessToken)
+
+	client := &http.Client{
+		Timeout: time.Minute * 1,
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		log.Printf("Error while reporting grading error of submission #%d: %q\n", task.SubmissionID, err)
+		return
+	}
+	defer response.Body.Close()
+
+	log.Printf("Webhook %q Response %d\n", task.WebhookURL, response.StatusCode)
 }
 
 func sendGraderRequest(posturl string, body []byte) (*http.Response, error) {
@@ -152,7 +323,7 @@ func sendGraderRequest(posturl string, body []byte) (*http.Response, error) {
 	request.Header.Add("Content-Type", "application/json")
 
 	client := &http.Client{
-		Timeout: time.Minute * 5,
+		Timeout: time.Minute * 1,
 	}
 	response, err := client.Do(request)
 	if err != nil {
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGradeSubmissionStatus(t *testing.T) {
+	queue := &TaskQueue{}
+
+	testCases := []struct {
+		StatusCode int
+		Rejected   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, true},
+		{http.StatusUnauthorized, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, testCase := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(testCase.StatusCode)
+		}))
+
+		err := queue.gradeSubmission(&SubmissionTask{GraderURL: server.URL}, []byte("{}"))
+		server.Close()
+
+		if testCase.StatusCode == http.StatusOK && err != nil {
+			t.Errorf("expected to not have errors for status %d, got %v", testCase.StatusCode, err)
+		}
+		if testCase.StatusCode != http.StatusOK && err == nil {
+			t.Errorf("expected to have errors for status %d", testCase.StatusCode)
+		}
+		if errors.Is(err, errTaskRejected) != testCase.Rejected {
+			t.Errorf("expected status %d to be rejected: %v, got %v", testCase.StatusCode, testCase.Rejected, err)
+		}
+	}
+}
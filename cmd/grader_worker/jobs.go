@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+const (
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+
+	defaultPollInterval = 5 * time.Second
+	defaultJobTimeout   = utils.DefaultWorkerJobTimeout
+)
+
+var (
+	errJobCancelled = errors.New("grading job was cancelled")
+	// errJobLost means the runner doesn't know the job anymore, e.g. after a restart
+	errJobLost = errors.New("grading job was lost by the grader")
+)
+
+type GraderJob struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	result, err := time.ParseDuration(value)
+	if err != nil || result <= 0 {
+		return 0, fmt.Errorf("%s should be a positive duration", name)
+	}
+
+	return result, nil
+}
+
+// jobURL resolves the Location of an accepted grading job against the grader URL
+func jobURL(graderURL string, response *http.Response, job *GraderJob) (string, error) {
+	base, err := url.Parse(graderURL)
+	if err != nil {
+		return "", err
+	}
+
+	location := response.Header.Get("Location")
+	if len(location) == 0 {
+		location = "/api/v1/jobs/" + job.ID
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+func (q *TaskQueue) waitForJob(jobURL string, accessToken string) error {
+	deadline := time.Now().Add(q.JobTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(q.PollInterval)
+
+		job, err := getJob(jobURL, accessToken)
+		if errors.Is(err, errJobLost) {
+			return err
+		}
+		if err != nil {
+			log.Printf("Can't get grading job %s: %q\n", jobURL, err)
+			continue
+		}
+
+		switch job.Status {
+		case jobSucceeded:
+			return nil
+		case jobFailed:
+			return fmt.Errorf("grading job failed: %s", job.Error)
+		case jobCancelled:
+			return errJobCancelled
+		}
+	}
+
+	cancelJob(jobURL, accessToken)
+
+	return fmt.Errorf("grading job didn't finish in %s", q.JobTimeout)
+}
+
+func getJob(jobURL string, accessToken string) (*GraderJob, error) {
+	request, err := http.NewRequest("GET", jobURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Authorization", accessToken)
+
+	client := &http.Client{
+		Timeout: time.Minute * 1,
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errJobLost
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("grader responded with status %d", response.StatusCode)
+	}
+
+	job := &GraderJob{}
+	err = json.NewDecoder(response.Body).Decode(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func cancelJob(jobURL string, accessToken string) {
+	request, err := http.NewRequest("DELETE", jobURL, nil)
+	if err != nil {
+		log.Printf("Can't create cancel request: %q\n", err)
+		return
+	}
+	request.Header.Add("Authorization", accessToken)
+
+	client := &http.Client{
+		Timeout: time.Minute * 1,
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		log.Printf("Can't cancel grading job %s: %q\n", jobURL, err)
+		return
+	}
+	defer response.Body.Close()
+}
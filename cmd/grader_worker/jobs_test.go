@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWaitForJob(t *testing.T) {
+	queue := &TaskQueue{PollInterval: time.Millisecond, JobTimeout: 50 * time.Millisecond}
+
+	type testCase struct {
+		Title   string
+		Status  string
+		Success bool
+		Check   func(*testing.T, error)
+	}
+
+	testCases := []*testCase{
+		{Title: "succeeded", Status: jobSucceeded, Success: true},
+		{Title: "failed", Status: jobFailed, Success: false},
+		{
+			Title:   "cancelled",
+			Status:  jobCancelled,
+			Success: false,
+			Check: func(t *testing.T, err error) {
+				if !errors.Is(err, errJobCancelled) {
+					t.Errorf("expected to have %v, got %v", errJobCancelled, err)
+				}
+			},
+		},
+		{
+			Title:   "timeout",
+			Status:  "running",
+			Success: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			cancelled := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if r.Method == "DELETE" {
+					cancelled = true
+				}
+				fmt.Fprintf(w, `{"id": "1", "status": %q}`, testCase.Status)
+			}))
+			defer server.Close()
+
+			err := queue.waitForJob(server.URL+"/api/v1/jobs/1", "token")
+			if testCase.Success && err != nil {
+				t.Errorf("expected to not have errors, got %v", err)
+			} else if !testCase.Success && err == nil {
+				t.Errorf("expected to have errors")
+			}
+			if testCase.Check != nil {
+				testCase.Check(t, err)
+			}
+			if testCase.Status == "running" && !cancelled {
+				t.Errorf("expected to cancel the job after timeout")
+			}
+		})
+	}
+}
+
+func TestWaitForLostJob(t *testing.T) {
+	queue := &TaskQueue{PollInterval: time.Millisecond, JobTimeout: time.Minute}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	err := queue.waitForJob(server.URL+"/api/v1/jobs/1", "token")
+	if !errors.Is(err, errJobLost) {
+		t.Errorf("expected to have %v, got %v", errJobLost, err)
+	}
+}
+
+func TestJobURL(t *testing.T) {
+	response := &http.Response{Header: http.Header{}}
+	response.Header.Set("Location", "/api/v1/jobs/1")
+
+	got, err := jobURL("http://runner:8021/api/v1/grader", response, &GraderJob{ID: "1"})
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if want := "http://runner:8021/api/v1/jobs/1"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
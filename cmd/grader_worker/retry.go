@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/maxshend/grader/pkg/utils"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	retryCountHeader  = "x-retry-count"
+	lastErrorHeader   = "x-last-error"
+	defaultMaxRetries = utils.DefaultWorkerMaxRetries
+	defaultRetryDelay = utils.DefaultWorkerRetryDelay
+	defaultMaxDelay   = utils.DefaultWorkerMaxRetryDelay
+)
+
+// RetryPolicy describes how failed deliveries are redelivered. Every delay
+// gets its own queue whose messages expire back into the main queue, so
+// RabbitMQ does the waiting instead of the workers.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func NewRetryPolicyFromEnv() (*RetryPolicy, error) {
+	policy := &RetryPolicy{
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultRetryDelay,
+		MaxDelay:   defaultMaxDelay,
+	}
+
+	var err error
+	if value := os.Getenv("WORKER_MAX_RETRIES"); len(value) != 0 {
+		policy.MaxRetries, err = strconv.Atoi(value)
+		if err != nil || policy.MaxRetries < 0 {
+			return nil, fmt.Errorf("WORKER_MAX_RETRIES should be a non-negative integer")
+		}
+	}
+	if value := os.Getenv("WORKER_RETRY_DELAY"); len(value) != 0 {
+		policy.BaseDelay, err = time.ParseDuration(value)
+		if err != nil || policy.BaseDelay <= 0 {
+			return nil, fmt.Errorf("WORKER_RETRY_DELAY should be a positive duration")
+		}
+	}
+	if value := os.Getenv("WORKER_MAX_RETRY_DELAY"); len(value) != 0 {
+		policy.MaxDelay, err = time.ParseDuration(value)
+		if err != nil || policy.MaxDelay < policy.BaseDelay {
+			return nil, fmt.Errorf("WORKER_MAX_RETRY_DELAY should be a duration not less than WORKER_RETRY_DELAY")
+		}
+	}
+
+	return policy, nil
+}
+
+// Delay returns the exponential backoff before the given retry attempt (starting from 1)
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	return delay
+}
+
+func retryQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%d", queueName, delay.Milliseconds())
+}
+
+func deadLetterQueueName(queueName string) string {
+	return queueName + ".dead"
+}
+
+func declareRetryQueues(ch *amqp.Channel, queueName string, policy *RetryPolicy) error {
+	declared := make(map[string]bool)
+	for attempt := 1; attempt <= policy.MaxRetries; attempt++ {
+		delay := policy.Delay(attempt)
+		name := retryQueueName(queueName, delay)
+		if declared[name] {
+			continue
+		}
+
+		_, err := ch.QueueDeclare(
+			name,
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queueName,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		declared[name] = true
+	}
+
+	_, err := ch.QueueDeclare(
+		deadLetterQueueName(queueName),
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+
+	return err
+}
+
+func retryCount(headers amqp.Table) int {
+	switch count := headers[retryCountHeader].(type) {
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	case int:
+		return count
+	}
+
+	return 0
+}
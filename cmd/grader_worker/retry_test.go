@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 5, BaseDelay: 10 * time.Second, MaxDelay: time.Minute}
+
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, want := range expected {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("expected attempt %d delay to be %s, got %s", i+1, want, got)
+		}
+	}
+}
+
+func TestRetryCount(t *testing.T) {
+	testCases := []struct {
+		Headers amqp.Table
+		Want    int
+	}{
+		{nil, 0},
+		{amqp.Table{}, 0},
+		{amqp.Table{retryCountHeader: int32(2)}, 2},
+		{amqp.Table{retryCountHeader: int64(3)}, 3},
+		{amqp.Table{retryCountHeader: "4"}, 0},
+	}
+
+	for _, testCase := range testCases {
+		if got := retryCount(testCase.Headers); got != testCase.Want {
+			t.Errorf("expected %d for %+v, got %d", testCase.Want, testCase.Headers, got)
+		}
+	}
+}
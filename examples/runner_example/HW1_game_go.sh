@@ -1,4 +1,4 @@
 #!/bin/bash
 
-cp /app/src/main.go /app/main.go
-go test
+cp "${GRADER_SRC_DIR:-/app/src}/main.go" ./main.go
+go test -json > "${GRADER_REPORTS_DIR:-/app/reports}/go_test.json"
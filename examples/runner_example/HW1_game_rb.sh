@@ -1,4 +1,4 @@
 #!/bin/bash
 
-cp /app/src/main.rb /app/main.rb
+cp "${GRADER_SRC_DIR:-/app/src}/main.rb" ./main.rb
 ruby main_test.rb
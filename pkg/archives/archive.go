@@ -0,0 +1,294 @@
+package archives
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	Zip   = "zip"
+	TarGz = "tar.gz"
+)
+
+// Limits are checked for the extracted content, zero values mean no limit
+type Limits struct {
+	MaxEntries int
+	MaxSize    int64
+}
+
+// DefaultLimits protect the web app and the runner from archive bombs
+var DefaultLimits = Limits{
+	MaxEntries: 1000,
+	MaxSize:    50 * 1024 * 1024,
+}
+
+// Entry is a file of the archive, Name is its clean slash separated path
+type Entry struct {
+	Name string
+	Size int64
+}
+
+// File is a file of the archive with its content
+type File struct {
+	Entry
+	Content []byte
+}
+
+type ArchiveValidationError struct {
+	Message string
+}
+
+func (e *ArchiveValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...any) error {
+	return &ArchiveValidationError{fmt.Sprintf(format, args...)}
+}
+
+// Format returns the format of an archive by the extension of its name,
+// an empty string is returned for other files
+func Format(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return Zip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return TarGz
+	default:
+		return ""
+	}
+}
+
+// Inspect validates the archive and returns its files
+func Inspect(name string, data []byte, limits Limits) ([]*Entry, error) {
+	return walk(name, data, limits, func(_ string, content io.Reader) error {
+		_, err := io.Copy(io.Discard, content)
+		return err
+	})
+}
+
+// Read validates the archive and returns its files with their contents
+func Read(name string, data []byte, limits Limits) ([]*File, error) {
+	files := []*File{}
+	_, err := walk(name, data, limits, func(entry string, content io.Reader) error {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		files = append(files, &File{Entry: Entry{Name: entry, Size: int64(len(data))}, Content: data})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// WriteZip packs the files into a zip archive
+func WriteZip(files []*File) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for _, file := range files {
+		entry, err := writer.Create(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = entry.Write(file.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Extract validates the archive and writes its files into dir keeping the
+// directory structure, it returns the written files
+func Extract(name string, data []byte, dir string, limits Limits) ([]*Entry, error) {
+	return walk(name, data, limits, func(entry string, content io.Reader) error {
+		target := filepath.Join(dir, filepath.FromSlash(entry))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(file, content)
+
+		return err
+	})
+}
+
+// walk calls fn for every file of the archive, directories are skipped and
+// links or special files are rejected
+func walk(name string, data []byte, limits Limits, fn func(entry string, content io.Reader) error) ([]*Entry, error) {
+	w := &walker{limits: limits, seen: map[string]bool{}, fn: fn}
+	var err error
+	switch Format(name) {
+	case Zip:
+		err = w.zip(data)
+	case TarGz:
+		err = w.tarGz(data)
+	default:
+		return nil, invalid("%s is not a zip or tar.gz archive", path.Base(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(w.entries) == 0 {
+		return nil, invalid("archive has no files")
+	}
+
+	return w.entries, nil
+}
+
+type walker struct {
+	limits  Limits
+	entries []*Entry
+	seen    map[string]bool
+	size    int64
+	fn      func(entry string, content io.Reader) error
+}
+
+func (w *walker) zip(data []byte) error {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return invalid("archive is corrupted: %v", err)
+	}
+
+	for _, file := range reader.File {
+		mode := file.Mode()
+		if mode.IsDir() {
+			continue
+		}
+		if !mode.IsRegular() {
+			return invalid("%s is not a regular file", file.Name)
+		}
+		content, err := file.Open()
+		if err != nil {
+			return invalid("archive is corrupted: %v", err)
+		}
+		err = w.add(file.Name, content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *walker) tarGz(data []byte) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return invalid("archive is corrupted: %v", err)
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return invalid("archive is corrupted: %v", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+			if err = w.add(header.Name, reader); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			return invalid("%s is not a regular file", header.Name)
+		}
+	}
+}
+
+// add checks the entry and passes its content limited by the remaining size to fn
+func (w *walker) add(name string, content io.Reader) error {
+	entry, err := entryName(name)
+	if err != nil {
+		return err
+	}
+	if w.seen[entry] {
+		return invalid("%s is duplicated", entry)
+	}
+	if w.limits.MaxEntries > 0 && len(w.entries) >= w.limits.MaxEntries {
+		return invalid("archive has more than %d files", w.limits.MaxEntries)
+	}
+	w.seen[entry] = true
+
+	counter := &countingReader{reader: content}
+	if w.limits.MaxSize > 0 {
+		// One byte more than allowed is read to detect oversized archives
+		counter.reader = io.LimitReader(content, w.limits.MaxSize-w.size+1)
+	}
+	if err = w.fn(entry, counter); err != nil {
+		if _, ok := err.(*ArchiveValidationError); ok {
+			return err
+		}
+		return invalid("archive is corrupted: %v", err)
+	}
+	w.size += counter.count
+	if w.limits.MaxSize > 0 && w.size > w.limits.MaxSize {
+		return invalid("extracted files are larger than %d bytes", w.limits.MaxSize)
+	}
+	w.entries = append(w.entries, &Entry{Name: entry, Size: counter.count})
+
+	return nil
+}
+
+// entryName returns the clean relative path of the entry, absolute paths and
+// paths leaving the extraction directory are rejected
+func entryName(name string) (string, error) {
+	if strings.Contains(name, "\\") || strings.ContainsRune(name, 0) {
+		return "", invalid("%q has an invalid path", name)
+	}
+	if path.IsAbs(name) {
+		return "", invalid("%s has an absolute path", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", invalid("%s leaves the archive directory", name)
+		}
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." || len(cleaned) == 0 {
+		return "", invalid("%q has an invalid path", name)
+	}
+
+	return cleaned, nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+
+	return n, err
+}
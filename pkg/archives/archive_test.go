@@ -0,0 +1,222 @@
+package archives
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testFile struct {
+	name    string
+	content string
+	link    bool
+}
+
+func zipArchive(t *testing.T, files ...testFile) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.name, Method: zip.Deflate}
+		if file.link {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+		part, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(file.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files ...testFile) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	w := tar.NewWriter(gz)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		if file.link {
+			header = &tar.Header{Name: file.name, Linkname: file.content, Typeflag: tar.TypeSymlink}
+		}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if !file.link {
+			w.Write([]byte(file.content))
+		}
+	}
+	w.Close()
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+func TestInspect(t *testing.T) {
+	type testCase struct {
+		name     string
+		archive  string
+		data     []byte
+		limits   Limits
+		expected []*Entry
+		err      string
+	}
+
+	project := []testFile{{name: "main.go", content: "package main"}, {name: "pkg/util/util.go", content: "package util"}}
+	projectEntries := []*Entry{{Name: "main.go", Size: 12}, {Name: "pkg/util/util.go", Size: 12}}
+	cases := []testCase{
+		{
+			name:     "zip",
+			archive:  "solution.zip",
+			data:     zipArchive(t, append(project, testFile{name: "pkg/"})...),
+			expected: projectEntries,
+		},
+		{
+			name:     "tar.gz",
+			archive:  "solution.tar.gz",
+			data:     tarGzArchive(t, project...),
+			expected: projectEntries,
+		},
+		{
+			name:    "unknown format",
+			archive: "solution.rar",
+			data:    []byte("rar"),
+			err:     "not a zip or tar.gz archive",
+		},
+		{
+			name:    "corrupted",
+			archive: "solution.zip",
+			data:    []byte("zip"),
+			err:     "archive is corrupted",
+		},
+		{
+			name:    "empty",
+			archive: "solution.zip",
+			data:    zipArchive(t, testFile{name: "src/"}),
+			err:     "archive has no files",
+		},
+		{
+			name:    "parent directory",
+			archive: "solution.zip",
+			data:    zipArchive(t, testFile{name: "../../etc/passwd", content: "root"}),
+			err:     "leaves the archive directory",
+		},
+		{
+			name:    "absolute path",
+			archive: "solution.tar.gz",
+			data:    tarGzArchive(t, testFile{name: "/etc/passwd", content: "root"}),
+			err:     "absolute path",
+		},
+		{
+			name:    "symlink",
+			archive: "solution.tar.gz",
+			data:    tarGzArchive(t, testFile{name: "main.go", content: "/etc/passwd", link: true}),
+			err:     "not a regular file",
+		},
+		{
+			name:    "zip symlink",
+			archive: "solution.zip",
+			data:    zipArchive(t, testFile{name: "main.go", content: "/etc/passwd", link: true}),
+			err:     "not a regular file",
+		},
+		{
+			name:    "duplicated",
+			archive: "solution.zip",
+			data:    zipArchive(t, testFile{name: "main.go"}, testFile{name: "./main.go"}),
+			err:     "main.go is duplicated",
+		},
+		{
+			name:    "too many files",
+			archive: "solution.zip",
+			data:    zipArchive(t, project...),
+			limits:  Limits{MaxEntries: 1},
+			err:     "more than 1 files",
+		},
+		{
+			name:    "too large",
+			archive: "solution.tar.gz",
+			data:    tarGzArchive(t, testFile{name: "main.go", content: strings.Repeat("a", 1024)}),
+			limits:  Limits{MaxSize: 1023},
+			err:     "larger than 1023 bytes",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := Inspect(tc.archive, tc.data, tc.limits)
+			if len(tc.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected to have error %q, got %v", tc.err, err)
+				}
+				if _, ok := err.(*ArchiveValidationError); !ok {
+					t.Fatalf("expected to have a validation error, got %T", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected not to have errors, got %v", err)
+			}
+			if !reflect.DeepEqual(entries, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, entries)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	data := zipArchive(t, testFile{name: "main.go", content: "package main"}, testFile{name: "pkg/util.go", content: "package pkg"})
+
+	entries, err := Extract("solution.zip", data, dir, DefaultLimits)
+	if err != nil {
+		t.Fatalf("expected not to have errors, got %v", err)
+	}
+	if !reflect.DeepEqual(entries, []*Entry{{Name: "main.go", Size: 12}, {Name: "pkg/util.go", Size: 11}}) {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "pkg", "util.go"))
+	if err != nil || string(content) != "package pkg" {
+		t.Fatalf("expected to extract pkg/util.go, got %q (%v)", content, err)
+	}
+
+	_, err = Extract("solution.zip", zipArchive(t, testFile{name: "../escape.go"}), dir, DefaultLimits)
+	if err == nil {
+		t.Fatalf("expected to have errors")
+	}
+	if _, err = os.Stat(filepath.Join(filepath.Dir(dir), "escape.go")); !os.IsNotExist(err) {
+		t.Fatalf("expected not to write files outside of the directory")
+	}
+}
+
+func TestRead(t *testing.T) {
+	data := tarGzArchive(t, testFile{name: "main.go", content: "package main"}, testFile{name: "pkg/util.go", content: "package pkg"})
+
+	files, err := Read("solution.tar.gz", data, DefaultLimits)
+	if err != nil {
+		t.Fatalf("expected not to have errors, got %v", err)
+	}
+	expected := []*File{
+		{Entry: Entry{Name: "main.go", Size: 12}, Content: []byte("package main")},
+		{Entry: Entry{Name: "pkg/util.go", Size: 11}, Content: []byte("package pkg")},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, files)
+	}
+
+	data, err = WriteZip(files)
+	if err != nil {
+		t.Fatalf("expected not to have errors, got %v", err)
+	}
+	if rewritten, err := Read("solution.zip", data, DefaultLimits); err != nil || !reflect.DeepEqual(rewritten, expected) {
+		t.Errorf("expected to read the written zip, got %+v (%v)", rewritten, err)
+	}
+}
@@ -1,22 +1,174 @@
 package assignments
 
+import (
+	"time"
+
+	"github.com/maxshend/grader/pkg/repo"
+	"github.com/maxshend/grader/pkg/submissions"
+)
+
+// ResourceLimits are applied to grading containers, zero values mean no limit
+// except for TimeoutSeconds which falls back to DefaultTimeoutSeconds.
+type ResourceLimits struct {
+	MemoryMB       int64   `json:"memory_mb"`
+	CPUs           float64 `json:"cpus"`
+	Pids           int64   `json:"pids"`
+	TmpfsMB        int64   `json:"tmpfs_mb"`
+	TimeoutSeconds int64   `json:"timeout_seconds"`
+}
+
+const DefaultTimeoutSeconds = 5 * 60
+
+var DefaultResourceLimits = ResourceLimits{
+	MemoryMB:       512,
+	CPUs:           1,
+	Pids:           256,
+	TmpfsMB:        64,
+	TimeoutSeconds: DefaultTimeoutSeconds,
+}
+
 type Assignment struct {
 	ID          int64
 	CreatorID   int64
+	CourseID    int64
 	Title       string
 	Description string
 	GraderURL   string
 	Container   string
 	PartID      string
-	Files       []string
+	// Files describe the submitted files or the files of the archive, any
+	// files are accepted when empty
+	Files    FileSpecs
+	MaxScore float64
+	Limits   ResourceLimits
+	// HideStderr makes stderr of submissions visible to admins only
+	HideStderr bool
+	// Published assignments are listed to students, drafts are visible to admins only
+	Published bool
+	OpensAt   *time.Time
+	DueAt     *time.Time
+	// LateDueAt closes the late window, submissions after DueAt are marked as late
+	LateDueAt *time.Time
+	// Archive assignments are submitted as a single zip or tar.gz archive of the project
+	Archive bool
+	// AllowGit lets students submit the files of a git repository at a commit
+	AllowGit bool
+	// MaxAttempts limits the submissions of every student, zero means no limit
+	MaxAttempts int
+	// CooldownSeconds is the minimal time between submissions of a student
+	CooldownSeconds int64
+	// AllowParallel accepts submissions while the previous one of the student is graded
+	AllowParallel bool
+}
+
+// CatalogEntry is a published assignment with the results of a student
+type CatalogEntry struct {
+	Assignment  *Assignment
+	CourseTitle string
+	Attempts    int
+	// LastSubmission is nil when the student hasn't submitted anything yet
+	LastSubmission *submissions.Submission
+}
+
+// Kinds of solutions uploaded by the author to validate the grader of an assignment
+const (
+	ReferenceSolution int = iota
+	BadSolution
+)
+
+// Validation is the grading run of a solution uploaded by the assignment author
+type Validation struct {
+	Kind       int
+	Submission *submissions.Submission
+}
+
+func (v *Validation) KindName() string {
+	if v.Kind == BadSolution {
+		return "Known-bad solution"
+	}
+
+	return "Reference solution"
+}
+
+// Passed reports whether the run finished as expected: the reference
+// solution has to pass and the bad one has to fail
+func (v *Validation) Passed() bool {
+	switch v.Kind {
+	case ReferenceSolution:
+		return v.Submission.Status == submissions.Success
+	case BadSolution:
+		switch v.Submission.Status {
+		case submissions.Fail, submissions.TimeLimitExceeded, submissions.MemoryLimitExceeded:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validated reports whether the reference solution passed and none of the validations failed
+func Validated(validations []*Validation) bool {
+	reference := false
+	for _, validation := range validations {
+		if !validation.Passed() {
+			return false
+		}
+		if validation.Kind == ReferenceSolution {
+			reference = true
+		}
+	}
+
+	return reference
+}
+
+// Deadline returns the last moment when submissions are accepted, nil means there is no deadline
+func (a *Assignment) Deadline() *time.Time {
+	if a.LateDueAt != nil {
+		return a.LateDueAt
+	}
+
+	return a.DueAt
+}
+
+// IsOpen reports whether students can submit the assignment at the moment
+func (a *Assignment) IsOpen(now time.Time) bool {
+	if !a.Published {
+		return false
+	}
+	if a.OpensAt != nil && now.Before(*a.OpensAt) {
+		return false
+	}
+	if deadline := a.Deadline(); deadline != nil && now.After(*deadline) {
+		return false
+	}
+
+	return true
+}
+
+func (a *Assignment) Cooldown() time.Duration {
+	return time.Duration(a.CooldownSeconds) * time.Second
+}
+
+// IsLate reports whether a submission at the moment is after the due date
+func (a *Assignment) IsLate(now time.Time) bool {
+	return a.DueAt != nil && now.After(*a.DueAt)
 }
 
 type RepositoryInterface interface {
-	GetAllByCreator(creatorID int64, limit int, offset int) ([]*Assignment, error)
+	GetAll(limit int, offset int) ([]*Assignment, error)
+	// GetAllByStaff lists assignments created by the user or of the courses the user is staff of
+	GetAllByStaff(userID int64, limit int, offset int) ([]*Assignment, error)
 	GetByID(int64) (*Assignment, error)
 	GetByIDByCreator(id int64, creatorID int64) (*Assignment, error)
+	GetByIDByStaff(id int64, userID int64) (*Assignment, error)
 	GetByTitle(string) (*Assignment, error)
 	GetByUserID(userID int64, limit, offset int) ([]*Assignment, error)
-	Create(creatorID int64, title, description, graderURL, container, partID string, files []string) (*Assignment, error)
+	Create(*Assignment) (*Assignment, error)
 	Update(*Assignment) (*Assignment, error)
+	// GetPublished lists assignments of the courses the user is a member of
+	GetPublished(userID int64, limit, offset int) ([]*CatalogEntry, error)
+	GetPublishedCount(userID int64) (int, error)
+	GetValidations(assignmentID int64) ([]*Validation, error)
+	// SetValidation replaces the submission which validates the solution of the kind
+	SetValidation(sqlExec repo.SqlQueryable, assignmentID int64, kind int, submissionID int64) error
 }
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	repo "github.com/maxshend/grader/pkg/repo"
 )
 
 // MockRepositoryInterface is a mock of RepositoryInterface interface.
@@ -34,33 +35,48 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 }
 
 // Create mocks base method.
-func (m *MockRepositoryInterface) Create(creatorID int64, title, description, graderURL, container, partID string, files []string) (*Assignment, error) {
+func (m *MockRepositoryInterface) Create(arg0 *Assignment) (*Assignment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", creatorID, title, description, graderURL, container, partID, files)
+	ret := m.ctrl.Call(m, "Create", arg0)
 	ret0, _ := ret[0].(*Assignment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryInterfaceMockRecorder) Create(creatorID, title, description, graderURL, container, partID, files interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) Create(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), creatorID, title, description, graderURL, container, partID, files)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), arg0)
 }
 
-// GetAllByCreator mocks base method.
-func (m *MockRepositoryInterface) GetAllByCreator(creatorID int64, limit, offset int) ([]*Assignment, error) {
+// GetAll mocks base method.
+func (m *MockRepositoryInterface) GetAll(limit, offset int) ([]*Assignment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByCreator", creatorID, limit, offset)
+	ret := m.ctrl.Call(m, "GetAll", limit, offset)
 	ret0, _ := ret[0].([]*Assignment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllByCreator indicates an expected call of GetAllByCreator.
-func (mr *MockRepositoryInterfaceMockRecorder) GetAllByCreator(creatorID, limit, offset interface{}) *gomock.Call {
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAll(limit, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByCreator", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAllByCreator), creatorID, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAll), limit, offset)
+}
+
+// GetAllByStaff mocks base method.
+func (m *MockRepositoryInterface) GetAllByStaff(userID int64, limit, offset int) ([]*Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByStaff", userID, limit, offset)
+	ret0, _ := ret[0].([]*Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByStaff indicates an expected call of GetAllByStaff.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAllByStaff(userID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByStaff", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAllByStaff), userID, limit, offset)
 }
 
 // GetByID mocks base method.
@@ -93,6 +109,21 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetByIDByCreator(id, creatorID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDByCreator", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByIDByCreator), id, creatorID)
 }
 
+// GetByIDByStaff mocks base method.
+func (m *MockRepositoryInterface) GetByIDByStaff(id, userID int64) (*Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDByStaff", id, userID)
+	ret0, _ := ret[0].(*Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDByStaff indicates an expected call of GetByIDByStaff.
+func (mr *MockRepositoryInterfaceMockRecorder) GetByIDByStaff(id, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDByStaff", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByIDByStaff), id, userID)
+}
+
 // GetByTitle mocks base method.
 func (m *MockRepositoryInterface) GetByTitle(arg0 string) (*Assignment, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +154,65 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetByUserID(userID, limit, offset
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByUserID), userID, limit, offset)
 }
 
+// GetPublished mocks base method.
+func (m *MockRepositoryInterface) GetPublished(userID int64, limit, offset int) ([]*CatalogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublished", userID, limit, offset)
+	ret0, _ := ret[0].([]*CatalogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublished indicates an expected call of GetPublished.
+func (mr *MockRepositoryInterfaceMockRecorder) GetPublished(userID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublished", reflect.TypeOf((*MockRepositoryInterface)(nil).GetPublished), userID, limit, offset)
+}
+
+// GetPublishedCount mocks base method.
+func (m *MockRepositoryInterface) GetPublishedCount(userID int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublishedCount", userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublishedCount indicates an expected call of GetPublishedCount.
+func (mr *MockRepositoryInterfaceMockRecorder) GetPublishedCount(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublishedCount", reflect.TypeOf((*MockRepositoryInterface)(nil).GetPublishedCount), userID)
+}
+
+// GetValidations mocks base method.
+func (m *MockRepositoryInterface) GetValidations(assignmentID int64) ([]*Validation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValidations", assignmentID)
+	ret0, _ := ret[0].([]*Validation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValidations indicates an expected call of GetValidations.
+func (mr *MockRepositoryInterfaceMockRecorder) GetValidations(assignmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValidations", reflect.TypeOf((*MockRepositoryInterface)(nil).GetValidations), assignmentID)
+}
+
+// SetValidation mocks base method.
+func (m *MockRepositoryInterface) SetValidation(sqlExec repo.SqlQueryable, assignmentID int64, kind int, submissionID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetValidation", sqlExec, assignmentID, kind, submissionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetValidation indicates an expected call of SetValidation.
+func (mr *MockRepositoryInterfaceMockRecorder) SetValidation(sqlExec, assignmentID, kind, submissionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetValidation", reflect.TypeOf((*MockRepositoryInterface)(nil).SetValidation), sqlExec, assignmentID, kind, submissionID)
+}
+
 // Update mocks base method.
 func (m *MockRepositoryInterface) Update(arg0 *Assignment) (*Assignment, error) {
 	m.ctrl.T.Helper()
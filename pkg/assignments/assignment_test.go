@@ -0,0 +1,132 @@
+package assignments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxshend/grader/pkg/submissions"
+)
+
+func TestAssignmentIsOpen(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	type testCase struct {
+		Title      string
+		Assignment *Assignment
+		Open       bool
+		Late       bool
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "draft",
+			Assignment: &Assignment{},
+		},
+		{
+			Title:      "without dates",
+			Assignment: &Assignment{Published: true},
+			Open:       true,
+		},
+		{
+			Title:      "not opened yet",
+			Assignment: &Assignment{Published: true, OpensAt: &after},
+		},
+		{
+			Title:      "before due date",
+			Assignment: &Assignment{Published: true, OpensAt: &before, DueAt: &after},
+			Open:       true,
+		},
+		{
+			Title:      "after due date",
+			Assignment: &Assignment{Published: true, DueAt: &before},
+			Late:       true,
+		},
+		{
+			Title:      "late window",
+			Assignment: &Assignment{Published: true, DueAt: &before, LateDueAt: &after},
+			Open:       true,
+			Late:       true,
+		},
+		{
+			Title:      "after late window",
+			Assignment: &Assignment{Published: true, DueAt: &before, LateDueAt: &before},
+			Late:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			if got := testCase.Assignment.IsOpen(now); got != testCase.Open {
+				t.Errorf("expected open to be %v, got %v", testCase.Open, got)
+			}
+			if got := testCase.Assignment.IsLate(now); got != testCase.Late {
+				t.Errorf("expected late to be %v, got %v", testCase.Late, got)
+			}
+		})
+	}
+}
+
+func TestValidated(t *testing.T) {
+	validation := func(kind int, status int) *Validation {
+		return &Validation{Kind: kind, Submission: &submissions.Submission{Status: status}}
+	}
+
+	type testCase struct {
+		Title       string
+		Validations []*Validation
+		Validated   bool
+	}
+
+	testCases := []*testCase{
+		{Title: "without solutions"},
+		{
+			Title:       "reference passed",
+			Validations: []*Validation{validation(ReferenceSolution, submissions.Success)},
+			Validated:   true,
+		},
+		{
+			Title:       "reference in progress",
+			Validations: []*Validation{validation(ReferenceSolution, submissions.InProgress)},
+		},
+		{
+			Title:       "reference failed",
+			Validations: []*Validation{validation(ReferenceSolution, submissions.Fail)},
+		},
+		{
+			Title: "bad solution failed",
+			Validations: []*Validation{
+				validation(ReferenceSolution, submissions.Success),
+				validation(BadSolution, submissions.TimeLimitExceeded),
+			},
+			Validated: true,
+		},
+		{
+			Title: "bad solution passed",
+			Validations: []*Validation{
+				validation(ReferenceSolution, submissions.Success),
+				validation(BadSolution, submissions.Success),
+			},
+		},
+		{
+			Title: "bad solution grading error",
+			Validations: []*Validation{
+				validation(ReferenceSolution, submissions.Success),
+				validation(BadSolution, submissions.GradingError),
+			},
+		},
+		{
+			Title:       "only bad solution",
+			Validations: []*Validation{validation(BadSolution, submissions.Fail)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			if got := Validated(testCase.Validations); got != testCase.Validated {
+				t.Errorf("expected validated to be %v, got %v", testCase.Validated, got)
+			}
+		})
+	}
+}
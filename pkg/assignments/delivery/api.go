@@ -0,0 +1,581 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/assignments"
+	"github.com/maxshend/grader/pkg/assignments/services"
+	coursesServices "github.com/maxshend/grader/pkg/courses/services"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/submissions"
+	submissionsServices "github.com/maxshend/grader/pkg/submissions/services"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+const maxSubmissionSize = 5 * 1024 * 1024
+
+// AssignmentsApiHandler serves the JSON API of assignments and their submissions under /api/v1
+type AssignmentsApiHandler struct {
+	Service            services.AssignmentsServiceInterface
+	SubmissionsService submissionsServices.SubmissionsServiceInterface
+	CoursesService     coursesServices.CoursesServiceInterface
+	SessionManager     sessions.HttpSessionManager
+}
+
+type assignmentJSON struct {
+	ID              int64                 `json:"id"`
+	CourseID        int64                 `json:"course_id"`
+	Title           string                `json:"title"`
+	Description     string                `json:"description,omitempty"`
+	Files           assignments.FileSpecs `json:"files,omitempty"`
+	Archive         bool                  `json:"archive"`
+	AllowGit        bool                  `json:"allow_git"`
+	AllowParallel   bool                  `json:"allow_parallel"`
+	MaxAttempts     int                   `json:"max_attempts"`
+	CooldownSeconds int64                 `json:"cooldown_seconds"`
+	MaxScore        float64               `json:"max_score"`
+	Published       bool                  `json:"published"`
+	Open            bool                  `json:"open"`
+	OpensAt         *time.Time            `json:"opens_at"`
+	DueAt           *time.Time            `json:"due_at"`
+	LateDueAt       *time.Time            `json:"late_due_at"`
+	// Grading settings are shown to the course staff only
+	GraderURL  string                      `json:"grader_url,omitempty"`
+	Container  string                      `json:"container,omitempty"`
+	PartID     string                      `json:"part_id,omitempty"`
+	Limits     *assignments.ResourceLimits `json:"limits,omitempty"`
+	HideStderr bool                        `json:"hide_stderr,omitempty"`
+	// Warnings describe problems which didn't prevent saving the assignment
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type catalogEntryJSON struct {
+	Assignment     *assignmentJSON `json:"assignment"`
+	CourseTitle    string          `json:"course_title"`
+	Attempts       int             `json:"attempts"`
+	LastSubmission *submissionJSON `json:"last_submission"`
+}
+
+type submissionJSON struct {
+	ID            int64                     `json:"id"`
+	AssignmentID  int64                     `json:"assignment_id"`
+	UserID        int64                     `json:"user_id,omitempty"`
+	Username      string                    `json:"username,omitempty"`
+	Status        int                       `json:"status"`
+	StatusName    string                    `json:"status_name"`
+	Finished      bool                      `json:"finished"`
+	Score         float64                   `json:"score"`
+	MaxScore      float64                   `json:"max_score"`
+	Late          bool                      `json:"late"`
+	RepositoryURL string                    `json:"repository_url,omitempty"`
+	CommitSHA     string                    `json:"commit_sha,omitempty"`
+	Details       string                    `json:"details,omitempty"`
+	ExitCode      *int                      `json:"exit_code,omitempty"`
+	Duration      float64                   `json:"duration,omitempty"`
+	Stdout        string                    `json:"stdout,omitempty"`
+	Stderr        string                    `json:"stderr,omitempty"`
+	TestResults   []*submissions.TestResult `json:"test_results,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+}
+
+type validationJSON struct {
+	Kind       string          `json:"kind"`
+	Passed     bool            `json:"passed"`
+	Submission *submissionJSON `json:"submission"`
+}
+
+type validationsJSON struct {
+	// Validated assignments can be published
+	Validated bool              `json:"validated"`
+	Data      []*validationJSON `json:"data"`
+	// Warnings describe problems which didn't prevent saving the solutions
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type assignmentRequest struct {
+	CourseID        int64                       `json:"course_id"`
+	Title           string                      `json:"title"`
+	Description     string                      `json:"description"`
+	GraderURL       string                      `json:"grader_url"`
+	Container       string                      `json:"container"`
+	PartID          string                      `json:"part_id"`
+	Files           assignments.FileSpecs       `json:"files"`
+	Archive         bool                        `json:"archive"`
+	AllowGit        bool                        `json:"allow_git"`
+	AllowParallel   bool                        `json:"allow_parallel"`
+	MaxAttempts     int                         `json:"max_attempts"`
+	CooldownSeconds int64                       `json:"cooldown_seconds"`
+	MaxScore        float64                     `json:"max_score"`
+	Limits          *assignments.ResourceLimits `json:"limits"`
+	HideStderr      bool                        `json:"hide_stderr"`
+	Published       bool                        `json:"published"`
+	OpensAt         *time.Time                  `json:"opens_at"`
+	DueAt           *time.Time                  `json:"due_at"`
+	LateDueAt       *time.Time                  `json:"late_due_at"`
+}
+
+// gitSubmissionRequest submits the files of the repository at the ref, the
+// default branch is used when the ref is empty
+type gitSubmissionRequest struct {
+	RepositoryURL string `json:"repository_url"`
+	Ref           string `json:"ref"`
+}
+
+func NewAssignmentsApiHandler(
+	service services.AssignmentsServiceInterface,
+	sessionManager sessions.HttpSessionManager,
+	submissionsService submissionsServices.SubmissionsServiceInterface,
+	coursesService coursesServices.CoursesServiceInterface,
+) *AssignmentsApiHandler {
+	return &AssignmentsApiHandler{
+		Service:            service,
+		SessionManager:     sessionManager,
+		SubmissionsService: submissionsService,
+		CoursesService:     coursesService,
+	}
+}
+
+// GetAll lists published assignments of the courses of the current user
+func (h AssignmentsApiHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	entries, paginationData, err := h.Service.GetCatalogPage(currentUser, utils.GetPageNumber(r))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	result := make([]*catalogEntryJSON, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, &catalogEntryJSON{
+			Assignment:     newAssignmentJSON(entry.Assignment, now, false),
+			CourseTitle:    entry.CourseTitle,
+			Attempts:       entry.Attempts,
+			LastSubmission: newSubmissionJSON(entry.LastSubmission, false),
+		})
+	}
+
+	utils.RenderJSON(w, http.StatusOK, &utils.APIList{Data: result, Pagination: paginationData})
+}
+
+func (h AssignmentsApiHandler) Show(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	assignment, ok := publishedAssignment(h.Service, w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	utils.RenderJSON(w, http.StatusOK, newAssignmentJSON(assignment, time.Now(), isCourseStaff(r, currentUser)))
+}
+
+func (h AssignmentsApiHandler) Create(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	request := &assignmentRequest{}
+	if err = json.NewDecoder(r.Body).Decode(request); err != nil {
+		utils.RenderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	assignment := &assignments.Assignment{CreatorID: currentUser.ID}
+	request.apply(assignment)
+
+	var warnings []string
+	err = checkCourse(h.CoursesService, currentUser, assignment)
+	if err == nil {
+		assignment, err = h.Service.Create(currentUser, assignment, nil)
+		warnings, err = serviceWarnings(err)
+	}
+	if err != nil {
+		renderAPIServiceError(w, r, err)
+		return
+	}
+
+	result := newAssignmentJSON(assignment, time.Now(), true)
+	result.Warnings = warnings
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/assignments/%d", assignment.ID))
+	utils.RenderJSON(w, http.StatusCreated, result)
+}
+
+// Update replaces the settings of the assignment, only its creator or an admin can update it
+func (h AssignmentsApiHandler) Update(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	assignment, err := h.Service.GetByIDByCreator(assignmentID(mux.Vars(r)["id"]), currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if assignment == nil {
+		utils.RenderNotFound(w, r)
+		return
+	}
+
+	request := &assignmentRequest{}
+	if err = json.NewDecoder(r.Body).Decode(request); err != nil {
+		utils.RenderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	request.apply(assignment)
+
+	var warnings []string
+	err = checkCourse(h.CoursesService, currentUser, assignment)
+	if err == nil {
+		assignment, err = h.Service.Update(currentUser, assignment, nil)
+		warnings, err = serviceWarnings(err)
+	}
+	if err != nil {
+		renderAPIServiceError(w, r, err)
+		return
+	}
+
+	result := newAssignmentJSON(assignment, time.Now(), true)
+	result.Warnings = warnings
+	utils.RenderJSON(w, http.StatusOK, result)
+}
+
+// Validations lists grading runs of the solutions uploaded by the assignment author
+func (h AssignmentsApiHandler) Validations(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	assignment, err := h.Service.GetByIDByCreator(assignmentID(mux.Vars(r)["id"]), currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if assignment == nil {
+		utils.RenderNotFound(w, r)
+		return
+	}
+
+	h.renderValidations(w, r, assignment, http.StatusOK, nil)
+}
+
+// UploadSolutions expects a multipart form with reference_files and
+// optionally bad_files parts and queues their validation runs
+func (h AssignmentsApiHandler) UploadSolutions(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 2*maxSubmissionSize)
+
+	assignment, err := h.Service.GetByIDByCreator(assignmentID(mux.Vars(r)["id"]), currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if assignment == nil {
+		utils.RenderNotFound(w, r)
+		return
+	}
+
+	solutions, closeSolutions, err := solutionFiles(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer closeSolutions()
+	if len(solutions.Reference) == 0 && len(solutions.Bad) == 0 {
+		utils.RenderAPIError(w, http.StatusUnprocessableEntity, "no solution files were uploaded")
+		return
+	}
+
+	assignment, err = h.Service.Update(currentUser, assignment, solutions)
+	warnings, err := serviceWarnings(err)
+	if err != nil {
+		renderAPIServiceError(w, r, err)
+		return
+	}
+
+	h.renderValidations(w, r, assignment, http.StatusAccepted, warnings)
+}
+
+func (h AssignmentsApiHandler) renderValidations(
+	w http.ResponseWriter,
+	r *http.Request,
+	assignment *assignments.Assignment,
+	status int,
+	warnings []string,
+) {
+	validations, err := h.Service.GetValidations(assignment.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	result := &validationsJSON{
+		Validated: assignments.Validated(validations),
+		Data:      make([]*validationJSON, 0, len(validations)),
+		Warnings:  warnings,
+	}
+	for _, validation := range validations {
+		kind := "reference"
+		if validation.Kind == assignments.BadSolution {
+			kind = "bad"
+		}
+		result.Data = append(result.Data, &validationJSON{
+			Kind:       kind,
+			Passed:     validation.Passed(),
+			Submission: newSubmissionJSON(validation.Submission, true),
+		})
+	}
+
+	utils.RenderJSON(w, status, result)
+}
+
+// Submissions lists submissions of the current user for the assignment
+func (h AssignmentsApiHandler) Submissions(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	assignment, ok := publishedAssignment(h.Service, w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	submissionsList, paginationData, err := h.SubmissionsService.GetByUserAssignment(
+		assignment.ID,
+		currentUser.ID,
+		utils.GetPageNumber(r),
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	result := make([]*submissionJSON, 0, len(submissionsList))
+	for _, submission := range submissionsList {
+		result = append(result, newSubmissionJSON(submission, false))
+	}
+
+	utils.RenderJSON(w, http.StatusOK, &utils.APIList{Data: result, Pagination: paginationData})
+}
+
+// Submit expects a multipart form with a part for every file of the assignment
+// or a JSON body with the git repository of the solution. Requests repeated
+// with the same Idempotency-Key header return the submission of the first one,
+// earlier submissions with the key or the same files are returned with 200 instead of 201
+func (h AssignmentsApiHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionSize)
+
+	assignment, ok := publishedAssignment(h.Service, w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	var submission *submissions.Submission
+	var reused bool
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		request := &gitSubmissionRequest{}
+		if err = json.NewDecoder(r.Body).Decode(request); err != nil {
+			utils.RenderAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		submission, reused, err = h.Service.SubmitGit(currentUser, assignment, request.RepositoryURL, request.Ref, idempotencyKey)
+	} else {
+		files, closeFiles, filesErr := submittedFiles(r)
+		if filesErr != nil {
+			utils.RenderAPIError(w, http.StatusUnprocessableEntity, filesErr.Error())
+			return
+		}
+		defer closeFiles()
+
+		submission, reused, err = h.Service.Submit(currentUser, assignment, files, idempotencyKey)
+	}
+	if err != nil {
+		renderAPIServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set(
+		"Location",
+		fmt.Sprintf("/api/v1/assignments/%d/submissions/%d", assignment.ID, submission.ID),
+	)
+	statusCode := http.StatusCreated
+	if reused {
+		statusCode = http.StatusOK
+	}
+	utils.RenderJSON(w, statusCode, newSubmissionJSON(submission, false))
+}
+
+// ShowSubmission returns the current state of the submission, clients poll it until it is finished
+func (h AssignmentsApiHandler) ShowSubmission(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	assignment, submission, ok := personalSubmission(h.Service, h.SubmissionsService, w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	submission.TestResults, err = h.SubmissionsService.GetTestResults(submission.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	utils.RenderJSON(
+		w,
+		http.StatusOK,
+		newSubmissionJSON(submission, !assignment.HideStderr || isCourseStaff(r, currentUser)),
+	)
+}
+
+// apply copies the request into the assignment, limits fall back to the defaults when omitted
+func (request *assignmentRequest) apply(assignment *assignments.Assignment) {
+	limits := assignments.DefaultResourceLimits
+	if request.Limits != nil {
+		limits = *request.Limits
+	}
+
+	assignment.CourseID = request.CourseID
+	assignment.Title = request.Title
+	assignment.Description = request.Description
+	assignment.GraderURL = request.GraderURL
+	assignment.Container = request.Container
+	assignment.PartID = request.PartID
+	assignment.Files = request.Files
+	assignment.Archive = request.Archive
+	assignment.AllowGit = request.AllowGit
+	assignment.AllowParallel = request.AllowParallel
+	assignment.MaxAttempts = request.MaxAttempts
+	assignment.CooldownSeconds = request.CooldownSeconds
+	assignment.MaxScore = request.MaxScore
+	assignment.Limits = limits
+	assignment.HideStderr = request.HideStderr
+	assignment.Published = request.Published
+	assignment.OpensAt = request.OpensAt
+	assignment.DueAt = request.DueAt
+	assignment.LateDueAt = request.LateDueAt
+}
+
+// serviceWarnings turns errors which didn't revert the changes into warnings of the response
+func serviceWarnings(err error) ([]string, error) {
+	if _, ok := err.(*services.SolutionsError); ok {
+		return []string{err.Error()}, nil
+	}
+
+	return nil, err
+}
+
+func renderAPIServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if limitErr, ok := err.(*services.SubmissionLimitError); ok {
+		if limitErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(limitErr.RetryAfter.Seconds())))
+		}
+		utils.RenderAPIError(w, http.StatusTooManyRequests, limitErr.Message)
+		return
+	}
+	if isValidationError(err) {
+		utils.RenderAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	utils.RenderInternalError(w, r, err)
+}
+
+func newAssignmentJSON(assignment *assignments.Assignment, now time.Time, staff bool) *assignmentJSON {
+	result := &assignmentJSON{
+		ID:              assignment.ID,
+		CourseID:        assignment.CourseID,
+		Title:           assignment.Title,
+		Description:     assignment.Description,
+		Files:           assignment.Files,
+		Archive:         assignment.Archive,
+		AllowGit:        assignment.AllowGit,
+		AllowParallel:   assignment.AllowParallel,
+		MaxAttempts:     assignment.MaxAttempts,
+		CooldownSeconds: assignment.CooldownSeconds,
+		MaxScore:        assignment.MaxScore,
+		Published:       assignment.Published,
+		Open:            assignment.IsOpen(now),
+		OpensAt:         assignment.OpensAt,
+		DueAt:           assignment.DueAt,
+		LateDueAt:       assignment.LateDueAt,
+	}
+	if staff {
+		limits := assignment.Limits
+		result.GraderURL = assignment.GraderURL
+		result.Container = assignment.Container
+		result.PartID = assignment.PartID
+		result.Limits = &limits
+		result.HideStderr = assignment.HideStderr
+	}
+
+	return result
+}
+
+func newSubmissionJSON(submission *submissions.Submission, showStderr bool) *submissionJSON {
+	if submission == nil {
+		return nil
+	}
+
+	result := &submissionJSON{
+		ID:            submission.ID,
+		AssignmentID:  submission.AssignmentID,
+		UserID:        submission.UserID,
+		Username:      submission.Username,
+		Status:        submission.Status,
+		StatusName:    utils.SubmissionStatus(submission.Status),
+		Finished:      submission.Status != submissions.InProgress,
+		Score:         submission.Score,
+		MaxScore:      submission.MaxScore,
+		Late:          submission.Late,
+		RepositoryURL: submission.RepositoryURL,
+		CommitSHA:     submission.CommitSHA,
+		Details:       submission.Details,
+		ExitCode:      submission.ExitCode,
+		Duration:      submission.Duration,
+		Stdout:        submission.Stdout,
+		TestResults:   submission.TestResults,
+		CreatedAt:     submission.CreatedAt,
+	}
+	if showStderr {
+		result.Stderr = submission.Stderr
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/assignments"
+	"github.com/maxshend/grader/pkg/assignments/services"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/submissions"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+func TestAssignmentsApiUploadSolutions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := services.NewMockAssignmentsServiceInterface(ctrl)
+	sessionManager := sessions.NewMockHttpSessionManager(ctrl)
+	handler := AssignmentsApiHandler{Service: service, SessionManager: sessionManager}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/assignments/{id}/validations", handler.UploadSolutions).Methods("POST")
+
+	user := &users.User{ID: 1}
+	assignment := &assignments.Assignment{ID: 2}
+	validations := []*assignments.Validation{
+		{Kind: assignments.ReferenceSolution, Submission: &submissions.Submission{ID: 3}},
+	}
+
+	type testCase struct {
+		Title              string
+		UpdateErr          error
+		ExpectedStatusCode int
+		ExpectedWarnings   []string
+	}
+
+	testCases := []*testCase{
+		{
+			Title:              "queued",
+			ExpectedStatusCode: http.StatusAccepted,
+		},
+		{
+			Title:              "solutions error",
+			UpdateErr:          &services.SolutionsError{Err: errors.New("runner is unavailable")},
+			ExpectedStatusCode: http.StatusAccepted,
+			ExpectedWarnings:   []string{(&services.SolutionsError{}).Error()},
+		},
+		{
+			Title:              "error",
+			UpdateErr:          errors.New("database is unavailable"),
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			sessionManager.EXPECT().CurrentUser(gomock.Any()).Return(user, nil)
+			service.EXPECT().GetByIDByCreator(assignment.ID, user).Return(assignment, nil)
+			service.EXPECT().Update(user, assignment, gomock.Any()).Return(assignment, testCase.UpdateErr)
+			if testCase.ExpectedStatusCode == http.StatusAccepted {
+				service.EXPECT().GetValidations(assignment.ID).Return(validations, nil)
+			}
+
+			body := &bytes.Buffer{}
+			form := multipart.NewWriter(body)
+			part, err := form.CreateFormFile("reference_files", "main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write([]byte("package main"))
+			form.Close()
+
+			req := httptest.NewRequest("POST", "/api/v1/assignments/2/validations", body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != testCase.ExpectedStatusCode {
+				t.Fatalf("expected status %d, got %d: %s", testCase.ExpectedStatusCode, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusAccepted {
+				return
+			}
+
+			result := &validationsJSON{}
+			if err = json.NewDecoder(w.Body).Decode(result); err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Data) != len(validations) {
+				t.Errorf("expected %d validations, got %d", len(validations), len(result.Data))
+			}
+			if len(result.Warnings) != len(testCase.ExpectedWarnings) ||
+				len(result.Warnings) > 0 && result.Warnings[0] != testCase.ExpectedWarnings[0] {
+				t.Errorf("expected warnings %v, got %v", testCase.ExpectedWarnings, result.Warnings)
+			}
+		})
+	}
+}
@@ -1,44 +1,100 @@
 package delivery
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"log"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/maxshend/grader/pkg/assignments"
 	"github.com/maxshend/grader/pkg/assignments/services"
+	"github.com/maxshend/grader/pkg/courses"
+	coursesServices "github.com/maxshend/grader/pkg/courses/services"
 	"github.com/maxshend/grader/pkg/sessions"
 	"github.com/maxshend/grader/pkg/submissions"
 	submissionsServices "github.com/maxshend/grader/pkg/submissions/services"
+	"github.com/maxshend/grader/pkg/users"
 	"github.com/maxshend/grader/pkg/utils"
 )
 
+const (
+	eventsHeartbeat     = 15 * time.Second
+	dateTimeLocalLayout = "2006-01-02T15:04"
+	// Fields of the form submitting a git repository instead of files
+	repositoryURLField = "repository_url"
+	refField           = "ref"
+	// idempotencyKeyField keeps a resent form from creating another submission
+	idempotencyKeyField = "idempotency_key"
+)
+
+var eventDataReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
 type AssignmentsHttpHandler struct {
 	Service            services.AssignmentsServiceInterface
 	SubmissionsService submissionsServices.SubmissionsServiceInterface
+	CoursesService     coursesServices.CoursesServiceInterface
 	SessionManager     sessions.HttpSessionManager
 	Views              map[string]*utils.View
 }
 
 type newSubmissionData struct {
 	Assignment *assignments.Assignment
+	Open       bool
 	Errors     []string
+	// FileErrors are shown next to the inputs of the file specs
+	FileErrors map[string][]string
+	// RepositoryURL and Ref keep the values of the git form
+	RepositoryURL string
+	Ref           string
+	// AttemptsLeft is shown for assignments with max attempts
+	AttemptsLeft int
+	// IdempotencyKey is generated for every rendered form
+	IdempotencyKey string
+}
+
+// setErrors groups errors of the files by their specs, other errors are shown above the form
+func (d *newSubmissionData) setErrors(err error) {
+	filesErr, ok := err.(*services.FilesValidationError)
+	if !ok {
+		d.Errors = []string{err.Error()}
+		return
+	}
+
+	d.FileErrors = map[string][]string{}
+	for _, fileErr := range filesErr.Errors {
+		if len(fileErr.Pattern) == 0 {
+			d.Errors = append(d.Errors, fileErr.Error())
+			continue
+		}
+		d.FileErrors[fileErr.Pattern] = append(d.FileErrors[fileErr.Pattern], fileErr.Error())
+	}
 }
 
 type newAssignmentnData struct {
-	Assignment *assignments.Assignment
-	Files      string
-	Errors     []string
-	Action     string
+	Assignment  *assignments.Assignment
+	Courses     []*courses.Course
+	Validations []*assignments.Validation
+	Files       string
+	Errors      []string
+	Action      string
 }
 
 func NewAssignmentsHttpHandler(
 	service services.AssignmentsServiceInterface,
 	sessionManager sessions.HttpSessionManager,
 	ubmissionsService submissionsServices.SubmissionsServiceInterface,
+	coursesService coursesServices.CoursesServiceInterface,
 	templatesFS fs.FS,
 ) (*AssignmentsHttpHandler, error) {
 	views := make(map[string]*utils.View)
@@ -69,12 +125,17 @@ func NewAssignmentsHttpHandler(
 	if err != nil {
 		return nil, err
 	}
+	views["ShowSubmission"], err = utils.NewView(templatesFS, "templates/assignments/submission.gohtml")
+	if err != nil {
+		return nil, err
+	}
 
 	return &AssignmentsHttpHandler{
 		Service:            service,
 		Views:              views,
 		SessionManager:     sessionManager,
 		SubmissionsService: ubmissionsService,
+		CoursesService:     coursesService,
 	}, nil
 }
 
@@ -106,7 +167,7 @@ func (h AssignmentsHttpHandler) PersonalAssignments(w http.ResponseWriter, r *ht
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
-	result, err := h.Service.GetByUserID(currentUser.ID)
+	result, err := h.Service.GetCatalog(currentUser)
 	if err != nil {
 		utils.RenderInternalError(w, r, err)
 		return
@@ -114,7 +175,7 @@ func (h AssignmentsHttpHandler) PersonalAssignments(w http.ResponseWriter, r *ht
 
 	err = h.Views["GetAllPersonal"].RenderView(
 		w,
-		&struct{ Assignments []*assignments.Assignment }{result},
+		&struct{ Entries []*assignments.CatalogEntry }{result},
 		currentUser,
 	)
 	if err != nil {
@@ -129,23 +190,15 @@ func (h AssignmentsHttpHandler) NewSubmission(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	params := mux.Vars(r)
-
-	assignment, err := h.Service.GetByID(assignmentID(params["id"]))
-	if err != nil {
-		utils.RenderInternalError(w, r, err)
-		return
-	}
-	if assignment == nil {
-		http.NotFound(w, r)
+	assignment, ok := publishedAssignment(h.Service, w, r, currentUser)
+	if !ok {
 		return
 	}
 
-	err = h.Views["NewSubmission"].RenderView(
-		w,
-		&newSubmissionData{Assignment: assignment},
-		currentUser,
-	)
+	data, err := h.submissionForm(currentUser, assignment)
+	if err == nil {
+		err = h.Views["NewSubmission"].RenderView(w, data, currentUser)
+	}
 	if err != nil {
 		utils.RenderInternalError(w, r, err)
 	}
@@ -159,43 +212,40 @@ func (h AssignmentsHttpHandler) CreateSubmission(w http.ResponseWriter, r *http.
 	}
 
 	r.Body = http.MaxBytesReader(w, r.Body, 5*1024*1024)
-	params := mux.Vars(r)
 
-	assignment, err := h.Service.GetByID(assignmentID(params["id"]))
-	if err != nil {
-		utils.RenderInternalError(w, r, err)
+	assignment, ok := publishedAssignment(h.Service, w, r, currentUser)
+	if !ok {
 		return
 	}
-	if assignment == nil {
-		http.NotFound(w, r)
+
+	files, closeFiles, err := submittedFiles(r)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
 		return
 	}
+	defer closeFiles()
 
-	files := []*services.SubmissionFile{}
-	for _, filename := range assignment.Files {
-		uploadData, header, err := r.FormFile(filename)
-		if err != nil {
+	repositoryURL, ref := r.FormValue(repositoryURLField), r.FormValue(refField)
+	idempotencyKey := r.FormValue(idempotencyKeyField)
+	if len(repositoryURL) > 0 {
+		_, _, err = h.Service.SubmitGit(currentUser, assignment, repositoryURL, ref, idempotencyKey)
+	} else {
+		_, _, err = h.Service.Submit(currentUser, assignment, files, idempotencyKey)
+	}
+	if err != nil {
+		if !isValidationError(err) {
 			utils.RenderInternalError(w, r, err)
 			return
 		}
-		defer uploadData.Close()
 
-		files = append(files, &services.SubmissionFile{Content: uploadData, Name: header.Filename})
-	}
-
-	_, err = h.Service.Submit(currentUser, assignment, files)
-	if err != nil {
-		if _, ok := err.(*services.AssignmentValidationError); ok {
-			err = h.Views["NewSubmission"].RenderView(
-				w,
-				newSubmissionData{assignment, []string{err.Error()}},
-				currentUser,
-			)
-			if err != nil {
-				utils.RenderInternalError(w, r, err)
-			}
-		} else {
-			utils.RenderInternalError(w, r, err)
+		data, formErr := h.submissionForm(currentUser, assignment)
+		if formErr == nil {
+			data.RepositoryURL, data.Ref = repositoryURL, ref
+			data.setErrors(err)
+			formErr = h.Views["NewSubmission"].RenderView(w, data, currentUser)
+		}
+		if formErr != nil {
+			utils.RenderInternalError(w, r, formErr)
 		}
 
 		return
@@ -211,14 +261,8 @@ func (h AssignmentsHttpHandler) ShowPersonal(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	params := mux.Vars(r)
-	assignment, err := h.Service.GetByID(assignmentID(params["id"]))
-	if err != nil {
-		utils.RenderInternalError(w, r, err)
-		return
-	}
-	if assignment == nil {
-		http.NotFound(w, r)
+	assignment, ok := publishedAssignment(h.Service, w, r, currentUser)
+	if !ok {
 		return
 	}
 
@@ -247,19 +291,43 @@ func (h AssignmentsHttpHandler) ShowPersonal(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-func (h AssignmentsHttpHandler) New(w http.ResponseWriter, r *http.Request) {
+func (h AssignmentsHttpHandler) ShowSubmission(w http.ResponseWriter, r *http.Request) {
 	currentUser, err := h.SessionManager.CurrentUser(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
-	err = h.Views["AssignmentForm"].RenderView(
+	assignment, submission, ok := personalSubmission(h.Service, h.SubmissionsService, w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	submission.TestResults, err = h.SubmissionsService.GetTestResults(submission.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	submission.Attachments, err = h.SubmissionsService.GetAttachments(submission.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	runs, err := h.SubmissionsService.GetRuns(submission.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	err = h.Views["ShowSubmission"].RenderView(
 		w,
-		newAssignmentnData{
-			Assignment: &assignments.Assignment{},
-			Action:     "create",
-		},
+		&struct {
+			Assignment *assignments.Assignment
+			Submission *submissions.Submission
+			Runs       []*submissions.Run
+			ShowStderr bool
+		}{assignment, submission, runs, !assignment.HideStderr || isCourseStaff(r, currentUser)},
 		currentUser,
 	)
 	if err != nil {
@@ -267,6 +335,342 @@ func (h AssignmentsHttpHandler) New(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DownloadAttachment sends a file of the submission to its author or the course staff
+func (h AssignmentsHttpHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	_, submission, ok := personalSubmission(h.Service, h.SubmissionsService, w, r, currentUser)
+	if !ok {
+		return
+	}
+	submissionAttachments, err := h.SubmissionsService.GetAttachments(submission.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	var attachment *submissions.Attachment
+	attachmentID := assignmentID(mux.Vars(r)["attachment_id"])
+	for _, att := range submissionAttachments {
+		if att.ID == attachmentID {
+			attachment = att
+			break
+		}
+	}
+	if attachment == nil {
+		utils.RenderNotFound(w, r)
+		return
+	}
+
+	file, err := h.Service.OpenAttachment(attachment)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	utils.SetAttachmentHeaders(w, attachment.Name)
+	io.Copy(w, file)
+}
+
+// SubmissionEvents streams live stdout of the grading run as Server-Sent
+// Events and finishes the stream with the result once it is reported
+func (h AssignmentsHttpHandler) SubmissionEvents(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	_, submission, ok := personalSubmission(h.Service, h.SubmissionsService, w, r, currentUser)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.RenderInternalError(w, r, errors.New("streaming is not supported"))
+		return
+	}
+
+	backlog, lines, unsubscribe := h.SubmissionsService.SubscribeLogs(submission.ID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// The result could have been reported before subscribing to the logs
+	submission, err = h.SubmissionsService.GetByID(submission.ID)
+	if err != nil || submission == nil {
+		log.Printf("Can't load submission for events: %v", err)
+		return
+	}
+
+	if submission.Status == submissions.InProgress {
+		for _, line := range backlog {
+			if err := writeEvent(w, "log", line); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+	events:
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					break events
+				}
+				if err := writeEvent(w, "log", line); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+			flusher.Flush()
+		}
+
+		submission, err = h.SubmissionsService.GetByID(submission.ID)
+		if err != nil || submission == nil {
+			log.Printf("Can't load submission for events: %v", err)
+			return
+		}
+	}
+
+	result, err := json.Marshal(&struct {
+		Status  string `json:"status"`
+		Details string `json:"details"`
+	}{utils.SubmissionStatus(submission.Status), submission.Details})
+	if err != nil {
+		log.Printf("Can't encode submission result: %v", err)
+		return
+	}
+	if err := writeEvent(w, "result", string(result)); err != nil {
+		return
+	}
+	flusher.Flush()
+}
+
+// submittedFiles opens every file of the form, they are matched with the
+// file specs of the assignment by their names
+func submittedFiles(r *http.Request) ([]*services.SubmissionFile, func(), error) {
+	files := []*services.SubmissionFile{}
+	opened := []io.Closer{}
+	closeAll := func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}
+
+	if err := r.ParseMultipartForm(maxSubmissionSize); err != nil {
+		return nil, nil, err
+	}
+	fields := make([]string, 0, len(r.MultipartForm.File))
+	for field := range r.MultipartForm.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		for _, header := range r.MultipartForm.File[field] {
+			file, err := header.Open()
+			if err != nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("file %q can't be read: %w", header.Filename, err)
+			}
+			opened = append(opened, file)
+			files = append(files, &services.SubmissionFile{Content: file, Name: header.Filename, Size: header.Size})
+		}
+	}
+
+	return files, closeAll, nil
+}
+
+// submissionForm prepares the submission form of the assignment with the attempts left to the user
+func (h AssignmentsHttpHandler) submissionForm(
+	currentUser *users.User,
+	assignment *assignments.Assignment,
+) (*newSubmissionData, error) {
+	attemptsLeft, err := h.Service.AttemptsLeft(currentUser, assignment)
+	if err != nil {
+		return nil, err
+	}
+	idempotencyKey, err := generateIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &newSubmissionData{
+		Assignment:     assignment,
+		Open:           assignment.IsOpen(time.Now()),
+		AttemptsLeft:   attemptsLeft,
+		IdempotencyKey: idempotencyKey,
+	}, nil
+}
+
+func generateIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+func isValidationError(err error) bool {
+	switch err.(type) {
+	case *services.AssignmentValidationError, *services.FilesValidationError, *services.SubmissionLimitError:
+		return true
+	}
+
+	return false
+}
+
+// personalSubmission loads the submission of the route and renders not found
+// unless it belongs to the current user or the user is the course staff
+func personalSubmission(
+	service services.AssignmentsServiceInterface,
+	submissionsService submissionsServices.SubmissionsServiceInterface,
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+) (*assignments.Assignment, *submissions.Submission, bool) {
+	params := mux.Vars(r)
+	assignment, err := service.GetByID(assignmentID(params["id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return nil, nil, false
+	}
+	if assignment == nil {
+		utils.RenderNotFound(w, r)
+		return nil, nil, false
+	}
+
+	submission, err := submissionsService.GetByID(assignmentID(params["submission_id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return nil, nil, false
+	}
+	if submission == nil || submission.AssignmentID != assignment.ID ||
+		(submission.UserID != currentUser.ID && !isCourseStaff(r, currentUser)) {
+		utils.RenderNotFound(w, r)
+		return nil, nil, false
+	}
+
+	return assignment, submission, true
+}
+
+func (h AssignmentsHttpHandler) renderAssignmentForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+	data *newAssignmentnData,
+) {
+	var err error
+	data.Courses, err = h.CoursesService.GetManaged(currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if data.Assignment.ID != 0 {
+		data.Validations, err = h.Service.GetValidations(data.Assignment.ID)
+		if err != nil {
+			utils.RenderInternalError(w, r, err)
+			return
+		}
+	}
+
+	err = h.Views["AssignmentForm"].RenderView(w, data, currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+// checkCourse makes sure the user is allowed to put the assignment into its course
+func checkCourse(
+	coursesService coursesServices.CoursesServiceInterface,
+	currentUser *users.User,
+	assignment *assignments.Assignment,
+) error {
+	if assignment.CourseID == 0 {
+		return nil
+	}
+
+	ok, err := coursesService.CanManage(currentUser, assignment.CourseID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &services.AssignmentValidationError{Message: services.MsgForbiddenCourseError}
+	}
+
+	return nil
+}
+
+// isCourseStaff reports whether the user can see all submissions of the course
+// of the route, access middlewares put the membership into the context
+func isCourseStaff(r *http.Request, currentUser *users.User) bool {
+	if currentUser.IsAdmin() {
+		return true
+	}
+	member := courses.CurrentMember(r)
+
+	return member != nil && member.IsStaff()
+}
+
+// publishedAssignment loads the assignment of the route and renders not found
+// for drafts unless the user is the course staff
+func publishedAssignment(
+	service services.AssignmentsServiceInterface,
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+) (*assignments.Assignment, bool) {
+	params := mux.Vars(r)
+	assignment, err := service.GetByID(assignmentID(params["id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return nil, false
+	}
+	if assignment == nil || (!assignment.Published && !isCourseStaff(r, currentUser)) {
+		utils.RenderNotFound(w, r)
+		return nil, false
+	}
+
+	return assignment, true
+}
+
+// writeEvent writes a Server-Sent Event, data can't contain line breaks
+func writeEvent(w io.Writer, event string, data string) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, eventDataReplacer.Replace(data))
+
+	return err
+}
+
+func (h AssignmentsHttpHandler) New(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	h.renderAssignmentForm(w, r, currentUser, &newAssignmentnData{
+		Assignment: &assignments.Assignment{Limits: assignments.DefaultResourceLimits},
+		Action:     "create",
+	})
+}
+
 func (h AssignmentsHttpHandler) Create(w http.ResponseWriter, r *http.Request) {
 	currentUser, err := h.SessionManager.CurrentUser(r)
 	if err != nil {
@@ -274,31 +678,63 @@ func (h AssignmentsHttpHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Both solutions can be uploaded with the form
+	r.Body = http.MaxBytesReader(w, r.Body, 2*maxSubmissionSize)
+
 	assignment := &assignments.Assignment{
-		CreatorID:   currentUser.ID,
-		Title:       r.FormValue("title"),
-		Description: r.FormValue("description"),
-		GraderURL:   r.FormValue("grader_url"),
-		Container:   r.FormValue("container"),
-		PartID:      r.FormValue("part_id"),
-		Files:       formatAssignmentFiles(r.FormValue("files")),
+		CreatorID:       currentUser.ID,
+		CourseID:        intParam(r.FormValue("course_id")),
+		Title:           r.FormValue("title"),
+		Description:     r.FormValue("description"),
+		GraderURL:       r.FormValue("grader_url"),
+		Container:       r.FormValue("container"),
+		PartID:          r.FormValue("part_id"),
+		MaxScore:        floatParam(r.FormValue("max_score")),
+		Limits:          formatLimits(r),
+		HideStderr:      r.FormValue("hide_stderr") == "on",
+		Archive:         r.FormValue("archive") == "on",
+		AllowGit:        r.FormValue("allow_git") == "on",
+		AllowParallel:   r.FormValue("allow_parallel") == "on",
+		MaxAttempts:     int(intParam(r.FormValue("max_attempts"))),
+		CooldownSeconds: intParam(r.FormValue("cooldown_seconds")),
+		Published:       r.FormValue("published") == "on",
+		OpensAt:         timeParam(r.FormValue("opens_at")),
+		DueAt:           timeParam(r.FormValue("due_at")),
+		LateDueAt:       timeParam(r.FormValue("late_due_at")),
+	}
+	solutions, closeSolutions, err := solutionFiles(r)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	defer closeSolutions()
+
+	assignment.Files, err = formatAssignmentFiles(r.FormValue("files"))
+	if err == nil {
+		err = checkCourse(h.CoursesService, currentUser, assignment)
+	}
+	var saved *assignments.Assignment
+	if err == nil {
+		saved, err = h.Service.Create(currentUser, assignment, solutions)
+	}
+	if _, ok := err.(*services.SolutionsError); ok {
+		// The assignment is created, its form is shown to upload the solutions again
+		h.renderAssignmentForm(w, r, currentUser, &newAssignmentnData{
+			Assignment: saved,
+			Files:      saved.Files.String(),
+			Errors:     []string{err.Error()},
+			Action:     "update",
+		})
+		return
 	}
-	_, err = h.Service.Create(assignment)
 	if err != nil {
 		if _, ok := err.(*services.AssignmentValidationError); ok {
-			err = h.Views["AssignmentForm"].RenderView(
-				w,
-				newAssignmentnData{
-					Assignment: assignment,
-					Files:      r.FormValue("files"),
-					Errors:     []string{err.Error()},
-					Action:     "create",
-				},
-				currentUser,
-			)
-			if err != nil {
-				utils.RenderInternalError(w, r, err)
-			}
+			h.renderAssignmentForm(w, r, currentUser, &newAssignmentnData{
+				Assignment: assignment,
+				Files:      r.FormValue("files"),
+				Errors:     []string{err.Error()},
+				Action:     "create",
+			})
 		} else {
 			utils.RenderInternalError(w, r, err)
 		}
@@ -327,18 +763,11 @@ func (h AssignmentsHttpHandler) Edit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.Views["AssignmentForm"].RenderView(
-		w,
-		newAssignmentnData{
-			Assignment: assignment,
-			Files:      strings.Join(assignment.Files, ","),
-			Action:     "update",
-		},
-		currentUser,
-	)
-	if err != nil {
-		utils.RenderInternalError(w, r, err)
-	}
+	h.renderAssignmentForm(w, r, currentUser, &newAssignmentnData{
+		Assignment: assignment,
+		Files:      assignment.Files.String(),
+		Action:     "update",
+	})
 }
 
 func (h AssignmentsHttpHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -348,6 +777,8 @@ func (h AssignmentsHttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, 2*maxSubmissionSize)
+
 	params := mux.Vars(r)
 	assignment, err := h.Service.GetByIDByCreator(assignmentID(params["id"]), currentUser)
 	if err != nil {
@@ -359,32 +790,53 @@ func (h AssignmentsHttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	assignment.CourseID = intParam(r.FormValue("course_id"))
 	assignment.Title = r.FormValue("title")
 	assignment.Description = r.FormValue("description")
 	assignment.GraderURL = r.FormValue("grader_url")
 	assignment.Container = r.FormValue("container")
 	assignment.PartID = r.FormValue("part_id")
-	assignment.Files = formatAssignmentFiles(r.FormValue("files"))
+	assignment.MaxScore = floatParam(r.FormValue("max_score"))
+	assignment.Limits = formatLimits(r)
+	assignment.HideStderr = r.FormValue("hide_stderr") == "on"
+	assignment.Archive = r.FormValue("archive") == "on"
+	assignment.AllowGit = r.FormValue("allow_git") == "on"
+	assignment.AllowParallel = r.FormValue("allow_parallel") == "on"
+	assignment.MaxAttempts = int(intParam(r.FormValue("max_attempts")))
+	assignment.CooldownSeconds = intParam(r.FormValue("cooldown_seconds"))
+	assignment.Published = r.FormValue("published") == "on"
+	assignment.OpensAt = timeParam(r.FormValue("opens_at"))
+	assignment.DueAt = timeParam(r.FormValue("due_at"))
+	assignment.LateDueAt = timeParam(r.FormValue("late_due_at"))
 
-	_, err = h.Service.Update(assignment)
+	solutions, closeSolutions, err := solutionFiles(r)
 	if err != nil {
-		if _, ok := err.(*services.AssignmentValidationError); ok {
-			err = h.Views["AssignmentForm"].RenderView(
-				w,
-				newAssignmentnData{
-					Assignment: assignment,
-					Files:      r.FormValue("files"),
-					Errors:     []string{err.Error()},
-					Action:     "update",
-				},
-				currentUser,
-			)
-			if err != nil {
-				utils.RenderInternalError(w, r, err)
-			}
-		} else {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	defer closeSolutions()
+
+	assignment.Files, err = formatAssignmentFiles(r.FormValue("files"))
+	if err == nil {
+		err = checkCourse(h.CoursesService, currentUser, assignment)
+	}
+	if err == nil {
+		_, err = h.Service.Update(currentUser, assignment, solutions)
+	}
+	if err != nil {
+		switch err.(type) {
+		case *services.AssignmentValidationError, *services.SolutionsError:
+			h.renderAssignmentForm(w, r, currentUser, &newAssignmentnData{
+				Assignment: assignment,
+				Files:      r.FormValue("files"),
+				Errors:     []string{err.Error()},
+				Action:     "update",
+			})
+		default:
 			utils.RenderInternalError(w, r, err)
 		}
+
+		return
 	}
 
 	http.Redirect(w, r, "/admin/assignments", http.StatusSeeOther)
@@ -398,7 +850,7 @@ func (h AssignmentsHttpHandler) Show(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := mux.Vars(r)
-	assignment, err := h.Service.GetByIDByCreator(assignmentID(params["id"]), currentUser)
+	assignment, err := h.Service.GetByIDByStaff(assignmentID(params["id"]), currentUser)
 	if err != nil {
 		utils.RenderInternalError(w, r, err)
 		return
@@ -426,8 +878,178 @@ func (h AssignmentsHttpHandler) Show(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func formatAssignmentFiles(files string) []string {
-	return strings.Split(files, ",")
+// Regrade queues submissions of the assignment again, the scope form value
+// "latest" limits it to the last submission of every student
+func (h AssignmentsHttpHandler) Regrade(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	params := mux.Vars(r)
+	assignment, err := h.Service.GetByIDByStaff(assignmentID(params["id"]), currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if assignment == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	count, err := h.Service.RegradeAll(assignment, r.FormValue("scope") == "latest")
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	log.Printf("Queued %d submissions of assignment %d for regrading", count, assignment.ID)
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/assignments/%d", assignment.ID), http.StatusSeeOther)
+}
+
+func (h AssignmentsHttpHandler) RegradeSubmission(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	params := mux.Vars(r)
+	assignment, err := h.Service.GetByIDByStaff(assignmentID(params["id"]), currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if assignment == nil {
+		http.NotFound(w, r)
+		return
+	}
+	submission, err := h.SubmissionsService.GetByID(assignmentID(params["submission_id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if submission == nil || submission.AssignmentID != assignment.ID {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = h.Service.Regrade(assignment, submission)
+	if err != nil {
+		if isValidationError(err) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		} else {
+			utils.RenderInternalError(w, r, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/assignments/%d", assignment.ID), http.StatusSeeOther)
+}
+
+// solutionFiles opens the solutions uploaded as reference_files and
+// bad_files, the returned function closes them
+func solutionFiles(r *http.Request) (*services.Solutions, func(), error) {
+	solutions := &services.Solutions{}
+	opened := []io.Closer{}
+	closeAll := func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}
+
+	err := r.ParseMultipartForm(2 * maxSubmissionSize)
+	if err == http.ErrNotMultipart {
+		return solutions, closeAll, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := map[string]*[]*services.SubmissionFile{
+		"reference_files": &solutions.Reference,
+		"bad_files":       &solutions.Bad,
+	}
+	for field, files := range fields {
+		for _, header := range r.MultipartForm.File[field] {
+			file, err := header.Open()
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			opened = append(opened, file)
+			*files = append(*files, &services.SubmissionFile{Content: file, Name: header.Filename, Size: header.Size})
+		}
+	}
+
+	return solutions, closeAll, nil
+}
+
+// formatAssignmentFiles parses file specs of the form, one spec per line
+func formatAssignmentFiles(files string) (assignments.FileSpecs, error) {
+	specs, err := assignments.ParseFileSpecs(files)
+	if err != nil {
+		return nil, &services.AssignmentValidationError{Message: services.MsgInvalidFilesError + ": " + err.Error()}
+	}
+
+	return specs, nil
+}
+
+func formatLimits(r *http.Request) assignments.ResourceLimits {
+	return assignments.ResourceLimits{
+		MemoryMB:       intParam(r.FormValue("memory_mb")),
+		CPUs:           floatParam(r.FormValue("cpus")),
+		Pids:           intParam(r.FormValue("pids")),
+		TmpfsMB:        intParam(r.FormValue("tmpfs_mb")),
+		TimeoutSeconds: intParam(r.FormValue("timeout_seconds")),
+	}
+}
+
+// timeParam parses datetime-local inputs in the server time zone, blank input
+// means no date and malformed input returns the zero time so it fails validation
+func timeParam(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return nil
+	}
+
+	result, err := time.ParseInLocation(dateTimeLocalLayout, value, time.Local)
+	if err != nil {
+		result = time.Time{}
+	}
+
+	return &result
+}
+
+// floatParam returns a negative value for malformed input so it fails validation
+func floatParam(value string) float64 {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(result) || math.IsInf(result, 0) {
+		return -1
+	}
+
+	return result
+}
+
+// intParam returns a negative value for malformed input so it fails validation
+func intParam(value string) int64 {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return result
 }
 
 func assignmentID(param string) int64 {
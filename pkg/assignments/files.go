@@ -0,0 +1,282 @@
+package assignments
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileSpec describes the submitted files matching Pattern
+type FileSpec struct {
+	// Pattern is a file name or a glob pattern, ** matches any number of directories
+	Pattern  string `json:"pattern"`
+	Optional bool   `json:"optional,omitempty"`
+	// MaxSize limits every matching file in bytes, zero means no limit
+	MaxSize int64 `json:"max_size,omitempty"`
+	// Extensions are allowed for the matching files, any extension is allowed when empty
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// FileSpecs are stored as JSON, a plain string is a spec of a required file
+type FileSpecs []*FileSpec
+
+// fileSpecJSON prevents the recursion of the custom encoding
+type fileSpecJSON FileSpec
+
+// IsGlob reports whether the spec matches several files
+func (s *FileSpec) IsGlob() bool {
+	return strings.ContainsAny(s.Pattern, "*?[")
+}
+
+func (s *FileSpec) Match(name string) bool {
+	return MatchPattern(s.Pattern, name)
+}
+
+// AllowedExtension reports whether the name ends with one of the extensions of the spec
+func (s *FileSpec) AllowedExtension(name string) bool {
+	if len(s.Extensions) == 0 {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, extension := range s.Extensions {
+		if strings.HasSuffix(lower, strings.ToLower(extension)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks the pattern, the size limit and the extensions of the spec
+func (s *FileSpec) Validate() error {
+	if len(s.Pattern) == 0 {
+		return fmt.Errorf("file pattern can't be blank")
+	}
+	if err := ValidatePattern(s.Pattern); err != nil {
+		return err
+	}
+	if s.MaxSize < 0 {
+		return fmt.Errorf("%s: max size should be a non-negative number", s.Pattern)
+	}
+	for _, extension := range s.Extensions {
+		if len(extension) < 2 || !strings.HasPrefix(extension, ".") {
+			return fmt.Errorf("%s: extension %q should start with a dot", s.Pattern, extension)
+		}
+	}
+
+	return nil
+}
+
+// String formats the spec as a line of the assignment form:
+// the pattern followed by the optional, max= and ext= options
+func (s *FileSpec) String() string {
+	parts := []string{s.Pattern}
+	if s.Optional {
+		parts = append(parts, "optional")
+	}
+	if s.MaxSize > 0 {
+		parts = append(parts, "max="+FormatSize(s.MaxSize))
+	}
+	if len(s.Extensions) > 0 {
+		parts = append(parts, "ext="+strings.Join(s.Extensions, ","))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (s *FileSpec) MaxSizeText() string {
+	return FormatSize(s.MaxSize)
+}
+
+// MarshalJSON encodes specs without options as plain strings
+func (s FileSpec) MarshalJSON() ([]byte, error) {
+	if !s.Optional && s.MaxSize == 0 && len(s.Extensions) == 0 {
+		return json.Marshal(s.Pattern)
+	}
+
+	return json.Marshal(fileSpecJSON(s))
+}
+
+func (s *FileSpec) UnmarshalJSON(data []byte) error {
+	var pattern string
+	if err := json.Unmarshal(data, &pattern); err == nil {
+		*s = FileSpec{Pattern: pattern}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*fileSpecJSON)(s))
+}
+
+// ParseFileSpec parses a line of the assignment form, for example:
+// src/**/*.rb optional max=100KB ext=.rb,.erb
+func ParseFileSpec(line string) (*FileSpec, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("file pattern can't be blank")
+	}
+
+	spec := &FileSpec{Pattern: fields[0]}
+	for _, option := range fields[1:] {
+		switch {
+		case option == "optional":
+			spec.Optional = true
+		case strings.HasPrefix(option, "max="):
+			size, err := ParseSize(strings.TrimPrefix(option, "max="))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", spec.Pattern, err)
+			}
+			spec.MaxSize = size
+		case strings.HasPrefix(option, "ext="):
+			spec.Extensions = strings.Split(strings.TrimPrefix(option, "ext="), ",")
+		default:
+			return nil, fmt.Errorf("%s: unknown option %q", spec.Pattern, option)
+		}
+	}
+
+	return spec, nil
+}
+
+// ParseFileSpecs parses the lines of the assignment form, blank lines are skipped
+func ParseFileSpecs(text string) (FileSpecs, error) {
+	specs := FileSpecs{}
+	for _, line := range strings.Split(text, "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		spec, err := ParseFileSpec(line)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+// Find returns the first spec matching the name, nil is returned when there is no such spec
+func (s FileSpecs) Find(name string) *FileSpec {
+	for _, spec := range s {
+		if spec.Match(name) {
+			return spec
+		}
+	}
+
+	return nil
+}
+
+func (s FileSpecs) String() string {
+	lines := make([]string, len(s))
+	for i, spec := range s {
+		lines[i] = spec.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (s FileSpecs) Value() (driver.Value, error) {
+	if s == nil {
+		s = FileSpecs{}
+	}
+
+	return json.Marshal(s)
+}
+
+func (s *FileSpecs) Scan(src any) error {
+	switch data := src.(type) {
+	case nil:
+		*s = FileSpecs{}
+		return nil
+	case []byte:
+		return json.Unmarshal(data, s)
+	case string:
+		return json.Unmarshal([]byte(data), s)
+	default:
+		return fmt.Errorf("can't scan %T into file specs", src)
+	}
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"MB", 1024 * 1024},
+	{"KB", 1024},
+	{"B", 1},
+}
+
+// ParseSize parses sizes like 512, 100KB or 5MB
+func ParseSize(text string) (int64, error) {
+	upper := strings.ToUpper(text)
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(upper, unit.suffix) {
+			upper = strings.TrimSuffix(upper, unit.suffix)
+			multiplier = unit.size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+
+	return size * multiplier, nil
+}
+
+func FormatSize(size int64) string {
+	for _, unit := range sizeUnits {
+		if size >= unit.size && size%unit.size == 0 {
+			return fmt.Sprintf("%d%s", size/unit.size, unit.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
+
+// MatchPattern reports whether the slash separated name matches the pattern,
+// ** matches any number of directories and other parts follow path.Match
+func MatchPattern(pattern, name string) bool {
+	return matchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// ValidatePattern checks the syntax of the pattern
+func ValidatePattern(pattern string) error {
+	for _, part := range strings.Split(pattern, "/") {
+		if part == "**" {
+			continue
+		}
+		if len(part) == 0 || part == "." || part == ".." {
+			return fmt.Errorf("%s: pattern should be a relative path", pattern)
+		}
+		if _, err := path.Match(part, ""); err != nil {
+			return fmt.Errorf("%s: %v", pattern, err)
+		}
+	}
+
+	return nil
+}
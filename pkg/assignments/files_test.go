@@ -0,0 +1,106 @@
+package assignments
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	type testCase struct {
+		Pattern string
+		Name    string
+		Match   bool
+	}
+
+	testCases := []*testCase{
+		{Pattern: "main.go", Name: "main.go", Match: true},
+		{Pattern: "main.go", Name: "lib.go"},
+		{Pattern: "*.go", Name: "main.go", Match: true},
+		{Pattern: "*.go", Name: "pkg/main.go"},
+		{Pattern: "src/**/*.rb", Name: "src/app.rb", Match: true},
+		{Pattern: "src/**/*.rb", Name: "src/lib/deep/util.rb", Match: true},
+		{Pattern: "src/**/*.rb", Name: "lib/util.rb"},
+		{Pattern: "**/*_test.go", Name: "main_test.go", Match: true},
+		{Pattern: "**", Name: "any/file", Match: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Pattern+" "+testCase.Name, func(t *testing.T) {
+			if got := MatchPattern(testCase.Pattern, testCase.Name); got != testCase.Match {
+				t.Errorf("expected match to be %v, got %v", testCase.Match, got)
+			}
+		})
+	}
+}
+
+func TestParseFileSpec(t *testing.T) {
+	type testCase struct {
+		Title string
+		Line  string
+		Want  *FileSpec
+		Valid bool
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "name",
+			Line:  " main.go ",
+			Want:  &FileSpec{Pattern: "main.go"},
+			Valid: true,
+		},
+		{
+			Title: "options",
+			Line:  "src/**/*.rb optional max=100KB ext=.rb,.erb",
+			Want:  &FileSpec{Pattern: "src/**/*.rb", Optional: true, MaxSize: 100 * 1024, Extensions: []string{".rb", ".erb"}},
+			Valid: true,
+		},
+		{
+			Title: "invalid size",
+			Line:  "main.go max=big",
+		},
+		{
+			Title: "unknown option",
+			Line:  "main.go required",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			got, err := ParseFileSpec(testCase.Line)
+			if !testCase.Valid {
+				if err == nil {
+					t.Fatalf("expected to have errors")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Fatalf("expected %+v, got %+v", testCase.Want, got)
+			}
+			if reparsed, _ := ParseFileSpec(got.String()); !reflect.DeepEqual(reparsed, got) {
+				t.Errorf("expected %q to be parsed back, got %+v", got.String(), reparsed)
+			}
+		})
+	}
+}
+
+func TestFileSpecsJSON(t *testing.T) {
+	data := `["main.go",{"pattern":"*.go","optional":true,"max_size":1024}]`
+	want := FileSpecs{{Pattern: "main.go"}, {Pattern: "*.go", Optional: true, MaxSize: 1024}}
+
+	var got FileSpecs
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	encoded, err := json.Marshal(got)
+	if err != nil || string(encoded) != data {
+		t.Errorf("expected %s, got %s (%v)", data, encoded, err)
+	}
+}
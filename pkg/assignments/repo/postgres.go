@@ -2,11 +2,24 @@ package repo
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
-	"github.com/lib/pq"
 	"github.com/maxshend/grader/pkg/assignments"
+	"github.com/maxshend/grader/pkg/courses"
+	"github.com/maxshend/grader/pkg/repo"
+	"github.com/maxshend/grader/pkg/submissions"
 )
 
+const assignmentColumns = "id, course_id, title, description, grader_url, container, part_id, files, creator_id, max_score, " +
+	"memory_limit_mb, cpu_limit, pids_limit, tmpfs_size_mb, timeout_seconds, hide_stderr, " +
+	"published, opens_at, due_at, late_due_at, archive, allow_git, max_attempts, cooldown_seconds, allow_parallel"
+
+// staffCourses selects courses where the user is a member with at least the given role
+func staffCourses(userArg int, roleArg int) string {
+	return fmt.Sprintf("SELECT course_id FROM course_members WHERE user_id = $%d AND role >= $%d", userArg, roleArg)
+}
+
 type AssignmentsSQLRepo struct {
 	DB *sql.DB
 }
@@ -15,13 +28,26 @@ func NewAssignmentsSQLRepo(db *sql.DB) *AssignmentsSQLRepo {
 	return &AssignmentsSQLRepo{DB: db}
 }
 
-func (r *AssignmentsSQLRepo) GetAllByCreator(creatorID int64, limit int, offset int) ([]*assignments.Assignment, error) {
-	rows, err := r.DB.Query(
-		"SELECT id, title, grader_url "+
-			"FROM assignments WHERE (creator_id = $3 OR creator_id IS NULL) "+
+func (r *AssignmentsSQLRepo) GetAll(limit int, offset int) ([]*assignments.Assignment, error) {
+	return r.getList(
+		"SELECT id, creator_id, title, grader_url, published, due_at "+
+			"FROM assignments ORDER BY id DESC LIMIT $1 OFFSET $2",
+		limit, offset,
+	)
+}
+
+// GetAllByStaff returns assignments created by the user or belonging to courses the user teaches
+func (r *AssignmentsSQLRepo) GetAllByStaff(userID int64, limit int, offset int) ([]*assignments.Assignment, error) {
+	return r.getList(
+		"SELECT id, creator_id, title, grader_url, published, due_at "+
+			"FROM assignments WHERE creator_id = $3 OR course_id IN ("+staffCourses(3, 4)+") "+
 			"ORDER BY id DESC LIMIT $1 OFFSET $2",
-		limit, offset, creatorID,
+		limit, offset, userID, courses.AssistantRole,
 	)
+}
+
+func (r *AssignmentsSQLRepo) getList(query string, args ...any) ([]*assignments.Assignment, error) {
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,12 +56,16 @@ func (r *AssignmentsSQLRepo) GetAllByCreator(creatorID int64, limit int, offset
 	result := []*assignments.Assignment{}
 	for rows.Next() {
 		assignment := &assignments.Assignment{}
+		creatorID := sql.NullInt64{}
+		dueAt := sql.NullTime{}
 		err = rows.Scan(
-			&assignment.ID, &assignment.Title, &assignment.GraderURL,
+			&assignment.ID, &creatorID, &assignment.Title, &assignment.GraderURL, &assignment.Published, &dueAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		assignment.CreatorID = creatorID.Int64
+		assignment.DueAt = timePtr(dueAt)
 
 		result = append(result, assignment)
 	}
@@ -47,53 +77,25 @@ func (r *AssignmentsSQLRepo) GetAllByCreator(creatorID int64, limit int, offset
 }
 
 func (r *AssignmentsSQLRepo) GetByID(id int64) (*assignments.Assignment, error) {
-	assignment := &assignments.Assignment{}
-	var creatorID sql.NullInt64
-	err := r.DB.QueryRow(
-		"SELECT id, title, description, grader_url, container, part_id, files, creator_id "+
-			"FROM assignments WHERE id = $1 LIMIT 1",
+	return scanAssignment(r.DB.QueryRow(
+		"SELECT "+assignmentColumns+" FROM assignments WHERE id = $1 LIMIT 1",
 		id,
-	).Scan(
-		&assignment.ID, &assignment.Title, &assignment.Description,
-		&assignment.GraderURL, &assignment.Container, &assignment.PartID, pq.Array(&assignment.Files),
-		&creatorID,
-	)
-	assignment.CreatorID = creatorID.Int64
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		} else {
-			return nil, err
-		}
-	}
-
-	return assignment, nil
+	))
 }
 
 func (r *AssignmentsSQLRepo) GetByIDByCreator(id int64, creatorID int64) (*assignments.Assignment, error) {
-	assignment := &assignments.Assignment{}
-	var creatorIDVal sql.NullInt64
-	err := r.DB.QueryRow(
-		"SELECT id, title, description, grader_url, container, part_id, files, creator_id "+
-			"FROM assignments WHERE id = $1 AND (creator_id = $2 OR creator_id IS NULL) LIMIT 1",
+	return scanAssignment(r.DB.QueryRow(
+		"SELECT "+assignmentColumns+" FROM assignments WHERE id = $1 AND creator_id = $2 LIMIT 1",
 		id, creatorID,
-	).Scan(
-		&assignment.ID, &assignment.Title, &assignment.Description,
-		&assignment.GraderURL, &assignment.Container, &assignment.PartID, pq.Array(&assignment.Files),
-		&creatorIDVal,
-	)
-	assignment.CreatorID = creatorIDVal.Int64
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		} else {
-			return nil, err
-		}
-	}
+	))
+}
 
-	return assignment, nil
+func (r *AssignmentsSQLRepo) GetByIDByStaff(id int64, userID int64) (*assignments.Assignment, error) {
+	return scanAssignment(r.DB.QueryRow(
+		"SELECT "+assignmentColumns+" FROM assignments "+
+			"WHERE id = $1 AND (creator_id = $2 OR course_id IN ("+staffCourses(2, 3)+")) LIMIT 1",
+		id, userID, courses.AssistantRole,
+	))
 }
 
 func (r *AssignmentsSQLRepo) GetByUserID(userID int64, limit, offset int) ([]*assignments.Assignment, error) {
@@ -128,25 +130,21 @@ func (r *AssignmentsSQLRepo) GetByUserID(userID int64, limit, offset int) ([]*as
 	return result, nil
 }
 
-func (r *AssignmentsSQLRepo) Create(
-	creatorID int64,
-	title, description, graderURL,
-	container, partID string, files []string,
-) (*assignments.Assignment, error) {
-	assignment := &assignments.Assignment{
-		CreatorID:   creatorID,
-		Title:       title,
-		Description: description,
-		GraderURL:   graderURL,
-		Container:   container,
-		PartID:      partID,
-		Files:       files,
-	}
-
+func (r *AssignmentsSQLRepo) Create(assignment *assignments.Assignment) (*assignments.Assignment, error) {
 	err := r.DB.QueryRow(
-		"INSERT INTO assignments (title, description, grader_url, container, part_id, files, creator_id) "+
-			"VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
-		title, description, graderURL, container, partID, pq.Array(files), creatorID,
+		"INSERT INTO assignments (title, description, grader_url, container, part_id, files, creator_id, max_score, "+
+			"memory_limit_mb, cpu_limit, pids_limit, tmpfs_size_mb, timeout_seconds, hide_stderr, "+
+			"published, opens_at, due_at, late_due_at, course_id, archive, allow_git, "+
+			"max_attempts, cooldown_seconds, allow_parallel) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, "+
+			"$22, $23, $24) RETURNING id",
+		assignment.Title, assignment.Description, assignment.GraderURL, assignment.Container,
+		assignment.PartID, assignment.Files, assignment.CreatorID, assignment.MaxScore,
+		assignment.Limits.MemoryMB, assignment.Limits.CPUs, assignment.Limits.Pids,
+		assignment.Limits.TmpfsMB, assignment.Limits.TimeoutSeconds, assignment.HideStderr,
+		assignment.Published, assignment.OpensAt, assignment.DueAt, assignment.LateDueAt, assignment.CourseID,
+		assignment.Archive, assignment.AllowGit, assignment.MaxAttempts, assignment.CooldownSeconds,
+		assignment.AllowParallel,
 	).Scan(&assignment.ID)
 	if err != nil {
 		return nil, err
@@ -158,9 +156,17 @@ func (r *AssignmentsSQLRepo) Create(
 func (r *AssignmentsSQLRepo) Update(assignment *assignments.Assignment) (*assignments.Assignment, error) {
 	_, err := r.DB.Exec(
 		"UPDATE assignments SET title = $1, description = $2, grader_url = $3, container = $4, "+
-			"part_id = $5, files = $6 WHERE id = $7",
+			"part_id = $5, files = $6, max_score = $7, memory_limit_mb = $8, cpu_limit = $9, "+
+			"pids_limit = $10, tmpfs_size_mb = $11, timeout_seconds = $12, hide_stderr = $13, "+
+			"published = $14, opens_at = $15, due_at = $16, late_due_at = $17, course_id = $18, archive = $19, "+
+			"allow_git = $20, max_attempts = $21, cooldown_seconds = $22, allow_parallel = $23 WHERE id = $24",
 		assignment.Title, assignment.Description, assignment.GraderURL, assignment.Container,
-		assignment.PartID, pq.Array(assignment.Files), assignment.ID,
+		assignment.PartID, assignment.Files, assignment.MaxScore,
+		assignment.Limits.MemoryMB, assignment.Limits.CPUs, assignment.Limits.Pids,
+		assignment.Limits.TmpfsMB, assignment.Limits.TimeoutSeconds, assignment.HideStderr,
+		assignment.Published, assignment.OpensAt, assignment.DueAt, assignment.LateDueAt, assignment.CourseID,
+		assignment.Archive, assignment.AllowGit, assignment.MaxAttempts, assignment.CooldownSeconds,
+		assignment.AllowParallel, assignment.ID,
 	)
 	if err != nil {
 		return nil, err
@@ -169,19 +175,156 @@ func (r *AssignmentsSQLRepo) Update(assignment *assignments.Assignment) (*assign
 	return assignment, nil
 }
 
+// GetPublished returns published assignments with the number of attempts
+// and the last submission of the user
+func (r *AssignmentsSQLRepo) GetPublishedCount(userID int64) (count int, err error) {
+	err = r.DB.QueryRow(
+		"SELECT COUNT(*) FROM assignments "+
+			"JOIN course_members ON assignments.course_id = course_members.course_id AND course_members.user_id = $1 "+
+			"WHERE assignments.published",
+		userID,
+	).Scan(&count)
+
+	return
+}
+
+func (r *AssignmentsSQLRepo) GetPublished(userID int64, limit, offset int) ([]*assignments.CatalogEntry, error) {
+	rows, err := r.DB.Query(
+		"SELECT assignments.id, assignments.course_id, courses.title, assignments.title, "+
+			"assignments.opens_at, assignments.due_at, assignments.late_due_at, assignments.max_attempts, "+
+			"(SELECT COUNT(*) FROM submissions WHERE assignment_id = assignments.id AND user_id = $1), "+
+			"last.id, last.status, last.score, last.max_score, last.late, last.created_at "+
+			"FROM assignments LEFT JOIN LATERAL ("+
+			"SELECT id, status, score, max_score, late, created_at FROM submissions "+
+			"WHERE assignment_id = assignments.id AND user_id = $1 ORDER BY id DESC LIMIT 1"+
+			") last ON true "+
+			"JOIN courses ON assignments.course_id = courses.id "+
+			"JOIN course_members ON courses.id = course_members.course_id AND course_members.user_id = $1 "+
+			"WHERE assignments.published ORDER BY assignments.id DESC LIMIT $2 OFFSET $3",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*assignments.CatalogEntry{}
+	for rows.Next() {
+		entry := &assignments.CatalogEntry{Assignment: &assignments.Assignment{Published: true}}
+		opensAt, dueAt, lateDueAt := sql.NullTime{}, sql.NullTime{}, sql.NullTime{}
+		submissionID, status := sql.NullInt64{}, sql.NullInt64{}
+		score, maxScore := sql.NullFloat64{}, sql.NullFloat64{}
+		late, createdAt := sql.NullBool{}, sql.NullTime{}
+		err = rows.Scan(
+			&entry.Assignment.ID, &entry.Assignment.CourseID, &entry.CourseTitle, &entry.Assignment.Title,
+			&opensAt, &dueAt, &lateDueAt, &entry.Assignment.MaxAttempts, &entry.Attempts,
+			&submissionID, &status, &score, &maxScore, &late, &createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.Assignment.OpensAt = timePtr(opensAt)
+		entry.Assignment.DueAt = timePtr(dueAt)
+		entry.Assignment.LateDueAt = timePtr(lateDueAt)
+		if submissionID.Valid {
+			entry.LastSubmission = &submissions.Submission{
+				ID:           submissionID.Int64,
+				AssignmentID: entry.Assignment.ID,
+				UserID:       userID,
+				Status:       int(status.Int64),
+				Score:        score.Float64,
+				MaxScore:     maxScore.Float64,
+				Late:         late.Bool,
+				CreatedAt:    createdAt.Time,
+			}
+		}
+
+		result = append(result, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
 func (r *AssignmentsSQLRepo) GetByTitle(title string) (*assignments.Assignment, error) {
-	assignment := &assignments.Assignment{}
-	var creatorID sql.NullInt64
-	err := r.DB.QueryRow(
-		"SELECT id, title, description, grader_url, container, part_id, files, creator_id "+
-			"FROM assignments WHERE title = $1 LIMIT 1",
+	return scanAssignment(r.DB.QueryRow(
+		"SELECT "+assignmentColumns+" FROM assignments WHERE title = $1 LIMIT 1",
 		title,
-	).Scan(
-		&assignment.ID, &assignment.Title, &assignment.Description,
-		&assignment.GraderURL, &assignment.Container, &assignment.PartID, pq.Array(&assignment.Files),
-		&creatorID,
+	))
+}
+
+func (r *AssignmentsSQLRepo) GetValidations(assignmentID int64) ([]*assignments.Validation, error) {
+	rows, err := r.DB.Query(
+		"SELECT assignment_validations.kind, submissions.id, submissions.user_id, submissions.status, "+
+			"submissions.details, submissions.score, submissions.max_score, submissions.created_at "+
+			"FROM assignment_validations JOIN submissions ON assignment_validations.submission_id = submissions.id "+
+			"WHERE assignment_validations.assignment_id = $1 ORDER BY assignment_validations.kind",
+		assignmentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*assignments.Validation{}
+	for rows.Next() {
+		submission := &submissions.Submission{AssignmentID: assignmentID}
+		validation := &assignments.Validation{Submission: submission}
+		userID, details, score := sql.NullInt64{}, sql.NullString{}, sql.NullFloat64{}
+		err = rows.Scan(
+			&validation.Kind, &submission.ID, &userID, &submission.Status,
+			&details, &score, &submission.MaxScore, &submission.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		submission.UserID = userID.Int64
+		submission.Details = details.String
+		submission.Score = score.Float64
+
+		result = append(result, validation)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *AssignmentsSQLRepo) SetValidation(
+	sqlExec repo.SqlQueryable,
+	assignmentID int64,
+	kind int,
+	submissionID int64,
+) error {
+	_, err := sqlExec.Exec(
+		"INSERT INTO assignment_validations (assignment_id, kind, submission_id) VALUES ($1, $2, $3) "+
+			"ON CONFLICT (assignment_id, kind) DO UPDATE SET submission_id = EXCLUDED.submission_id",
+		assignmentID, kind, submissionID,
+	)
+
+	return err
+}
+
+func scanAssignment(row *sql.Row) (*assignments.Assignment, error) {
+	assignment := &assignments.Assignment{}
+	var creatorID, courseID sql.NullInt64
+	opensAt, dueAt, lateDueAt := sql.NullTime{}, sql.NullTime{}, sql.NullTime{}
+	err := row.Scan(
+		&assignment.ID, &courseID, &assignment.Title, &assignment.Description,
+		&assignment.GraderURL, &assignment.Container, &assignment.PartID, &assignment.Files,
+		&creatorID, &assignment.MaxScore, &assignment.Limits.MemoryMB, &assignment.Limits.CPUs,
+		&assignment.Limits.Pids, &assignment.Limits.TmpfsMB, &assignment.Limits.TimeoutSeconds,
+		&assignment.HideStderr, &assignment.Published, &opensAt, &dueAt, &lateDueAt, &assignment.Archive,
+		&assignment.AllowGit, &assignment.MaxAttempts, &assignment.CooldownSeconds, &assignment.AllowParallel,
 	)
 	assignment.CreatorID = creatorID.Int64
+	assignment.CourseID = courseID.Int64
+	assignment.OpensAt = timePtr(opensAt)
+	assignment.DueAt = timePtr(dueAt)
+	assignment.LateDueAt = timePtr(lateDueAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -193,3 +336,11 @@ func (r *AssignmentsSQLRepo) GetByTitle(title string) (*assignments.Assignment,
 
 	return assignment, nil
 }
+
+func timePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
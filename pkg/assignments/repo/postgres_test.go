@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/maxshend/grader/pkg/assignments"
@@ -18,9 +19,14 @@ func TestAssignmentsGetByID(t *testing.T) {
 	defer db.Close()
 
 	repo := NewAssignmentsSQLRepo(db)
-	sqlQuery := "SELECT id, title, description"
-	fields := []string{"id", "title", "description", "grader_url", "container", "part_id", "files", "creator_id"}
+	sqlQuery := "SELECT id, course_id, title, description"
+	fields := []string{"id", "course_id", "title", "description", "grader_url", "container", "part_id", "files", "creator_id", "max_score",
+		"memory_limit_mb", "cpu_limit", "pids_limit", "tmpfs_size_mb", "timeout_seconds", "hide_stderr",
+		"published", "opens_at", "due_at", "late_due_at", "archive", "allow_git",
+		"max_attempts", "cooldown_seconds", "allow_parallel",
+	}
 	var assignmentID int64 = 1
+	dueAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
 
 	type testCase struct {
 		Title   string
@@ -34,15 +40,22 @@ func TestAssignmentsGetByID(t *testing.T) {
 		{
 			Title:   "Success",
 			Success: true,
-			Want:    &assignments.Assignment{ID: assignmentID},
+			Want:    &assignments.Assignment{ID: assignmentID, CourseID: 1, Published: true, DueAt: &dueAt, AllowGit: true, MaxAttempts: 3},
 			Mock: func(t *testing.T, tc *testCase, expected *sqlmock.ExpectedQuery) {
 				t.Helper()
 
-				tc.Want.Files = []string{"main.go"}
-				files := "{\"main.go\"}"
+				tc.Want.Files = assignments.FileSpecs{
+					{Pattern: "main.go"},
+					{Pattern: "*.go", Optional: true, MaxSize: 1024, Extensions: []string{".go"}},
+				}
+				files := `["main.go", {"pattern": "*.go", "optional": true, "max_size": 1024, "extensions": [".go"]}]`
 				rows := sqlmock.NewRows(fields).AddRow(
-					tc.Want.ID, tc.Want.Title, tc.Want.Description, tc.Want.GraderURL,
-					tc.Want.Container, tc.Want.PartID, files, tc.Want.CreatorID,
+					tc.Want.ID, tc.Want.CourseID, tc.Want.Title, tc.Want.Description, tc.Want.GraderURL,
+					tc.Want.Container, tc.Want.PartID, files, tc.Want.CreatorID, tc.Want.MaxScore,
+					tc.Want.Limits.MemoryMB, tc.Want.Limits.CPUs, tc.Want.Limits.Pids,
+					tc.Want.Limits.TmpfsMB, tc.Want.Limits.TimeoutSeconds, tc.Want.HideStderr,
+					tc.Want.Published, nil, tc.Want.DueAt, nil, tc.Want.Archive, tc.Want.AllowGit,
+					tc.Want.MaxAttempts, tc.Want.CooldownSeconds, tc.Want.AllowParallel,
 				)
 
 				expected.WithArgs(tc.Want.ID).WillReturnRows(rows)
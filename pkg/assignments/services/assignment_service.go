@@ -1,17 +1,25 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
-	"strconv"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/maxshend/grader/pkg/archives"
 	"github.com/maxshend/grader/pkg/assignments"
 	"github.com/maxshend/grader/pkg/attachments"
+	"github.com/maxshend/grader/pkg/gitsource"
+	"github.com/maxshend/grader/pkg/repo"
 	"github.com/maxshend/grader/pkg/submissions"
 	"github.com/maxshend/grader/pkg/users"
 	"github.com/maxshend/grader/pkg/utils"
@@ -26,42 +34,136 @@ type AssignmentsService struct {
 	QueueCh         *amqp.Channel
 	QueueName       string
 	JwtSecret       string
+	Git             gitsource.FetcherInterface
+	RateLimit       RateLimit
+	// TaskLifetime is the lifetime of access tokens of grading tasks, see utils.TaskLifetime
+	TaskLifetime time.Duration
 }
 
+// RateLimit caps the submissions of a user to all assignments within Window,
+// zero Submissions means no limit
+type RateLimit struct {
+	Submissions int
+	Window      time.Duration
+}
+
+var DefaultRateLimit = RateLimit{Submissions: 60, Window: time.Hour}
+
 type SubmissionFile struct {
 	Content io.Reader
 	Name    string
+	Size    int64
+	// Entries are the files of a checked archive
+	Entries []string
+}
+
+// Solutions are uploaded by the author to validate the grader of an
+// assignment, empty files keep the previous solution of the kind
+type Solutions struct {
+	Reference []*SubmissionFile
+	Bad       []*SubmissionFile
+}
+
+func (s *Solutions) byKind() map[int][]*SubmissionFile {
+	result := map[int][]*SubmissionFile{}
+	if s == nil {
+		return result
+	}
+	if len(s.Reference) > 0 {
+		result[assignments.ReferenceSolution] = s.Reference
+	}
+	if len(s.Bad) > 0 {
+		result[assignments.BadSolution] = s.Bad
+	}
+
+	return result
+}
+
+func (s *Solutions) uploaded() bool {
+	return len(s.byKind()) > 0
 }
 
 type SubmitAssignmentTask struct {
-	GraderURL    string                    `json:"grader_url"`
-	AccessToken  string                    `json:"access_token"`
-	WebhookURL   string                    `json:"webhook_url"`
-	Container    string                    `json:"container"`
-	SubmissionID int64                     `json:"submission_id"`
-	PartID       string                    `json:"part_id"`
-	Files        []*submissions.Attachment `json:"files"`
+	GraderURL    string                     `json:"grader_url"`
+	AccessToken  string                     `json:"access_token"`
+	WebhookURL   string                     `json:"webhook_url"`
+	LogsURL      string                     `json:"logs_url"`
+	Container    string                     `json:"container"`
+	SubmissionID int64                      `json:"submission_id"`
+	PartID       string                     `json:"part_id"`
+	Files        []*submissions.Attachment  `json:"files"`
+	MaxScore     float64                    `json:"max_score"`
+	Limits       assignments.ResourceLimits `json:"limits"`
 }
 
+const DefaultPageSize = 25
+
 const (
-	MsgSubmissionFilesError  = "required submission file not present or has a wrong name"
-	MsgBlankTitleError       = "title can't be blank"
-	MsgBlankDescriptionError = "description can't be blank"
-	MsgInvalidGraderURLError = "grader url is not a valid url"
-	MsgBlankContainerError   = "container can't be blank"
-	MsgBlankPartIDError      = "part id can't be blank"
-	MsgUniqueTitleError      = "title already exists"
-	MsgInvalidFilesError     = "files have invalid format"
+	MsgBlankTitleError        = "title can't be blank"
+	MsgBlankCourseError       = "course can't be blank"
+	MsgForbiddenCourseError   = "you can't add assignments to this course"
+	MsgBlankDescriptionError  = "description can't be blank"
+	MsgInvalidGraderURLError  = "grader url is not a valid url"
+	MsgBlankContainerError    = "container can't be blank"
+	MsgBlankPartIDError       = "part id can't be blank"
+	MsgUniqueTitleError       = "title already exists"
+	MsgInvalidFilesError      = "files have invalid format"
+	MsgInvalidMaxScoreError   = "max score should be a non-negative number"
+	MsgInvalidLimitsError     = "resource limits should be non-negative numbers"
+	MsgSubmissionClosedError  = "assignment is not open for submissions"
+	MsgInvalidDatesError      = "dates have invalid format"
+	MsgDatesOrderError        = "dates should go in order: opens, due, late due"
+	MsgLateWithoutDueError    = "late due date requires a due date"
+	MsgNotValidatedError      = "assignment can't be published until the reference solution passes validation"
+	MsgSolutionFilesError     = "solution files should match the assignment files"
+	MsgSubmissionArchiveError = "submission should be a single zip or tar.gz archive"
+	MsgNestedFilesError       = "files in directories can be submitted only in archives"
+	MsgMissingFileError       = "required file is missing"
+	MsgUnexpectedFileError    = "file doesn't match any of the assignment files"
+	MsgDuplicateFileError     = "file is submitted more than once"
+	MsgFileSizeError          = "file is larger than %s"
+	MsgFileExtensionError     = "file should have one of the extensions: %s"
+	MsgGitDisabledError       = "assignment doesn't accept submissions from git repositories"
+	MsgEmptyRepositoryError   = "repository has no files of the assignment"
+	MsgInvalidAttemptsError   = "max attempts and cooldown should be non-negative numbers"
+	MsgNoAttemptsLeftError    = "you have used all attempts of the assignment"
+	MsgCooldownError          = "the next submission is allowed in %s"
+	MsgRateLimitError         = "too many submissions, try again in %s"
+	MsgInProgressError        = "the previous submission is still being graded"
+	MsgIdempotencyKeyError    = "idempotency key should be at most 255 characters"
+	MsgUsedKeyError           = "idempotency key was used for another assignment"
+	MsgRegradeError           = "submission is still being graded"
+	MsgPublishedGraderError   = "grading settings of a published assignment can't be changed, unpublish it first"
 )
 
+const MaxIdempotencyKeyLength = 255
+
 type AssignmentsServiceInterface interface {
 	GetAll(*users.User) ([]*assignments.Assignment, error)
 	GetByID(int64) (*assignments.Assignment, error)
 	GetByIDByCreator(int64, *users.User) (*assignments.Assignment, error)
+	GetByIDByStaff(int64, *users.User) (*assignments.Assignment, error)
 	GetByUserID(int64) ([]*assignments.Assignment, error)
-	Submit(*users.User, *assignments.Assignment, []*SubmissionFile) (*submissions.Submission, error)
-	Create(*assignments.Assignment) (*assignments.Assignment, error)
-	Update(*assignments.Assignment) (*assignments.Assignment, error)
+	GetCatalog(*users.User) ([]*assignments.CatalogEntry, error)
+	GetCatalogPage(user *users.User, page int) ([]*assignments.CatalogEntry, *utils.PaginationData, error)
+	Submit(
+		user *users.User,
+		assignment *assignments.Assignment,
+		files []*SubmissionFile,
+		idempotencyKey string,
+	) (submission *submissions.Submission, reused bool, err error)
+	SubmitGit(
+		user *users.User,
+		assignment *assignments.Assignment,
+		repositoryURL, ref, idempotencyKey string,
+	) (submission *submissions.Submission, reused bool, err error)
+	AttemptsLeft(*users.User, *assignments.Assignment) (int, error)
+	Regrade(*assignments.Assignment, *submissions.Submission) error
+	RegradeAll(assignment *assignments.Assignment, latestOnly bool) (int, error)
+	Create(*users.User, *assignments.Assignment, *Solutions) (*assignments.Assignment, error)
+	Update(*users.User, *assignments.Assignment, *Solutions) (*assignments.Assignment, error)
+	GetValidations(assignmentID int64) ([]*assignments.Validation, error)
+	OpenAttachment(*submissions.Attachment) (io.ReadCloser, error)
 	ValidateAssignment(*assignments.Assignment) error
 }
 
@@ -73,6 +175,9 @@ func NewAssignmentsService(
 	queueCh *amqp.Channel,
 	queueName string,
 	jwtSecret string,
+	git gitsource.FetcherInterface,
+	rateLimit RateLimit,
+	taskLifetime time.Duration,
 ) AssignmentsServiceInterface {
 	return &AssignmentsService{
 		WebhookFullURL:  webhookFullURL,
@@ -82,109 +187,618 @@ func NewAssignmentsService(
 		QueueCh:         queueCh,
 		QueueName:       queueName,
 		JwtSecret:       jwtSecret,
+		Git:             git,
+		RateLimit:       rateLimit,
+		TaskLifetime:    taskLifetime,
 	}
 }
 
 func (s *AssignmentsService) GetAll(user *users.User) ([]*assignments.Assignment, error) {
 	// TODO: Pagination handling
-	return s.Repo.GetAllByCreator(user.ID, 100, 0)
+	if user.IsAdmin() {
+		return s.Repo.GetAll(100, 0)
+	}
+
+	return s.Repo.GetAllByStaff(user.ID, 100, 0)
 }
 
 func (s *AssignmentsService) GetByID(id int64) (*assignments.Assignment, error) {
 	return s.Repo.GetByID(id)
 }
 
+// GetByIDByCreator returns the assignment if the user may edit it
 func (s *AssignmentsService) GetByIDByCreator(id int64, user *users.User) (*assignments.Assignment, error) {
+	if user.IsAdmin() {
+		return s.Repo.GetByID(id)
+	}
+
 	return s.Repo.GetByIDByCreator(id, user.ID)
 }
 
+// GetByIDByStaff returns the assignment if the user may view its submissions
+func (s *AssignmentsService) GetByIDByStaff(id int64, user *users.User) (*assignments.Assignment, error) {
+	if user.IsAdmin() {
+		return s.Repo.GetByID(id)
+	}
+
+	return s.Repo.GetByIDByStaff(id, user.ID)
+}
+
 func (s *AssignmentsService) GetByUserID(userID int64) ([]*assignments.Assignment, error) {
 	return s.Repo.GetByUserID(userID, 100, 0)
 }
 
+func (s *AssignmentsService) GetCatalog(user *users.User) ([]*assignments.CatalogEntry, error) {
+	// TODO: Pagination handling
+	return s.Repo.GetPublished(user.ID, 100, 0)
+}
+
+func (s *AssignmentsService) GetCatalogPage(
+	user *users.User,
+	page int,
+) ([]*assignments.CatalogEntry, *utils.PaginationData, error) {
+	totalCount, err := s.Repo.GetPublishedCount(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	paginationData := utils.NewPaginationData(page, DefaultPageSize, totalCount)
+	offset := utils.GetPageOffset(paginationData.CurrentPage, DefaultPageSize)
+	result, err := s.Repo.GetPublished(user.ID, DefaultPageSize, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, paginationData, nil
+}
+
+// Submit checks the files and sends them to the runner, the submission
+// created earlier with the same idempotency key is returned instead.
+// Reused is set when an earlier submission with the key or the same files is returned.
 func (s *AssignmentsService) Submit(
 	user *users.User,
 	assignment *assignments.Assignment,
 	files []*SubmissionFile,
-) (submission *submissions.Submission, err error) {
-	err = checkSubmissionFiles(assignment.Files, files)
+	idempotencyKey string,
+) (*submissions.Submission, bool, error) {
+	submission, err := s.idempotentSubmission(user, assignment, idempotencyKey)
+	if submission != nil || err != nil {
+		return submission, submission != nil, err
+	}
+
+	now := time.Now()
+	if !assignment.IsOpen(now) {
+		return nil, false, &AssignmentValidationError{MsgSubmissionClosedError}
+	}
+
+	err = checkFiles(assignment, files)
 	if err != nil {
+		return nil, false, err
+	}
+
+	return s.submitFiles(user, assignment, files, now, idempotencyKey, nil)
+}
+
+// SubmitGit submits the files of the repository at the ref, the default
+// branch is used when the ref is empty and the commit is recorded on the submission
+func (s *AssignmentsService) SubmitGit(
+	user *users.User,
+	assignment *assignments.Assignment,
+	repositoryURL string,
+	ref string,
+	idempotencyKey string,
+) (*submissions.Submission, bool, error) {
+	submission, err := s.idempotentSubmission(user, assignment, idempotencyKey)
+	if submission != nil || err != nil {
+		return submission, submission != nil, err
+	}
+
+	now := time.Now()
+	if !assignment.IsOpen(now) {
+		return nil, false, &AssignmentValidationError{MsgSubmissionClosedError}
+	}
+	if !assignment.AllowGit {
+		return nil, false, &AssignmentValidationError{MsgGitDisabledError}
+	}
+	// The rest of the limits are checked once the files are known since
+	// identical files are reused, but repositories aren't fetched without limits
+	if err = s.checkRateLimit(user, now); err != nil {
+		return nil, false, err
+	}
+
+	repositoryURL = strings.TrimSpace(repositoryURL)
+	snapshot, err := s.Git.Fetch(repositoryURL, strings.TrimSpace(ref))
+	if err != nil {
+		if fetchErr, ok := err.(*gitsource.FetchError); ok {
+			return nil, false, &AssignmentValidationError{fetchErr.Message}
+		}
+		return nil, false, err
+	}
+	files, err := snapshotFiles(assignment, snapshot)
+	if err != nil {
+		return nil, false, err
+	}
+	if err = checkFiles(assignment, files); err != nil {
+		return nil, false, err
+	}
+
+	source := gitsource.RedactURL(repositoryURL)
+	link := func(sqlExec repo.SqlQueryable, submission *submissions.Submission) error {
+		submission.RepositoryURL = source
+		submission.CommitSHA = snapshot.SHA
+		return s.SubmissionsRepo.SetSource(sqlExec, submission.ID, source, snapshot.SHA)
+	}
+
+	return s.submitFiles(user, assignment, files, now, idempotencyKey, link)
+}
+
+// idempotentSubmission returns the submission created earlier with the key, nil
+// is returned when the key is empty or wasn't used yet
+func (s *AssignmentsService) idempotentSubmission(
+	user *users.User,
+	assignment *assignments.Assignment,
+	idempotencyKey string,
+) (*submissions.Submission, error) {
+	if len(idempotencyKey) == 0 {
+		return nil, nil
+	}
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, &AssignmentValidationError{MsgIdempotencyKeyError}
+	}
+	submission, err := s.SubmissionsRepo.GetByIdempotencyKey(user.ID, idempotencyKey)
+	if err != nil || submission == nil {
 		return nil, err
 	}
+	if submission.AssignmentID != assignment.ID {
+		return nil, &AssignmentValidationError{MsgUsedKeyError}
+	}
+
+	return submission, nil
+}
+
+// submitFiles returns the latest graded submission of the user with the same
+// content so that its verdict is reused without spending an attempt, otherwise
+// a new submission is created within the limits
+func (s *AssignmentsService) submitFiles(
+	user *users.User,
+	assignment *assignments.Assignment,
+	files []*SubmissionFile,
+	now time.Time,
+	idempotencyKey string,
+	link func(repo.SqlQueryable, *submissions.Submission) error,
+) (*submissions.Submission, bool, error) {
+	hash, err := contentHash(assignment, files)
+	if err != nil {
+		return nil, false, err
+	}
+	late := assignment.IsLate(now)
+	previous, err := s.SubmissionsRepo.GetByContentHash(assignment.ID, user.ID, hash)
+	if err != nil {
+		return nil, false, err
+	}
+	if isReusable(previous, late) {
+		return previous, true, nil
+	}
+	if err = s.checkLimits(user, assignment, now); err != nil {
+		return nil, false, err
+	}
+
+	submission, err := s.createSubmission(
+		user.ID,
+		assignment,
+		files,
+		late,
+		s.limitsGuard(user, assignment, now),
+		func(sqlExec repo.SqlQueryable, submission *submissions.Submission) error {
+			if err := s.SubmissionsRepo.SetKeys(sqlExec, submission.ID, hash, idempotencyKey); err != nil {
+				return err
+			}
+			if link == nil {
+				return nil
+			}
+			return link(sqlExec, submission)
+		},
+	)
+	if errors.Is(err, submissions.ErrIdempotencyKeyUsed) {
+		// A concurrent request with the same key created the submission first,
+		// e.g. a double-click on the form
+		existing, keyErr := s.idempotentSubmission(user, assignment, idempotencyKey)
+		if keyErr != nil {
+			return nil, false, keyErr
+		}
+		if existing != nil {
+			return existing, true, nil
+		}
+	}
+
+	return submission, false, err
+}
+
+// isReusable is false for submissions still being graded and for late ones
+// when the submission would be on time now, e.g. after the deadline moved
+func isReusable(previous *submissions.Submission, late bool) bool {
+	return previous != nil && previous.Status != submissions.InProgress && (!previous.Late || late)
+}
+
+// AttemptsLeft returns the number of submissions the user can still make,
+// it's meaningful only for assignments with max attempts
+func (s *AssignmentsService) AttemptsLeft(user *users.User, assignment *assignments.Assignment) (int, error) {
+	if assignment.MaxAttempts <= 0 {
+		return 0, nil
+	}
+	count, err := s.SubmissionsRepo.GetAttemptsCount(assignment.ID, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if count >= assignment.MaxAttempts {
+		return 0, nil
+	}
+
+	return assignment.MaxAttempts - count, nil
+}
+
+// checkLimits enforces the max attempts and the cooldown of the assignment
+// and the rate limit of the user over all assignments
+func (s *AssignmentsService) checkLimits(user *users.User, assignment *assignments.Assignment, now time.Time) error {
+	if assignment.MaxAttempts > 0 {
+		left, err := s.AttemptsLeft(user, assignment)
+		if err != nil {
+			return err
+		}
+		if left == 0 {
+			return &AssignmentValidationError{MsgNoAttemptsLeftError}
+		}
+	}
+	if assignment.CooldownSeconds > 0 || !assignment.AllowParallel {
+		last, err := s.SubmissionsRepo.GetByUserAssignment(assignment.ID, user.ID, 1, 0)
+		if err != nil {
+			return err
+		}
+		if len(last) > 0 {
+			if !assignment.AllowParallel && last[0].Status == submissions.InProgress &&
+				now.Sub(last[0].CreatedAt) < inProgressTimeout(assignment) {
+				return &SubmissionLimitError{Message: MsgInProgressError}
+			}
+			if wait := last[0].CreatedAt.Add(assignment.Cooldown()).Sub(now); wait > 0 {
+				return limitError(MsgCooldownError, wait)
+			}
+		}
+	}
+
+	return s.checkRateLimit(user, now)
+}
+
+// limitsGuard repeats the limits concurrent submissions could pass together in
+// the transaction of the new submission, submissions of the user wait for it.
+// Submissions of the user committed before the lock are visible to the checks.
+func (s *AssignmentsService) limitsGuard(
+	user *users.User,
+	assignment *assignments.Assignment,
+	now time.Time,
+) func(repo.SqlQueryable) error {
+	if assignment.AllowParallel && assignment.MaxAttempts <= 0 {
+		return nil
+	}
+
+	return func(sqlExec repo.SqlQueryable) error {
+		if err := s.SubmissionsRepo.LockUser(sqlExec, user.ID); err != nil {
+			return err
+		}
+		if assignment.MaxAttempts > 0 {
+			left, err := s.AttemptsLeft(user, assignment)
+			if err != nil {
+				return err
+			}
+			if left == 0 {
+				return &AssignmentValidationError{MsgNoAttemptsLeftError}
+			}
+		}
+		if assignment.AllowParallel {
+			return nil
+		}
+		count, err := s.SubmissionsRepo.GetInProgressCount(
+			sqlExec,
+			assignment.ID,
+			user.ID,
+			now.Add(-inProgressTimeout(assignment)),
+		)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return &SubmissionLimitError{Message: MsgInProgressError}
+		}
+
+		return nil
+	}
+}
+
+// checkRateLimit enforces the rate limit of the user over all assignments
+func (s *AssignmentsService) checkRateLimit(user *users.User, now time.Time) error {
+	if s.RateLimit.Submissions <= 0 {
+		return nil
+	}
+	count, oldest, err := s.SubmissionsRepo.GetRecentCountByUser(user.ID, now.Add(-s.RateLimit.Window))
+	if err != nil {
+		return err
+	}
+	if count >= s.RateLimit.Submissions {
+		return limitError(MsgRateLimitError, oldest.Add(s.RateLimit.Window).Sub(now))
+	}
+
+	return nil
+}
+
+// inProgressTimeout is how long a submission in progress blocks new ones of
+// the student, longer runs are considered lost
+func inProgressTimeout(assignment *assignments.Assignment) time.Duration {
+	timeout := assignment.Limits.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = assignments.DefaultTimeoutSeconds
+	}
+
+	return 2*time.Duration(timeout)*time.Second + 10*time.Minute
+}
+
+// contentHash fingerprints the files together with the grading settings so that
+// verdicts aren't reused once the grader changes, contents are kept readable
+func contentHash(assignment *assignments.Assignment, files []*SubmissionFile) (string, error) {
+	settings, err := json.Marshal([]any{
+		assignment.GraderURL, assignment.Container, assignment.PartID, assignment.MaxScore,
+		assignment.Limits, assignment.Files, assignment.Archive,
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write(settings)
+
+	sorted := append([]*SubmissionFile{}, files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, file := range sorted {
+		data, err := io.ReadAll(file.Content)
+		if err != nil {
+			return "", err
+		}
+		file.Content = bytes.NewReader(data)
+		fmt.Fprintf(hash, "\x00%s\x00%d\x00", file.Name, len(data))
+		hash.Write(data)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// limitError rounds the wait up to whole seconds
+func limitError(message string, wait time.Duration) error {
+	wait = (wait + time.Second - 1).Truncate(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+
+	return &SubmissionLimitError{Message: fmt.Sprintf(message, wait), RetryAfter: wait}
+}
+
+// createSubmission saves the files and sends them to the runner, link is
+// called within the transaction which creates the submission
+func (s *AssignmentsService) createSubmission(
+	userID int64,
+	assignment *assignments.Assignment,
+	files []*SubmissionFile,
+	late bool,
+	guard func(repo.SqlQueryable) error,
+	link func(repo.SqlQueryable, *submissions.Submission) error,
+) (submission *submissions.Submission, err error) {
 	newAttachments := []*attachments.Attachment{}
 
 	txn, err := s.SubmissionsRepo.CreateTxn()
 	if err != nil {
 		return nil, err
 	}
-	defer func(newAttachments []*attachments.Attachment) {
+	committed := false
+	defer func() {
 		p := recover()
 
-		rollbackErr := txn.Rollback()
-		if rollbackErr != nil {
-			log.Printf("Error while reverting db changes: %v", rollbackErr)
-		}
+		// Uploads of a committed submission are kept even if it couldn't be queued
+		if !committed {
+			rollbackErr := txn.Rollback()
+			if rollbackErr != nil {
+				log.Printf("Error while reverting db changes: %v", rollbackErr)
+			}
 
-		for _, att := range newAttachments {
-			attErr := s.AttachRepo.Destroy(att.URL)
-			if attErr != nil {
-				log.Printf("Error while reverting attachment creation: %v", err)
+			for _, att := range newAttachments {
+				attErr := s.AttachRepo.Destroy(att.URL)
+				if attErr != nil {
+					log.Printf("Error while reverting attachment creation: %v", attErr)
+				}
 			}
 		}
 
 		if p != nil {
 			panic(p)
 		}
-	}(newAttachments)
+	}()
 
-	submission, err = s.SubmissionsRepo.Create(txn, user.ID, assignment.ID)
+	if guard != nil {
+		if err = guard(txn); err != nil {
+			return nil, err
+		}
+	}
+	submission, err = s.SubmissionsRepo.Create(txn, userID, assignment.ID, assignment.MaxScore, late)
 	if err != nil {
 		return nil, err
 	}
+	if link != nil {
+		if err = link(txn, submission); err != nil {
+			return nil, err
+		}
+	}
 
 	pathPrefix := fmt.Sprintf("submissions/%d", submission.ID)
+	submissionAttachments := []*submissions.Attachment{}
 	for _, file := range files {
 		attachment, err := s.AttachRepo.Create(pathPrefix, file.Name, file.Content)
 		if err != nil {
 			return nil, err
 		}
 		newAttachments = append(newAttachments, attachment)
+		submissionAttachments = append(
+			submissionAttachments,
+			&submissions.Attachment{URL: attachment.URL, Name: attachment.Name, Entries: file.Entries},
+		)
 	}
 
-	submissionAttachments, err := s.SubmissionsRepo.CreateSubmissionAttachments(
+	submissionAttachments, err = s.SubmissionsRepo.CreateSubmissionAttachments(
 		txn,
 		submission.ID,
-		newAttachments,
+		submissionAttachments,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	token, err := utils.AccessToken(s.JwtSecret, strconv.FormatInt(submission.ID, 10))
+	submission.Attachments = submissionAttachments
+	data, err := s.taskData(assignment, submission)
 	if err != nil {
 		return nil, err
 	}
+
+	err = txn.Commit()
+	if err != nil {
+		return
+	}
+	committed = true
+
+	err = s.publishTask(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Regrade archives the current result of the submission and sends it to
+// the runner again with the stored attachments, submissions in progress
+// can't be regraded
+func (s *AssignmentsService) Regrade(assignment *assignments.Assignment, submission *submissions.Submission) error {
+	if submission.Status == submissions.InProgress {
+		return &AssignmentValidationError{MsgRegradeError}
+	}
+
+	return s.restart(assignment, submission)
+}
+
+// restart sends the submission to the runner again regardless of its status
+func (s *AssignmentsService) restart(assignment *assignments.Assignment, submission *submissions.Submission) error {
+	submissionAttachments, err := s.SubmissionsRepo.GetSubmissionAttachments(submission.ID)
+	if err != nil {
+		return err
+	}
 	submission.Attachments = submissionAttachments
+	submission.MaxScore = assignment.MaxScore
+
+	txn, err := s.SubmissionsRepo.CreateTxn()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rollbackErr := txn.Rollback(); rollbackErr != nil {
+			log.Printf("Error while reverting db changes: %v", rollbackErr)
+		}
+	}()
+
+	err = s.SubmissionsRepo.ArchiveResult(txn, submission.ID)
+	if err != nil {
+		return err
+	}
+	err = s.SubmissionsRepo.Reset(txn, submission)
+	if err != nil {
+		return err
+	}
+	// The task carries the new run number so that results of the previous run are ignored
+	data, err := s.taskData(assignment, submission)
+	if err != nil {
+		return err
+	}
+	err = txn.Commit()
+	if err != nil {
+		return err
+	}
+
+	submission.Status = submissions.InProgress
+	submission.Details = ""
+	submission.Score = 0
+	submission.Stdout = ""
+	submission.Stderr = ""
+	submission.ExitCode = nil
+	submission.Duration = 0
+	submission.TestResults = nil
+
+	return s.publishTask(data)
+}
+
+// RegradeAll regrades submissions of the assignment which already got their
+// results and returns the number of queued submissions
+func (s *AssignmentsService) RegradeAll(assignment *assignments.Assignment, latestOnly bool) (int, error) {
+	submissionsList, err := s.SubmissionsRepo.GetAllByAssignment(assignment.ID, latestOnly)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, submission := range submissionsList {
+		if submission.Status == submissions.InProgress {
+			continue
+		}
+		if err = s.Regrade(assignment, submission); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+func (s *AssignmentsService) taskData(
+	assignment *assignments.Assignment,
+	submission *submissions.Submission,
+) ([]byte, error) {
+	token, err := utils.ExpiringAccessToken(
+		s.JwtSecret,
+		submissions.RunTokenID(submission.ID, submission.RunNumber),
+		s.TaskLifetime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Download URLs may expire so they are created for every task instead of being stored
+	files := make([]*submissions.Attachment, 0, len(submission.Attachments))
+	for _, att := range submission.Attachments {
+		downloadURL, err := s.AttachRepo.DownloadURL(att.URL)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &submissions.Attachment{ID: att.ID, URL: downloadURL, Name: att.Name, Archive: att.Archive})
+	}
 	task := &SubmitAssignmentTask{
 		GraderURL:    assignment.GraderURL,
 		Container:    assignment.Container,
 		PartID:       assignment.PartID,
-		Files:        submission.Attachments,
+		Files:        files,
 		SubmissionID: submission.ID,
+		MaxScore:     assignment.MaxScore,
+		Limits:       assignment.Limits,
 		AccessToken:  token,
-		WebhookURL:   fmt.Sprint(s.WebhookFullURL, submission.ID),
-	}
-	data, err := json.Marshal(task)
-	if err != nil {
-		return nil, err
+		WebhookURL:   fmt.Sprintf("%s%d?run=%d", s.WebhookFullURL, submission.ID, submission.RunNumber),
+		LogsURL:      fmt.Sprintf("%s%d/logs?run=%d", s.WebhookFullURL, submission.ID, submission.RunNumber),
 	}
 
-	err = txn.Commit()
-	if err != nil {
-		return
-	}
+	return json.Marshal(task)
+}
 
-	err = s.QueueCh.PublishWithContext(
+func (s *AssignmentsService) publishTask(data []byte) error {
+	return s.QueueCh.PublishWithContext(
 		context.Background(),
 		"",
 		s.QueueName,
@@ -196,14 +810,13 @@ func (s *AssignmentsService) Submit(
 			Body:         data,
 		},
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return
 }
 
-func (s *AssignmentsService) Create(assignment *assignments.Assignment) (*assignments.Assignment, error) {
+func (s *AssignmentsService) Create(
+	user *users.User,
+	assignment *assignments.Assignment,
+	solutions *Solutions,
+) (*assignments.Assignment, error) {
 	foundAssignment, err := s.Repo.GetByTitle(assignment.Title)
 	if err != nil {
 		return nil, err
@@ -212,40 +825,189 @@ func (s *AssignmentsService) Create(assignment *assignments.Assignment) (*assign
 		return nil, &AssignmentValidationError{MsgUniqueTitleError}
 	}
 
-	for i, file := range assignment.Files {
-		assignment.Files[i] = strings.TrimSpace(file)
+	for _, file := range assignment.Files {
+		if file != nil {
+			file.Pattern = strings.TrimSpace(file.Pattern)
+		}
 	}
 
 	err = s.ValidateAssignment(assignment)
 	if err != nil {
 		return nil, err
 	}
+	err = checkSolutions(assignment, solutions)
+	if err != nil {
+		return nil, err
+	}
+	// Solutions can be validated only after the assignment is saved
+	if assignment.Published {
+		return nil, &AssignmentValidationError{MsgNotValidatedError}
+	}
 
-	return s.Repo.Create(
-		assignment.CreatorID,
-		assignment.Title,
-		assignment.Description,
-		assignment.GraderURL,
-		assignment.Container,
-		assignment.PartID,
-		assignment.Files,
-	)
+	assignment, err = s.Repo.Create(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignment, s.validateSaved(user, assignment, solutions, false)
 }
 
-func (s *AssignmentsService) Update(assignment *assignments.Assignment) (*assignments.Assignment, error) {
-	for i, file := range assignment.Files {
-		assignment.Files[i] = strings.TrimSpace(file)
+// Update runs validations again when the grading settings are changed, the
+// assignment can be published only with the passed validations and its grading
+// settings can't be changed while it's published
+func (s *AssignmentsService) Update(
+	user *users.User,
+	assignment *assignments.Assignment,
+	solutions *Solutions,
+) (*assignments.Assignment, error) {
+	previous, err := s.Repo.GetByID(assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("assignment #%d not found", assignment.ID)
+	}
+
+	for _, file := range assignment.Files {
+		if file != nil {
+			file.Pattern = strings.TrimSpace(file.Pattern)
+		}
 	}
 
-	err := s.ValidateAssignment(assignment)
+	err = s.ValidateAssignment(assignment)
 	if err != nil {
 		return nil, err
 	}
+	err = checkSolutions(assignment, solutions)
+	if err != nil {
+		return nil, err
+	}
+
+	rerun := graderChanged(previous, assignment)
+	if rerun && assignment.Published && previous.Published {
+		return nil, &AssignmentValidationError{MsgPublishedGraderError}
+	}
+	if assignment.Published && !previous.Published {
+		if rerun || solutions.uploaded() {
+			return nil, &AssignmentValidationError{MsgNotValidatedError}
+		}
+		validations, err := s.Repo.GetValidations(assignment.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !assignments.Validated(validations) {
+			return nil, &AssignmentValidationError{MsgNotValidatedError}
+		}
+	}
 
-	return s.Repo.Update(assignment)
+	assignment, err = s.Repo.Update(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignment, s.validateSaved(user, assignment, solutions, rerun)
+}
+
+func (s *AssignmentsService) GetValidations(assignmentID int64) ([]*assignments.Validation, error) {
+	return s.Repo.GetValidations(assignmentID)
+}
+
+func (s *AssignmentsService) OpenAttachment(attachment *submissions.Attachment) (io.ReadCloser, error) {
+	return s.AttachRepo.Open(attachment.URL)
+}
+
+// validateSaved validates the assignment which is already saved, so errors
+// are reported as SolutionsError instead of failing the whole request
+func (s *AssignmentsService) validateSaved(
+	user *users.User,
+	assignment *assignments.Assignment,
+	solutions *Solutions,
+	rerun bool,
+) error {
+	if err := s.validate(user, assignment, solutions, rerun); err != nil {
+		log.Printf("Can't validate solutions of assignment #%d: %v", assignment.ID, err)
+		return &SolutionsError{Err: err}
+	}
+
+	return nil
+}
+
+// validate sends the uploaded solutions to the runner, the previous ones are
+// graded again when rerun is set
+func (s *AssignmentsService) validate(
+	user *users.User,
+	assignment *assignments.Assignment,
+	solutions *Solutions,
+	rerun bool,
+) error {
+	uploads := solutions.byKind()
+	for kind, files := range uploads {
+		kind := kind
+		_, err := s.createSubmission(
+			user.ID,
+			assignment,
+			files,
+			false,
+			nil,
+			func(sqlExec repo.SqlQueryable, submission *submissions.Submission) error {
+				return s.Repo.SetValidation(sqlExec, assignment.ID, kind, submission.ID)
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if !rerun {
+		return nil
+	}
+
+	validations, err := s.Repo.GetValidations(assignment.ID)
+	if err != nil {
+		return err
+	}
+	for _, validation := range validations {
+		if _, ok := uploads[validation.Kind]; ok {
+			continue
+		}
+		// Runs in progress are graded with the previous settings, so they are restarted
+		// too. The restart starts a new run of the submission and results of the
+		// previous one are ignored, so validations reflect the current settings only
+		if err = s.restart(assignment, validation.Submission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// graderChanged reports whether the settings which affect grading results differ
+func graderChanged(previous, current *assignments.Assignment) bool {
+	return previous.GraderURL != current.GraderURL ||
+		previous.Container != current.Container ||
+		previous.PartID != current.PartID ||
+		previous.MaxScore != current.MaxScore ||
+		previous.Limits != current.Limits ||
+		previous.Archive != current.Archive ||
+		previous.Files.String() != current.Files.String()
+}
+
+func checkSolutions(assignment *assignments.Assignment, solutions *Solutions) error {
+	for _, files := range solutions.byKind() {
+		if err := checkFiles(assignment, files); err != nil {
+			if filesErr, ok := err.(*FilesValidationError); ok {
+				return &AssignmentValidationError{MsgSolutionFilesError + ": " + filesErr.Error()}
+			}
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (s *AssignmentsService) ValidateAssignment(assignment *assignments.Assignment) error {
+	if assignment.CourseID <= 0 {
+		return &AssignmentValidationError{MsgBlankCourseError}
+	}
 	if len(assignment.Title) == 0 {
 		return &AssignmentValidationError{MsgBlankTitleError}
 	}
@@ -261,26 +1023,197 @@ func (s *AssignmentsService) ValidateAssignment(assignment *assignments.Assignme
 	if len(assignment.PartID) == 0 {
 		return &AssignmentValidationError{MsgBlankPartIDError}
 	}
+	if err := validateFiles(assignment); err != nil {
+		return err
+	}
+	if assignment.MaxScore < 0 {
+		return &AssignmentValidationError{MsgInvalidMaxScoreError}
+	}
+	limits := assignment.Limits
+	if limits.MemoryMB < 0 || limits.CPUs < 0 || limits.Pids < 0 || limits.TmpfsMB < 0 || limits.TimeoutSeconds < 0 {
+		return &AssignmentValidationError{MsgInvalidLimitsError}
+	}
+	if assignment.MaxAttempts < 0 || assignment.CooldownSeconds < 0 {
+		return &AssignmentValidationError{MsgInvalidAttemptsError}
+	}
+
+	return validateDates(assignment)
+}
+
+func validateFiles(assignment *assignments.Assignment) error {
+	patterns := map[string]bool{}
 	for _, file := range assignment.Files {
-		if len(file) == 0 {
+		if file == nil {
 			return &AssignmentValidationError{MsgInvalidFilesError}
 		}
+		if err := file.Validate(); err != nil {
+			return &AssignmentValidationError{MsgInvalidFilesError + ": " + err.Error()}
+		}
+		if patterns[file.Pattern] {
+			return &AssignmentValidationError{MsgInvalidFilesError + ": " + file.Pattern + " is duplicated"}
+		}
+		patterns[file.Pattern] = true
+		// Only base names of uploaded files are known
+		if !assignment.Archive && strings.Contains(file.Pattern, "/") {
+			return &AssignmentValidationError{MsgNestedFilesError}
+		}
 	}
 
 	return nil
 }
 
-func checkSubmissionFiles(requiredFiles []string, requestFiles []*SubmissionFile) error {
-	for _, file := range requestFiles {
-		for i, requiredFile := range requiredFiles {
-			if requiredFile == file.Name {
-				break
-			}
+func validateDates(assignment *assignments.Assignment) error {
+	dates := []*time.Time{}
+	for _, date := range []*time.Time{assignment.OpensAt, assignment.DueAt, assignment.LateDueAt} {
+		if date == nil {
+			continue
+		}
+		if date.IsZero() {
+			return &AssignmentValidationError{MsgInvalidDatesError}
+		}
+		dates = append(dates, date)
+	}
+	if assignment.LateDueAt != nil && assignment.DueAt == nil {
+		return &AssignmentValidationError{MsgLateWithoutDueError}
+	}
+	for i := 1; i < len(dates); i++ {
+		if dates[i].Before(*dates[i-1]) {
+			return &AssignmentValidationError{MsgDatesOrderError}
+		}
+	}
 
-			if i == len(requiredFiles)-1 {
-				return &AssignmentValidationError{MsgSubmissionFilesError}
-			}
+	return nil
+}
+
+// checkFiles validates the submitted files against the file specs of the
+// assignment, the archive of an archive assignment is read to check its files
+func checkFiles(assignment *assignments.Assignment, files []*SubmissionFile) error {
+	if !assignment.Archive {
+		submitted := make([]*archives.Entry, len(files))
+		for i, file := range files {
+			submitted[i] = &archives.Entry{Name: file.Name, Size: file.Size}
+		}
+		return checkFileSpecs(assignment.Files, submitted)
+	}
+	if len(files) != 1 {
+		return &AssignmentValidationError{MsgSubmissionArchiveError}
+	}
+
+	archive := files[0]
+	data, err := io.ReadAll(archive.Content)
+	if err != nil {
+		return err
+	}
+	entries, err := archives.Inspect(archive.Name, data, archives.DefaultLimits)
+	if err != nil {
+		if archiveErr, ok := err.(*archives.ArchiveValidationError); ok {
+			return &AssignmentValidationError{archiveErr.Message}
+		}
+		return err
+	}
+	if err = checkFileSpecs(assignment.Files, entries); err != nil {
+		return err
+	}
+	archive.Entries = make([]string, len(entries))
+	for i, entry := range entries {
+		archive.Entries[i] = entry.Name
+	}
+	archive.Content = bytes.NewReader(data)
+
+	return nil
+}
+
+// snapshotFiles picks the files of the assignment from the repository tree,
+// files in directories are kept only for archive assignments which get them
+// packed into a zip named after the commit
+func snapshotFiles(assignment *assignments.Assignment, snapshot *gitsource.Snapshot) ([]*SubmissionFile, error) {
+	tree, err := archives.Read("snapshot.zip", snapshot.Archive, archives.DefaultLimits)
+	if err != nil {
+		if archiveErr, ok := err.(*archives.ArchiveValidationError); ok {
+			return nil, &AssignmentValidationError{archiveErr.Message}
 		}
+		return nil, err
+	}
+
+	picked := []*archives.File{}
+	for _, file := range tree {
+		if !assignment.Archive && strings.Contains(file.Name, "/") {
+			continue
+		}
+		if len(assignment.Files) > 0 && assignment.Files.Find(file.Name) == nil {
+			continue
+		}
+		picked = append(picked, file)
+	}
+	if len(picked) == 0 {
+		return nil, &AssignmentValidationError{MsgEmptyRepositoryError}
+	}
+
+	if assignment.Archive {
+		data, err := archives.WriteZip(picked)
+		if err != nil {
+			return nil, err
+		}
+		name := snapshot.SHA
+		if len(name) > 12 {
+			name = name[:12]
+		}
+		return []*SubmissionFile{{Name: name + ".zip", Content: bytes.NewReader(data), Size: int64(len(data))}}, nil
+	}
+
+	files := make([]*SubmissionFile, len(picked))
+	for i, file := range picked {
+		files[i] = &SubmissionFile{Name: file.Name, Content: bytes.NewReader(file.Content), Size: file.Size}
+	}
+
+	return files, nil
+}
+
+// checkFileSpecs matches every file with the first spec of its name and
+// collects the problems of all files, any files are accepted without specs
+func checkFileSpecs(specs assignments.FileSpecs, files []*archives.Entry) error {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	fileErrors := []*FileError{}
+	matched := map[*assignments.FileSpec]bool{}
+	seen := map[string]bool{}
+	for _, file := range files {
+		if seen[file.Name] {
+			fileErrors = append(fileErrors, &FileError{Name: file.Name, Message: MsgDuplicateFileError})
+			continue
+		}
+		seen[file.Name] = true
+
+		spec := specs.Find(file.Name)
+		if spec == nil {
+			fileErrors = append(fileErrors, &FileError{Name: file.Name, Message: MsgUnexpectedFileError})
+			continue
+		}
+		matched[spec] = true
+		if !spec.AllowedExtension(file.Name) {
+			fileErrors = append(fileErrors, &FileError{
+				Pattern: spec.Pattern,
+				Name:    file.Name,
+				Message: fmt.Sprintf(MsgFileExtensionError, strings.Join(spec.Extensions, ", ")),
+			})
+		}
+		if spec.MaxSize > 0 && file.Size > spec.MaxSize {
+			fileErrors = append(fileErrors, &FileError{
+				Pattern: spec.Pattern,
+				Name:    file.Name,
+				Message: fmt.Sprintf(MsgFileSizeError, spec.MaxSizeText()),
+			})
+		}
+	}
+	for _, spec := range specs {
+		if !spec.Optional && !matched[spec] {
+			fileErrors = append(fileErrors, &FileError{Pattern: spec.Pattern, Name: spec.Pattern, Message: MsgMissingFileError})
+		}
+	}
+	if len(fileErrors) > 0 {
+		return &FilesValidationError{fileErrors}
 	}
 
 	return nil
@@ -1,12 +1,25 @@
 package services
 
 import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/pkg/archives"
 	"github.com/maxshend/grader/pkg/assignments"
+	"github.com/maxshend/grader/pkg/attachments"
+	"github.com/maxshend/grader/pkg/gitsource"
+	"github.com/maxshend/grader/pkg/submissions"
+	"github.com/maxshend/grader/pkg/users"
+	"github.com/maxshend/grader/pkg/utils"
 )
 
 func TestAssignmentsGetByID(t *testing.T) {
@@ -14,7 +27,7 @@ func TestAssignmentsGetByID(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := assignments.NewMockRepositoryInterface(ctrl)
-	service := NewAssignmentsService("", repo, nil, nil, nil, "", "")
+	service := NewAssignmentsService("", repo, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
 	var id int64 = 1
 
 	t.Run("success", func(t *testing.T) {
@@ -38,3 +51,973 @@ func TestAssignmentsGetByID(t *testing.T) {
 		}
 	})
 }
+
+func TestAssignmentsGetByIDByStaff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := assignments.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", repo, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
+	assignment := &assignments.Assignment{ID: 1}
+
+	t.Run("admin", func(t *testing.T) {
+		repo.EXPECT().GetByID(assignment.ID).Return(assignment, nil)
+
+		result, err := service.GetByIDByStaff(assignment.ID, &users.User{ID: 2, Role: users.AdminRole})
+		if err != nil {
+			t.Fatalf("expected to not have errors got %v", err)
+		}
+		if result != assignment {
+			t.Errorf("expect to have %+v, got %+v", assignment, result)
+		}
+	})
+
+	t.Run("assistant", func(t *testing.T) {
+		repo.EXPECT().GetByIDByStaff(assignment.ID, int64(3)).Return(nil, nil)
+
+		result, err := service.GetByIDByStaff(assignment.ID, &users.User{ID: 3, Role: users.AssistantRole})
+		if err != nil {
+			t.Fatalf("expected to not have errors got %v", err)
+		}
+		if result != nil {
+			t.Errorf("expect to not have an assignment, got %+v", result)
+		}
+	})
+}
+
+func TestAssignmentsRegradeAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", nil, nil, submissionsRepo, nil, "", "", nil, RateLimit{}, time.Hour)
+	assignment := &assignments.Assignment{ID: 1}
+
+	t.Run("skips submissions in progress", func(t *testing.T) {
+		submissionsRepo.EXPECT().GetAllByAssignment(assignment.ID, true).Return(
+			[]*submissions.Submission{{ID: 1, Status: submissions.InProgress}},
+			nil,
+		)
+
+		count, err := service.RegradeAll(assignment, true)
+		if err != nil {
+			t.Fatalf("expected to not have errors got %v", err)
+		}
+		if count != 0 {
+			t.Errorf("expected to not regrade submissions, got %d", count)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		submissionsRepo.EXPECT().GetAllByAssignment(assignment.ID, false).Return(nil, fmt.Errorf("db_error"))
+
+		_, err := service.RegradeAll(assignment, false)
+		if err == nil {
+			t.Fatalf("expected to have errors")
+		}
+	})
+
+	t.Run("attachments error", func(t *testing.T) {
+		submissionsRepo.EXPECT().GetAllByAssignment(assignment.ID, false).Return(
+			[]*submissions.Submission{{ID: 2, Status: submissions.Fail}},
+			nil,
+		)
+		submissionsRepo.EXPECT().GetSubmissionAttachments(int64(2)).Return(nil, fmt.Errorf("db_error"))
+
+		count, err := service.RegradeAll(assignment, false)
+		if err == nil {
+			t.Fatalf("expected to have errors")
+		}
+		if count != 0 {
+			t.Errorf("expected to not regrade submissions, got %d", count)
+		}
+	})
+}
+
+func TestAssignmentsRegradeInProgress(t *testing.T) {
+	service := NewAssignmentsService("", nil, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
+
+	err := service.Regrade(&assignments.Assignment{ID: 1}, &submissions.Submission{ID: 1, Status: submissions.InProgress})
+	if _, ok := err.(*AssignmentValidationError); !ok || err.Error() != MsgRegradeError {
+		t.Errorf("expected %q, got %v", MsgRegradeError, err)
+	}
+}
+
+func TestAssignmentsTaskDataRun(t *testing.T) {
+	service := &AssignmentsService{
+		WebhookFullURL: "http://web/webhooks/submissions/",
+		JwtSecret:      "secret",
+		TaskLifetime:   time.Hour,
+	}
+
+	data, err := service.taskData(&assignments.Assignment{ID: 1}, &submissions.Submission{ID: 7, RunNumber: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &SubmitAssignmentTask{}
+	if err = json.Unmarshal(data, task); err != nil {
+		t.Fatal(err)
+	}
+
+	if task.WebhookURL != "http://web/webhooks/submissions/7?run=2" {
+		t.Errorf("expected the webhook url of the run, got %s", task.WebhookURL)
+	}
+	if task.LogsURL != "http://web/webhooks/submissions/7/logs?run=2" {
+		t.Errorf("expected the logs url of the run, got %s", task.LogsURL)
+	}
+	if err = utils.CheckAccessToken("secret", task.AccessToken, submissions.RunTokenID(7, 2)); err != nil {
+		t.Errorf("expected the token of the run, got %v", err)
+	}
+	if err = utils.CheckAccessToken("secret", task.AccessToken, submissions.RunTokenID(7, 1)); err == nil {
+		t.Errorf("expected the token to not be valid for the previous run")
+	}
+}
+
+func TestAssignmentsSubmitClosed(t *testing.T) {
+	service := NewAssignmentsService("", nil, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
+	assignment := &assignments.Assignment{ID: 1, Files: assignments.FileSpecs{{Pattern: "main.go"}}}
+
+	_, _, err := service.Submit(&users.User{ID: 1}, assignment, []*SubmissionFile{{Name: "main.go"}}, "")
+	if _, ok := err.(*AssignmentValidationError); !ok {
+		t.Fatalf("expected to have validation error, got %v", err)
+	}
+	if err.Error() != MsgSubmissionClosedError {
+		t.Errorf("expected %q, got %q", MsgSubmissionClosedError, err.Error())
+	}
+}
+
+func TestAssignmentsCheckLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := &AssignmentsService{SubmissionsRepo: submissionsRepo, RateLimit: RateLimit{Submissions: 10, Window: time.Hour}}
+	user := &users.User{ID: 1}
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		Title      string
+		Assignment *assignments.Assignment
+		Mock       func()
+		Error      string
+		RetryAfter time.Duration
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "allowed",
+			Assignment: &assignments.Assignment{ID: 1, MaxAttempts: 3, CooldownSeconds: 60},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetAttemptsCount(int64(1), user.ID).Return(2, nil)
+				submissionsRepo.EXPECT().GetByUserAssignment(int64(1), user.ID, 1, 0).Return(
+					[]*submissions.Submission{{Status: submissions.Success, CreatedAt: now.Add(-time.Minute)}},
+					nil,
+				)
+				submissionsRepo.EXPECT().GetRecentCountByUser(user.ID, now.Add(-time.Hour)).Return(9, now.Add(-time.Minute), nil)
+			},
+		},
+		{
+			Title:      "no attempts left",
+			Assignment: &assignments.Assignment{ID: 1, MaxAttempts: 3},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetAttemptsCount(int64(1), user.ID).Return(3, nil)
+			},
+			Error: MsgNoAttemptsLeftError,
+		},
+		{
+			Title:      "cooldown",
+			Assignment: &assignments.Assignment{ID: 1, CooldownSeconds: 60},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetByUserAssignment(int64(1), user.ID, 1, 0).Return(
+					[]*submissions.Submission{{Status: submissions.Fail, CreatedAt: now.Add(-10500 * time.Millisecond)}},
+					nil,
+				)
+			},
+			Error:      "the next submission is allowed in 50s",
+			RetryAfter: 50 * time.Second,
+		},
+		{
+			Title:      "in progress",
+			Assignment: &assignments.Assignment{ID: 1},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetByUserAssignment(int64(1), user.ID, 1, 0).Return(
+					[]*submissions.Submission{{Status: submissions.InProgress, CreatedAt: now.Add(-time.Minute)}},
+					nil,
+				)
+			},
+			Error: MsgInProgressError,
+		},
+		{
+			Title:      "lost in progress",
+			Assignment: &assignments.Assignment{ID: 1, Limits: assignments.ResourceLimits{TimeoutSeconds: 60}},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetByUserAssignment(int64(1), user.ID, 1, 0).Return(
+					[]*submissions.Submission{{Status: submissions.InProgress, CreatedAt: now.Add(-time.Hour)}},
+					nil,
+				)
+				submissionsRepo.EXPECT().GetRecentCountByUser(user.ID, now.Add(-time.Hour)).Return(1, now.Add(-time.Hour), nil)
+			},
+		},
+		{
+			Title:      "parallel",
+			Assignment: &assignments.Assignment{ID: 1, AllowParallel: true},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetRecentCountByUser(user.ID, now.Add(-time.Hour)).Return(1, now.Add(-time.Minute), nil)
+			},
+		},
+		{
+			Title:      "rate limit",
+			Assignment: &assignments.Assignment{ID: 1, AllowParallel: true},
+			Mock: func() {
+				submissionsRepo.EXPECT().GetRecentCountByUser(user.ID, now.Add(-time.Hour)).Return(10, now.Add(-45*time.Minute), nil)
+			},
+			Error:      "too many submissions, try again in 15m0s",
+			RetryAfter: 15 * time.Minute,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			testCase.Mock()
+
+			err := service.checkLimits(user, testCase.Assignment, now)
+			if len(testCase.Error) == 0 {
+				if err != nil {
+					t.Fatalf("expected to not have errors, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != testCase.Error {
+				t.Fatalf("expected to have %q, got %v", testCase.Error, err)
+			}
+			if limitErr, ok := err.(*SubmissionLimitError); testCase.RetryAfter > 0 && (!ok || limitErr.RetryAfter != testCase.RetryAfter) {
+				t.Errorf("expected to retry after %s, got %v", testCase.RetryAfter, err)
+			}
+		})
+	}
+}
+
+func TestAssignmentsSubmitIdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", nil, nil, submissionsRepo, nil, "", "", nil, RateLimit{}, time.Hour)
+	user := &users.User{ID: 1}
+	// The assignment is closed, so only the earlier submission can be returned
+	assignment := &assignments.Assignment{ID: 1}
+
+	type testCase struct {
+		Title string
+		Key   string
+		Mock  func()
+		Want  *submissions.Submission
+		Error string
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "used key",
+			Key:   "key-1",
+			Mock: func() {
+				submissionsRepo.EXPECT().GetByIdempotencyKey(user.ID, "key-1").Return(&submissions.Submission{ID: 5, AssignmentID: 1}, nil)
+			},
+			Want: &submissions.Submission{ID: 5, AssignmentID: 1},
+		},
+		{
+			Title: "key of another assignment",
+			Key:   "key-2",
+			Mock: func() {
+				submissionsRepo.EXPECT().GetByIdempotencyKey(user.ID, "key-2").Return(&submissions.Submission{ID: 6, AssignmentID: 2}, nil)
+			},
+			Error: MsgUsedKeyError,
+		},
+		{
+			Title: "new key",
+			Key:   "key-3",
+			Mock: func() {
+				submissionsRepo.EXPECT().GetByIdempotencyKey(user.ID, "key-3").Return(nil, nil)
+			},
+			Error: MsgSubmissionClosedError,
+		},
+		{
+			Title: "long key",
+			Key:   string(bytes.Repeat([]byte("k"), MaxIdempotencyKeyLength+1)),
+			Mock:  func() {},
+			Error: MsgIdempotencyKeyError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			testCase.Mock()
+
+			got, _, err := service.Submit(user, assignment, []*SubmissionFile{{Name: "main.go"}}, testCase.Key)
+			if len(testCase.Error) > 0 {
+				if err == nil || err.Error() != testCase.Error {
+					t.Fatalf("expected to have %q, got %v", testCase.Error, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Errorf("expected %+v, got %+v", testCase.Want, got)
+			}
+		})
+	}
+}
+
+func TestAssignmentsSubmitConcurrentKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", nil, nil, submissionsRepo, nil, "", "", nil, RateLimit{}, time.Hour)
+	user := &users.User{ID: 1}
+	assignment := &assignments.Assignment{ID: 1, Published: true, AllowParallel: true}
+	existing := &submissions.Submission{ID: 5, AssignmentID: 1, Status: submissions.InProgress}
+	txn, dbMock := newTxn(t)
+	dbMock.ExpectRollback()
+
+	// The first request commits its submission while the second one waits on the key
+	gomock.InOrder(
+		submissionsRepo.EXPECT().GetByIdempotencyKey(user.ID, "key").Return(nil, nil),
+		submissionsRepo.EXPECT().GetByContentHash(assignment.ID, user.ID, gomock.Any()).Return(nil, nil),
+		submissionsRepo.EXPECT().CreateTxn().Return(txn, nil),
+		submissionsRepo.EXPECT().Create(txn, user.ID, assignment.ID, assignment.MaxScore, false).Return(
+			&submissions.Submission{ID: 6, AssignmentID: 1},
+			nil,
+		),
+		submissionsRepo.EXPECT().SetKeys(txn, int64(6), gomock.Any(), "key").Return(submissions.ErrIdempotencyKeyUsed),
+		submissionsRepo.EXPECT().GetByIdempotencyKey(user.ID, "key").Return(existing, nil),
+	)
+
+	got, reused, err := service.Submit(
+		user,
+		assignment,
+		[]*SubmissionFile{{Name: "main.go", Content: bytes.NewReader([]byte("package main"))}},
+		"key",
+	)
+	if err != nil {
+		t.Fatalf("expected to not have errors, got %v", err)
+	}
+	if !reused || got != existing {
+		t.Errorf("expected to return the submission of the first request, got %+v", got)
+	}
+	if err = dbMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAssignmentsSubmitGuard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", nil, nil, submissionsRepo, nil, "", "", nil, RateLimit{}, time.Hour)
+	user := &users.User{ID: 1}
+
+	type testCase struct {
+		Title      string
+		Assignment *assignments.Assignment
+		Mock       func(*sql.Tx)
+		Error      string
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "in progress",
+			Assignment: &assignments.Assignment{ID: 1, Published: true},
+			Mock: func(txn *sql.Tx) {
+				// A concurrent submission wasn't committed when the limits were checked
+				submissionsRepo.EXPECT().GetByUserAssignment(int64(1), user.ID, 1, 0).Return(nil, nil)
+				submissionsRepo.EXPECT().LockUser(txn, user.ID).Return(nil)
+				submissionsRepo.EXPECT().GetInProgressCount(txn, int64(1), user.ID, gomock.Any()).Return(1, nil)
+			},
+			Error: MsgInProgressError,
+		},
+		{
+			Title:      "attempts",
+			Assignment: &assignments.Assignment{ID: 1, Published: true, AllowParallel: true, MaxAttempts: 1},
+			Mock: func(txn *sql.Tx) {
+				// A concurrent submission spent the last attempt after the limits were checked
+				gomock.InOrder(
+					submissionsRepo.EXPECT().GetAttemptsCount(int64(1), user.ID).Return(0, nil),
+					submissionsRepo.EXPECT().LockUser(txn, user.ID).Return(nil),
+					submissionsRepo.EXPECT().GetAttemptsCount(int64(1), user.ID).Return(1, nil),
+				)
+			},
+			Error: MsgNoAttemptsLeftError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			txn, dbMock := newTxn(t)
+			dbMock.ExpectRollback()
+			submissionsRepo.EXPECT().GetByContentHash(testCase.Assignment.ID, user.ID, gomock.Any()).Return(nil, nil)
+			submissionsRepo.EXPECT().CreateTxn().Return(txn, nil)
+			testCase.Mock(txn)
+
+			_, _, err := service.Submit(
+				user,
+				testCase.Assignment,
+				[]*SubmissionFile{{Name: "main.go", Content: bytes.NewReader([]byte("package main"))}},
+				"",
+			)
+			if err == nil || err.Error() != testCase.Error {
+				t.Fatalf("expected to have error %q, got %v", testCase.Error, err)
+			}
+			if err = dbMock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAssignmentsSubmitIdentical(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", nil, nil, submissionsRepo, nil, "", "", nil, RateLimit{}, time.Hour)
+	user := &users.User{ID: 1}
+	// No attempts are left, so only the previous submission can be returned
+	assignment := &assignments.Assignment{ID: 1, Published: true, AllowParallel: true, MaxAttempts: 1}
+	hash, err := contentHash(assignment, []*SubmissionFile{{Name: "main.go", Content: bytes.NewReader([]byte("package main"))}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		Title    string
+		Previous *submissions.Submission
+		Reused   bool
+	}
+
+	testCases := []*testCase{
+		{
+			Title:    "graded",
+			Previous: &submissions.Submission{ID: 3, AssignmentID: 1, Status: submissions.Success},
+			Reused:   true,
+		},
+		{
+			Title:    "in progress",
+			Previous: &submissions.Submission{ID: 3, AssignmentID: 1, Status: submissions.InProgress},
+		},
+		{
+			Title:    "late before the deadline moved",
+			Previous: &submissions.Submission{ID: 3, AssignmentID: 1, Status: submissions.Fail, Late: true},
+		},
+		{
+			Title: "new files",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			submissionsRepo.EXPECT().GetByContentHash(assignment.ID, user.ID, hash).Return(testCase.Previous, nil)
+			if !testCase.Reused {
+				submissionsRepo.EXPECT().GetAttemptsCount(assignment.ID, user.ID).Return(1, nil)
+			}
+
+			got, reused, err := service.Submit(
+				user,
+				assignment,
+				[]*SubmissionFile{{Name: "main.go", Content: bytes.NewReader([]byte("package main"))}},
+				"",
+			)
+			if !testCase.Reused {
+				if err == nil || err.Error() != MsgNoAttemptsLeftError {
+					t.Fatalf("expected to have %q, got %v", MsgNoAttemptsLeftError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if !reused || got != testCase.Previous {
+				t.Errorf("expected to reuse the previous submission, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestAssignmentsCreateSubmissionCleanup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	attachRepo := attachments.NewMockRepositoryInterface(ctrl)
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := &AssignmentsService{AttachRepo: attachRepo, SubmissionsRepo: submissionsRepo}
+	assignment := &assignments.Assignment{ID: 1}
+	txn, dbMock := newTxn(t)
+	dbMock.ExpectRollback()
+
+	submissionsRepo.EXPECT().CreateTxn().Return(txn, nil)
+	submissionsRepo.EXPECT().Create(txn, int64(1), assignment.ID, assignment.MaxScore, false).Return(
+		&submissions.Submission{ID: 3, AssignmentID: 1},
+		nil,
+	)
+	attachRepo.EXPECT().Create("submissions/3", "main.go", gomock.Any()).Return(
+		&attachments.Attachment{URL: "submissions/3/main.go", Name: "main.go"},
+		nil,
+	)
+	submissionsRepo.EXPECT().CreateSubmissionAttachments(txn, int64(3), gomock.Any()).Return(nil, fmt.Errorf("db_error"))
+	attachRepo.EXPECT().Destroy("submissions/3/main.go").Return(nil)
+
+	_, err := service.createSubmission(
+		1,
+		assignment,
+		[]*SubmissionFile{{Name: "main.go", Content: bytes.NewReader([]byte("package main"))}},
+		false,
+		nil,
+		nil,
+	)
+	if err == nil {
+		t.Fatalf("expected to have errors")
+	}
+	if err = dbMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func newTxn(t *testing.T) (*sql.Tx, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	dbMock.ExpectBegin()
+	txn, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return txn, dbMock
+}
+
+func TestContentHash(t *testing.T) {
+	hash := func(assignment *assignments.Assignment, contents ...string) string {
+		files := []*SubmissionFile{}
+		for i := 0; i < len(contents); i += 2 {
+			files = append(files, &SubmissionFile{Name: contents[i], Content: bytes.NewReader([]byte(contents[i+1]))})
+		}
+		result, err := contentHash(assignment, files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, file := range files {
+			if data, _ := io.ReadAll(file.Content); string(data) != contents[i*2+1] {
+				t.Fatalf("expected the content of %s to be readable again", file.Name)
+			}
+		}
+
+		return result
+	}
+	assignment := &assignments.Assignment{GraderURL: "http://grader/1"}
+	base := hash(assignment, "a.go", "package a", "b.go", "package b")
+
+	if hash(assignment, "b.go", "package b", "a.go", "package a") != base {
+		t.Errorf("expected the order of files to not change the hash")
+	}
+	if hash(assignment, "a.go", "package a", "b.go", "package c") == base {
+		t.Errorf("expected the content to change the hash")
+	}
+	if hash(assignment, "a.go", "package ab.go", "", "package b") == base {
+		t.Errorf("expected the file names to change the hash")
+	}
+	if hash(&assignments.Assignment{GraderURL: "http://grader/2"}, "a.go", "package a", "b.go", "package b") == base {
+		t.Errorf("expected the grading settings to change the hash")
+	}
+}
+
+func TestAssignmentsCreatePublished(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := assignments.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", repo, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
+	assignment := validAssignment()
+	assignment.Published = true
+
+	repo.EXPECT().GetByTitle(assignment.Title).Return(nil, nil)
+
+	_, err := service.Create(&users.User{ID: 1}, assignment, nil)
+	if err == nil || err.Error() != MsgNotValidatedError {
+		t.Errorf("expected to have %q, got %v", MsgNotValidatedError, err)
+	}
+}
+
+func TestAssignmentsCreateSolutionsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := assignments.NewMockRepositoryInterface(ctrl)
+	submissionsRepo := submissions.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", repo, nil, submissionsRepo, nil, "", "", nil, RateLimit{}, time.Hour)
+	assignment := validAssignment()
+	saved := validAssignment()
+	saved.ID = 5
+
+	repo.EXPECT().GetByTitle(assignment.Title).Return(nil, nil)
+	repo.EXPECT().Create(assignment).Return(saved, nil)
+	submissionsRepo.EXPECT().CreateTxn().Return(nil, fmt.Errorf("db_error"))
+
+	got, err := service.Create(
+		&users.User{ID: 1},
+		assignment,
+		&Solutions{Reference: []*SubmissionFile{{Name: "main.go", Content: bytes.NewReader(nil)}}},
+	)
+	if _, ok := err.(*SolutionsError); !ok {
+		t.Fatalf("expected to have solutions error, got %v", err)
+	}
+	if got != saved {
+		t.Errorf("expected to return the saved assignment, got %+v", got)
+	}
+}
+
+func TestAssignmentsUpdatePublish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := assignments.NewMockRepositoryInterface(ctrl)
+	service := NewAssignmentsService("", repo, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
+	user := &users.User{ID: 1}
+	passed := []*assignments.Validation{
+		{Kind: assignments.ReferenceSolution, Submission: &submissions.Submission{Status: submissions.Success}},
+	}
+
+	type testCase struct {
+		Title     string
+		Published bool
+		Container string
+		Setup     func(*assignments.Assignment)
+		Error     string
+	}
+
+	testCases := []*testCase{
+		{
+			Title: "validated",
+			Setup: func(assignment *assignments.Assignment) {
+				repo.EXPECT().GetValidations(assignment.ID).Return(passed, nil)
+				repo.EXPECT().Update(assignment).Return(assignment, nil)
+			},
+		},
+		{
+			Title: "not validated",
+			Setup: func(assignment *assignments.Assignment) {
+				repo.EXPECT().GetValidations(assignment.ID).Return([]*assignments.Validation{}, nil)
+			},
+			Error: MsgNotValidatedError,
+		},
+		{
+			Title:     "grader changed",
+			Container: "new_container",
+			Error:     MsgNotValidatedError,
+		},
+		{
+			Title:     "grader of a published assignment changed",
+			Published: true,
+			Container: "new_container",
+			Error:     MsgPublishedGraderError,
+		},
+		{
+			Title:     "published assignment",
+			Published: true,
+			Setup: func(assignment *assignments.Assignment) {
+				repo.EXPECT().Update(assignment).Return(assignment, nil)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			previous := validAssignment()
+			previous.Published = testCase.Published
+			assignment := validAssignment()
+			assignment.Published = true
+			if len(testCase.Container) > 0 {
+				assignment.Container = testCase.Container
+			}
+
+			repo.EXPECT().GetByID(assignment.ID).Return(previous, nil)
+			if testCase.Setup != nil {
+				testCase.Setup(assignment)
+			}
+
+			_, err := service.Update(user, assignment, nil)
+			if len(testCase.Error) == 0 && err != nil {
+				t.Errorf("expected to not have errors, got %v", err)
+			} else if len(testCase.Error) > 0 && (err == nil || err.Error() != testCase.Error) {
+				t.Errorf("expected to have %q, got %v", testCase.Error, err)
+			}
+		})
+	}
+}
+
+func validAssignment() *assignments.Assignment {
+	return &assignments.Assignment{
+		ID:          1,
+		CourseID:    1,
+		Title:       "Title",
+		Description: "Description",
+		GraderURL:   "http://runner/api/v1/grader",
+		Container:   "container",
+		PartID:      "part",
+		Files:       assignments.FileSpecs{{Pattern: "main.go"}},
+	}
+}
+
+func TestValidateDates(t *testing.T) {
+	first := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	invalid := time.Time{}
+
+	type testCase struct {
+		Title      string
+		Assignment *assignments.Assignment
+		Want       string
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "without dates",
+			Assignment: &assignments.Assignment{},
+		},
+		{
+			Title:      "ordered dates",
+			Assignment: &assignments.Assignment{OpensAt: &first, DueAt: &second, LateDueAt: &second},
+		},
+		{
+			Title:      "invalid date",
+			Assignment: &assignments.Assignment{DueAt: &invalid},
+			Want:       MsgInvalidDatesError,
+		},
+		{
+			Title:      "late without due date",
+			Assignment: &assignments.Assignment{LateDueAt: &second},
+			Want:       MsgLateWithoutDueError,
+		},
+		{
+			Title:      "due before opens",
+			Assignment: &assignments.Assignment{OpensAt: &second, DueAt: &first},
+			Want:       MsgDatesOrderError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			err := validateDates(testCase.Assignment)
+			if len(testCase.Want) == 0 {
+				if err != nil {
+					t.Fatalf("expected to not have errors got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != testCase.Want {
+				t.Errorf("expected %q, got %v", testCase.Want, err)
+			}
+		})
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	zipData := func(names ...string) []byte {
+		buf := &bytes.Buffer{}
+		w := zip.NewWriter(buf)
+		for _, name := range names {
+			part, _ := w.Create(name)
+			part.Write([]byte("package main"))
+		}
+		w.Close()
+
+		return buf.Bytes()
+	}
+
+	type testCase struct {
+		name       string
+		assignment *assignments.Assignment
+		files      []*SubmissionFile
+		entries    []string
+		err        string
+	}
+	files := assignments.FileSpecs{
+		{Pattern: "main.go"},
+		{Pattern: "*_test.go", Optional: true},
+		{Pattern: "*", MaxSize: 10, Extensions: []string{".go", ".mod"}},
+	}
+	archive := &assignments.Assignment{
+		Files:   assignments.FileSpecs{{Pattern: "*.go"}, {Pattern: "src/**/*.rb"}},
+		Archive: true,
+	}
+	cases := []testCase{
+		{
+			name:       "files",
+			assignment: &assignments.Assignment{Files: files},
+			files:      []*SubmissionFile{{Name: "main.go", Size: 100}, {Name: "util.go", Size: 10}, {Name: "go.mod"}},
+		},
+		{
+			name:       "any files without specs",
+			assignment: &assignments.Assignment{},
+			files:      []*SubmissionFile{{Name: "notes.txt"}},
+		},
+		{
+			name:       "unknown file",
+			assignment: &assignments.Assignment{Files: assignments.FileSpecs{{Pattern: "main.go"}}},
+			files:      []*SubmissionFile{{Name: "other.go"}},
+			err:        "other.go: " + MsgUnexpectedFileError + "; main.go: " + MsgMissingFileError,
+		},
+		{
+			name:       "missing glob",
+			assignment: &assignments.Assignment{Files: files},
+			files:      []*SubmissionFile{{Name: "main.go"}, {Name: "main_test.go"}},
+			err:        "*: " + MsgMissingFileError,
+		},
+		{
+			name:       "too large",
+			assignment: &assignments.Assignment{Files: files},
+			files:      []*SubmissionFile{{Name: "main.go"}, {Name: "util.go", Size: 11}},
+			err:        "util.go: file is larger than 10B",
+		},
+		{
+			name:       "extension",
+			assignment: &assignments.Assignment{Files: files},
+			files:      []*SubmissionFile{{Name: "main.go"}, {Name: "notes.txt"}},
+			err:        "notes.txt: file should have one of the extensions: .go, .mod",
+		},
+		{
+			name:       "duplicated",
+			assignment: &assignments.Assignment{Files: files},
+			files:      []*SubmissionFile{{Name: "main.go"}, {Name: "main.go"}, {Name: "util.go"}},
+			err:        "main.go: " + MsgDuplicateFileError,
+		},
+		{
+			name:       "archive",
+			assignment: archive,
+			files:      []*SubmissionFile{{Name: "project.zip", Content: bytes.NewReader(zipData("main.go", "src/app.rb", "src/lib/util.rb"))}},
+			entries:    []string{"main.go", "src/app.rb", "src/lib/util.rb"},
+		},
+		{
+			name:       "several archives",
+			assignment: archive,
+			files:      []*SubmissionFile{{Name: "a.zip"}, {Name: "b.zip"}},
+			err:        MsgSubmissionArchiveError,
+		},
+		{
+			name:       "not allowed archive file",
+			assignment: archive,
+			files:      []*SubmissionFile{{Name: "project.zip", Content: bytes.NewReader(zipData("main.go", "run.sh"))}},
+			err:        "run.sh: " + MsgUnexpectedFileError + "; src/**/*.rb: " + MsgMissingFileError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkFiles(tc.assignment, tc.files)
+			if len(tc.err) > 0 {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected to have validation error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected not to have errors, got %v", err)
+			}
+			if !reflect.DeepEqual(tc.files[0].Entries, tc.entries) {
+				t.Errorf("expected entries %v, got %v", tc.entries, tc.files[0].Entries)
+			}
+			if tc.assignment.Archive {
+				data, _ := io.ReadAll(tc.files[0].Content)
+				if len(data) == 0 {
+					t.Errorf("expected the archive to be readable again")
+				}
+			}
+		})
+	}
+}
+
+func TestAssignmentsSubmitGitDisabled(t *testing.T) {
+	service := NewAssignmentsService("", nil, nil, nil, nil, "", "", nil, RateLimit{}, time.Hour)
+	assignment := &assignments.Assignment{ID: 1, Published: true}
+
+	_, _, err := service.SubmitGit(&users.User{ID: 1}, assignment, "https://git.example.com/solution.git", "main", "")
+	if err == nil || err.Error() != MsgGitDisabledError {
+		t.Errorf("expected to have %q, got %v", MsgGitDisabledError, err)
+	}
+}
+
+func TestSnapshotFiles(t *testing.T) {
+	tree, err := archives.WriteZip([]*archives.File{
+		{Entry: archives.Entry{Name: "README.md"}, Content: []byte("# Solution")},
+		{Entry: archives.Entry{Name: "main.go"}, Content: []byte("package main")},
+		{Entry: archives.Entry{Name: "src/app.rb"}, Content: []byte("puts 1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &gitsource.Snapshot{SHA: "0123456789abcdef0123456789abcdef01234567", Archive: tree}
+
+	type testCase struct {
+		name       string
+		assignment *assignments.Assignment
+		files      []string
+		entries    []string
+		err        string
+	}
+	cases := []testCase{
+		{
+			name:       "matching files",
+			assignment: &assignments.Assignment{Files: assignments.FileSpecs{{Pattern: "*.go"}}},
+			files:      []string{"main.go"},
+		},
+		{
+			name:       "top level files without specs",
+			assignment: &assignments.Assignment{},
+			files:      []string{"README.md", "main.go"},
+		},
+		{
+			name:       "archive",
+			assignment: &assignments.Assignment{Files: assignments.FileSpecs{{Pattern: "*.go"}, {Pattern: "src/**/*.rb"}}, Archive: true},
+			files:      []string{"0123456789ab.zip"},
+			entries:    []string{"main.go", "src/app.rb"},
+		},
+		{
+			name:       "no matching files",
+			assignment: &assignments.Assignment{Files: assignments.FileSpecs{{Pattern: "*.py"}}},
+			err:        MsgEmptyRepositoryError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := snapshotFiles(tc.assignment, snapshot)
+			if len(tc.err) > 0 {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected to have validation error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected not to have errors, got %v", err)
+			}
+			names := []string{}
+			for _, file := range files {
+				names = append(names, file.Name)
+			}
+			if !reflect.DeepEqual(names, tc.files) {
+				t.Fatalf("expected files %v, got %v", tc.files, names)
+			}
+			if err = checkFiles(tc.assignment, files); err != nil {
+				t.Fatalf("expected the files to be valid, got %v", err)
+			}
+			if tc.assignment.Archive && !reflect.DeepEqual(files[0].Entries, tc.entries) {
+				t.Errorf("expected entries %v, got %v", tc.entries, files[0].Entries)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package services
 
+import (
+	"strings"
+	"time"
+)
+
 type AssignmentValidationError struct {
 	Message string
 }
@@ -7,3 +12,53 @@ type AssignmentValidationError struct {
 func (e *AssignmentValidationError) Error() string {
 	return e.Message
 }
+
+// FileError is the problem of a submitted file or of a missing required one
+type FileError struct {
+	// Pattern is the file spec of the error, it's empty for files which don't match any spec
+	Pattern string
+	Name    string
+	Message string
+}
+
+func (e *FileError) Error() string {
+	return e.Name + ": " + e.Message
+}
+
+// FilesValidationError lists the problems of every file of a submission
+type FilesValidationError struct {
+	Errors []*FileError
+}
+
+func (e *FilesValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fileErr := range e.Errors {
+		messages[i] = fileErr.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// SolutionsError is returned together with the saved assignment when its
+// solutions couldn't be sent to validation, they can be uploaded again
+type SolutionsError struct {
+	Err error
+}
+
+func (e *SolutionsError) Error() string {
+	return "assignment is saved but its solutions can't be validated, upload them again"
+}
+
+func (e *SolutionsError) Unwrap() error {
+	return e.Err
+}
+
+// SubmissionLimitError rejects a submission which is allowed again after RetryAfter
+type SubmissionLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *SubmissionLimitError) Error() string {
+	return e.Message
+}
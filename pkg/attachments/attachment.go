@@ -11,4 +11,7 @@ type Attachment struct {
 type RepositoryInterface interface {
 	Create(pathPrefix, name string, content io.Reader) (*Attachment, error)
 	Destroy(path string) error
+	Open(path string) (io.ReadCloser, error)
+	// DownloadURL returns the URL the runner downloads the attachment from
+	DownloadURL(path string) (string, error)
 }
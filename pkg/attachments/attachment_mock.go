@@ -48,3 +48,47 @@ func (mr *MockRepositoryInterfaceMockRecorder) Create(pathPrefix, name, content
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), pathPrefix, name, content)
 }
+
+// Destroy mocks base method.
+func (m *MockRepositoryInterface) Destroy(path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Destroy", path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Destroy indicates an expected call of Destroy.
+func (mr *MockRepositoryInterfaceMockRecorder) Destroy(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Destroy", reflect.TypeOf((*MockRepositoryInterface)(nil).Destroy), path)
+}
+
+// DownloadURL mocks base method.
+func (m *MockRepositoryInterface) DownloadURL(path string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadURL", path)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadURL indicates an expected call of DownloadURL.
+func (mr *MockRepositoryInterfaceMockRecorder) DownloadURL(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadURL", reflect.TypeOf((*MockRepositoryInterface)(nil).DownloadURL), path)
+}
+
+// Open mocks base method.
+func (m *MockRepositoryInterface) Open(path string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", path)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockRepositoryInterfaceMockRecorder) Open(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockRepositoryInterface)(nil).Open), path)
+}
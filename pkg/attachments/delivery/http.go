@@ -0,0 +1,44 @@
+package delivery
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/maxshend/grader/pkg/attachments/repo"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+// AttachmentsHttpHandler serves local attachments to the runner which downloads
+// them with the signed URLs of the tasks
+type AttachmentsHttpHandler struct {
+	Repo *repo.AttachmentsInmemRepo
+}
+
+func NewAttachmentsHttpHandler(repo *repo.AttachmentsInmemRepo) *AttachmentsHttpHandler {
+	return &AttachmentsHttpHandler{Repo: repo}
+}
+
+func (h *AttachmentsHttpHandler) Download(w http.ResponseWriter, r *http.Request) {
+	location := h.Repo.Host + strings.TrimPrefix(r.URL.Path, "/")
+	if err := h.Repo.Authorize(location, r.URL.Query().Get("token")); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	file, err := h.Repo.Open(location)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			utils.RenderNotFound(w, r)
+		} else {
+			utils.RenderInternalError(w, r, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	utils.SetAttachmentHeaders(w, location)
+	io.Copy(w, file)
+}
@@ -7,18 +7,24 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/maxshend/grader/pkg/attachments"
+	"github.com/maxshend/grader/pkg/utils"
 )
 
+// AttachmentsInmemRepo stores attachments as local files which are served by
+// the web app only with a download token signed with Secret
 type AttachmentsInmemRepo struct {
-	Path string
-	Host string
-	mx   sync.Mutex
+	Path      string
+	Host      string
+	Secret    string
+	URLExpiry time.Duration
+	mx        sync.Mutex
 }
 
-func NewAttachmentsInmemRepo(host string, path string) *AttachmentsInmemRepo {
-	return &AttachmentsInmemRepo{Path: path, Host: host}
+func NewAttachmentsInmemRepo(host, path, secret string, urlExpiry time.Duration) *AttachmentsInmemRepo {
+	return &AttachmentsInmemRepo{Path: path, Host: host, Secret: secret, URLExpiry: urlExpiry}
 }
 
 func (r *AttachmentsInmemRepo) Create(pathPrefix, name string, content io.Reader) (*attachments.Attachment, error) {
@@ -46,13 +52,57 @@ func (r *AttachmentsInmemRepo) Create(pathPrefix, name string, content io.Reader
 }
 
 func (r *AttachmentsInmemRepo) Destroy(path string) error {
-	u, err := url.ParseRequestURI(path)
+	path, err := localPath(path)
 	if err != nil {
 		return err
 	}
+
+	return os.Remove(path)
+}
+
+func (r *AttachmentsInmemRepo) Open(path string) (io.ReadCloser, error) {
+	path, err := localPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+// DownloadURL returns the URL of the attachment with a token which expires after URLExpiry
+func (r *AttachmentsInmemRepo) DownloadURL(path string) (string, error) {
+	token, err := utils.ExpiringAccessToken(r.Secret, downloadTokenID(path), r.URLExpiry)
+	if err != nil {
+		return "", err
+	}
+
+	return path + "?" + url.Values{"token": {token}}.Encode(), nil
+}
+
+// Authorize checks the token of a download URL of the attachment
+func (r *AttachmentsInmemRepo) Authorize(path, token string) error {
+	if len(token) == 0 {
+		return utils.ErrInvalidAccessToken
+	}
+	if err := utils.CheckAccessToken(r.Secret, token, downloadTokenID(path)); err != nil {
+		return utils.ErrInvalidAccessToken
+	}
+
+	return nil
+}
+
+func downloadTokenID(path string) string {
+	return "attachment:" + path
+}
+
+func localPath(path string) (string, error) {
+	u, err := url.ParseRequestURI(path)
+	if err != nil {
+		return "", err
+	}
 	if strings.HasPrefix(u.Path, "/") {
 		path = u.Path[1:]
 	}
 
-	return os.Remove(path)
+	return path, nil
 }
@@ -2,10 +2,13 @@ package repo
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/maxshend/grader/pkg/attachments"
@@ -17,7 +20,7 @@ func TestCreate(t *testing.T) {
 
 	tmpDir := t.TempDir()
 	host := "http://localhost"
-	repo := NewAttachmentsInmemRepo(host, tmpDir)
+	repo := NewAttachmentsInmemRepo(host, tmpDir, "secret", time.Hour)
 
 	rawData := []byte("Hello")
 	prefix := "test_dir"
@@ -50,7 +53,7 @@ func TestDestroy(t *testing.T) {
 	defer ctrl.Finish()
 
 	tmpDir := t.TempDir()
-	repo := NewAttachmentsInmemRepo("/", tmpDir)
+	repo := NewAttachmentsInmemRepo("/", tmpDir, "secret", time.Hour)
 
 	t.Run("success", func(t *testing.T) {
 		rawData := []byte("Hello")
@@ -80,3 +83,82 @@ func TestDestroy(t *testing.T) {
 		}
 	})
 }
+
+func TestOpen(t *testing.T) {
+	repo := NewAttachmentsInmemRepo("/", t.TempDir(), "secret", time.Hour)
+
+	rawData := []byte("Hello")
+	attachment, err := repo.Create("test_dir", "test.txt", bytes.NewReader(rawData))
+	if err != nil {
+		t.Fatalf("error creating test file %v", err)
+	}
+
+	file, err := repo.Open(attachment.URL)
+	if err != nil {
+		t.Fatalf("expected not to have errors, got %v", err)
+	}
+	defer file.Close()
+
+	readData, _ := io.ReadAll(file)
+	if !reflect.DeepEqual(readData, rawData) {
+		t.Fatalf("expected %v got %v", rawData, readData)
+	}
+}
+
+func TestDownloadURL(t *testing.T) {
+	repo := NewAttachmentsInmemRepo("http://localhost/", "uploads", "secret", time.Hour)
+	path := "http://localhost/uploads/submissions/1/main.go"
+
+	downloadURL, err := repo.DownloadURL(path)
+	if err != nil {
+		t.Fatalf("expected not to have errors, got %v", err)
+	}
+	if !strings.HasPrefix(downloadURL, path+"?token=") {
+		t.Fatalf("expected to have a token in %s", downloadURL)
+	}
+	token := strings.TrimPrefix(downloadURL, path+"?token=")
+
+	type testCase struct {
+		name  string
+		path  string
+		token string
+		valid bool
+	}
+	cases := []testCase{
+		{name: "valid", path: path, token: token, valid: true},
+		{name: "empty", path: path, token: "", valid: false},
+		{name: "another file", path: "http://localhost/uploads/submissions/2/main.go", token: token, valid: false},
+		{
+			name:  "another secret",
+			path:  path,
+			token: strings.TrimPrefix(mustDownloadURL(t, NewAttachmentsInmemRepo("", "", "other", time.Hour), path), path+"?token="),
+			valid: false,
+		},
+		{
+			name:  "expired",
+			path:  path,
+			token: strings.TrimPrefix(mustDownloadURL(t, NewAttachmentsInmemRepo("", "", "secret", -time.Minute), path), path+"?token="),
+			valid: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := repo.Authorize(tc.path, tc.token)
+			if tc.valid && err != nil {
+				t.Errorf("expected not to have errors, got %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected to have errors")
+			}
+		})
+	}
+}
+
+func mustDownloadURL(t *testing.T, repo *AttachmentsInmemRepo, path string) string {
+	downloadURL, err := repo.DownloadURL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return downloadURL
+}
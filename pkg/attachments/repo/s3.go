@@ -0,0 +1,333 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxshend/grader/pkg/attachments"
+)
+
+const (
+	// S3Scheme prefixes locations of attachments stored in a bucket: s3://bucket/key
+	S3Scheme = "s3://"
+
+	DefaultS3Region = "us-east-1"
+	// Presigned URLs can't live longer than a week
+	maxS3URLExpiry = 7 * 24 * time.Hour
+
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3AmzDateLayout   = "20060102T150405Z"
+	s3ShortDateLayout = "20060102"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// AttachmentsS3Repo stores attachments in a bucket of an S3-compatible storage
+// with path-style requests signed with AWS Signature Version 4, the runner
+// downloads them with presigned URLs
+type AttachmentsS3Repo struct {
+	Endpoint        *url.URL
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// URLExpiry limits the lifetime of download URLs, tasks waiting in the queue longer can't be graded
+	URLExpiry time.Duration
+	Client    *http.Client
+	now       func() time.Time
+}
+
+func NewAttachmentsS3Repo(
+	endpoint, region, bucket, accessKeyID, secretAccessKey string,
+	urlExpiry time.Duration,
+) (*AttachmentsS3Repo, error) {
+	endpointURL, err := url.ParseRequestURI(endpoint)
+	if err != nil || len(endpointURL.Host) == 0 {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if len(bucket) == 0 || len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+		return nil, fmt.Errorf("S3 bucket and credentials are required")
+	}
+	if urlExpiry <= 0 || urlExpiry > maxS3URLExpiry {
+		return nil, fmt.Errorf("S3 URL expiry should be positive and not longer than %s", maxS3URLExpiry)
+	}
+	if len(region) == 0 {
+		region = DefaultS3Region
+	}
+
+	return &AttachmentsS3Repo{
+		Endpoint:        endpointURL,
+		Region:          region,
+		Bucket:          bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		URLExpiry:       urlExpiry,
+		Client:          &http.Client{Timeout: time.Minute},
+		now:             time.Now,
+	}, nil
+}
+
+func (r *AttachmentsS3Repo) Create(pathPrefix, name string, content io.Reader) (*attachments.Attachment, error) {
+	// The payload is hashed for the signature and S3 requires the content length
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	key := path.Join(pathPrefix, name)
+
+	request, err := http.NewRequest("PUT", r.objectURL(key).String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	if err = r.do(request, hex.EncodeToString(hash[:]), http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return &attachments.Attachment{Name: name, Content: content, URL: S3Scheme + r.Bucket + "/" + key}, nil
+}
+
+func (r *AttachmentsS3Repo) Destroy(location string) error {
+	key, err := r.key(location)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("DELETE", r.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	return r.do(request, emptyPayloadHash(), http.StatusNoContent)
+}
+
+func (r *AttachmentsS3Repo) Open(location string) (io.ReadCloser, error) {
+	key, err := r.key(location)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest("GET", r.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	r.sign(request, emptyPayloadHash(), r.now())
+
+	response, err := r.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("S3 GET %s: unexpected status %d", key, response.StatusCode)
+	}
+
+	return response.Body, nil
+}
+
+// DownloadURL returns a presigned URL which expires after URLExpiry
+func (r *AttachmentsS3Repo) DownloadURL(location string) (string, error) {
+	key, err := r.key(location)
+	if err != nil {
+		return "", err
+	}
+
+	return r.presign("GET", r.objectURL(key), r.URLExpiry, r.now()), nil
+}
+
+func (r *AttachmentsS3Repo) objectURL(key string) *url.URL {
+	result := *r.Endpoint
+	result.Path = path.Join("/", r.Endpoint.Path, r.Bucket, key)
+	result.RawPath = canonicalS3URI(result.Path)
+	result.RawQuery = ""
+
+	return &result
+}
+
+// key returns the object key of an attachment location in the bucket of the repo
+func (r *AttachmentsS3Repo) key(location string) (string, error) {
+	prefix := S3Scheme + r.Bucket + "/"
+	if !strings.HasPrefix(location, prefix) || len(location) == len(prefix) {
+		return "", fmt.Errorf("attachment %q is not stored in bucket %q", location, r.Bucket)
+	}
+
+	return strings.TrimPrefix(location, prefix), nil
+}
+
+func (r *AttachmentsS3Repo) do(request *http.Request, payloadHash string, expectedStatus int) error {
+	r.sign(request, payloadHash, r.now())
+
+	response, err := r.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	// Some S3-compatible storages reply to DELETE with 200
+	if response.StatusCode != expectedStatus && response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("S3 %s %s: unexpected status %d: %s", request.Method, request.URL.Path, response.StatusCode, body)
+	}
+
+	return nil
+}
+
+// sign adds the Authorization header to the request
+func (r *AttachmentsS3Repo) sign(request *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	request.Header.Set("x-amz-date", now.Format(s3AmzDateLayout))
+	request.Header.Set("x-amz-content-sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 request.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           now.Format(s3AmzDateLayout),
+	}
+	signedHeaders, canonicalHeaders := canonicalS3Headers(headers)
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalS3URI(request.URL.Path),
+		canonicalS3Query(request.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := r.scope(now)
+	signature := r.signature(now, scope, canonicalRequest)
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, r.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// presign returns the URL with the signature in the query string
+func (r *AttachmentsS3Repo) presign(method string, target *url.URL, expiry time.Duration, now time.Time) string {
+	now = now.UTC()
+	scope := r.scope(now)
+	query := target.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", r.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(s3AmzDateLayout))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	signedHeaders, canonicalHeaders := canonicalS3Headers(map[string]string{"host": target.Host})
+	canonicalQuery := canonicalS3Query(query)
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalS3URI(target.Path),
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	result := *target
+	result.RawQuery = canonicalQuery + "&X-Amz-Signature=" + r.signature(now, scope, canonicalRequest)
+
+	return result.String()
+}
+
+func (r *AttachmentsS3Repo) scope(now time.Time) string {
+	return strings.Join([]string{now.Format(s3ShortDateLayout), r.Region, "s3", "aws4_request"}, "/")
+}
+
+func (r *AttachmentsS3Repo) signature(now time.Time, scope, canonicalRequest string) string {
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3AmzDateLayout),
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+r.SecretAccessKey), now.Format(s3ShortDateLayout))
+	key = hmacSHA256(key, r.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func emptyPayloadHash() string {
+	hash := sha256.Sum256(nil)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// canonicalS3Headers returns the list of signed headers and their canonical form
+func canonicalS3Headers(headers map[string]string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+func canonicalS3URI(uriPath string) string {
+	if len(uriPath) == 0 {
+		return "/"
+	}
+	segments := strings.Split(uriPath, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalS3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent-encodes everything except the unreserved characters of RFC 3986
+func s3Escape(value string) string {
+	var result strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			result.WriteByte(b)
+		} else {
+			fmt.Fprintf(&result, "%%%02X", b)
+		}
+	}
+
+	return result.String()
+}
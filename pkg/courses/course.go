@@ -0,0 +1,77 @@
+package courses
+
+const (
+	StudentRole int = iota
+	AssistantRole
+	InstructorRole
+)
+
+var roleNames = map[int]string{
+	StudentRole:    "Student",
+	AssistantRole:  "Teaching Assistant",
+	InstructorRole: "Instructor",
+}
+
+type Course struct {
+	ID          int64
+	CreatorID   int64
+	Title       string
+	Description string
+	InviteCode  string
+	// Role of the current user when courses are listed for a member
+	Role int
+}
+
+type Member struct {
+	CourseID int64
+	UserID   int64
+	Username string
+	Role     int
+}
+
+type RepositoryInterface interface {
+	GetAll(limit, offset int) ([]*Course, error)
+	GetByUserID(userID int64, limit, offset int) ([]*Course, error)
+	GetByID(int64) (*Course, error)
+	GetByInviteCode(string) (*Course, error)
+	GetByTitle(string) (*Course, error)
+	Create(*Course) (*Course, error)
+	Update(*Course) (*Course, error)
+	GetMembers(courseID int64) ([]*Member, error)
+	GetMember(courseID, userID int64) (*Member, error)
+	GetMemberByAssignment(assignmentID, userID int64) (*Member, error)
+	AddMember(courseID, userID int64, role int) error
+	RemoveMember(courseID, userID int64) error
+}
+
+func RoleName(role int) string {
+	return roleNames[role]
+}
+
+func RoleNames() map[int]string {
+	result := make(map[int]string, len(roleNames))
+	for role, name := range roleNames {
+		result[role] = name
+	}
+
+	return result
+}
+
+func ValidRole(role int) bool {
+	_, ok := roleNames[role]
+
+	return ok
+}
+
+func (c *Course) RoleName() string {
+	return RoleName(c.Role)
+}
+
+func (m *Member) RoleName() string {
+	return RoleName(m.Role)
+}
+
+// IsStaff reports whether the member teaches the course
+func (m *Member) IsStaff() bool {
+	return m.Role == AssistantRole || m.Role == InstructorRole
+}
@@ -0,0 +1,412 @@
+package delivery
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/courses"
+	"github.com/maxshend/grader/pkg/courses/services"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/users"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+type CoursesHttpHandler struct {
+	Service        services.CoursesServiceInterface
+	SessionManager sessions.HttpSessionManager
+	Views          map[string]*utils.View
+}
+
+type courseFormData struct {
+	Course *courses.Course
+	Errors []string
+	Action string
+}
+
+type courseData struct {
+	Course  *courses.Course
+	Member  *courses.Member
+	Members []*courses.Member
+	Roles   map[int]string
+	Errors  []string
+}
+
+type personalCoursesData struct {
+	Courses    []*courses.Course
+	InviteCode string
+	Errors     []string
+}
+
+func NewCoursesHttpHandler(
+	service services.CoursesServiceInterface,
+	sessionManager sessions.HttpSessionManager,
+	templatesFS fs.FS,
+) (*CoursesHttpHandler, error) {
+	views := make(map[string]*utils.View)
+	var err error
+
+	views["GetAll"], err = utils.NewView(templatesFS, "templates/courses/admin/list.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	views["CourseForm"], err = utils.NewView(templatesFS, "templates/courses/admin/course_form.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	views["Show"], err = utils.NewView(templatesFS, "templates/courses/admin/course.gohtml")
+	if err != nil {
+		return nil, err
+	}
+
+	views["GetAllPersonal"], err = utils.NewView(templatesFS, "templates/courses/list.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	views["ShowPersonal"], err = utils.NewView(templatesFS, "templates/courses/course.gohtml")
+	if err != nil {
+		return nil, err
+	}
+
+	return &CoursesHttpHandler{
+		Service:        service,
+		SessionManager: sessionManager,
+		Views:          views,
+	}, nil
+}
+
+func (h CoursesHttpHandler) PersonalCourses(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	h.renderPersonalCourses(w, r, currentUser, "", nil)
+}
+
+func (h CoursesHttpHandler) Join(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course, err := h.Service.Join(currentUser, r.FormValue("invite_code"))
+	if err != nil {
+		if _, ok := err.(*services.CourseValidationError); ok {
+			h.renderPersonalCourses(w, r, currentUser, r.FormValue("invite_code"), []string{err.Error()})
+		} else {
+			utils.RenderInternalError(w, r, err)
+		}
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/courses/%d", course.ID), http.StatusSeeOther)
+}
+
+// ShowPersonal expects CourseAccessMiddleware to check the membership
+func (h CoursesHttpHandler) ShowPersonal(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+	member := courses.CurrentMember(r)
+	data := &courseData{Course: course, Member: member}
+	if currentUser.IsAdmin() || (member != nil && member.IsStaff()) {
+		data.Members, err = h.Service.GetMembers(course.ID)
+		if err != nil {
+			utils.RenderInternalError(w, r, err)
+			return
+		}
+	}
+
+	err = h.Views["ShowPersonal"].RenderView(w, data, currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func (h CoursesHttpHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	result, err := h.Service.GetAll()
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	err = h.Views["GetAll"].RenderView(
+		w,
+		&struct{ Courses []*courses.Course }{result},
+		currentUser,
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func (h CoursesHttpHandler) New(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	err = h.Views["CourseForm"].RenderView(
+		w,
+		&courseFormData{Course: &courses.Course{}, Action: "create"},
+		currentUser,
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func (h CoursesHttpHandler) Create(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course := &courses.Course{
+		Title:       r.FormValue("title"),
+		Description: r.FormValue("description"),
+	}
+	_, err = h.Service.Create(course, currentUser)
+	if err != nil {
+		h.renderFormError(w, r, currentUser, course, "create", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/courses/%d", course.ID), http.StatusSeeOther)
+}
+
+func (h CoursesHttpHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.Views["CourseForm"].RenderView(
+		w,
+		&courseFormData{Course: course, Action: "update"},
+		currentUser,
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func (h CoursesHttpHandler) Update(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+	course.Title = r.FormValue("title")
+	course.Description = r.FormValue("description")
+
+	_, err = h.Service.Update(course)
+	if err != nil {
+		h.renderFormError(w, r, currentUser, course, "update", err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/courses/%d", course.ID), http.StatusSeeOther)
+}
+
+func (h CoursesHttpHandler) Show(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+
+	h.renderCourse(w, r, currentUser, course, nil)
+}
+
+func (h CoursesHttpHandler) ResetInviteCode(w http.ResponseWriter, r *http.Request) {
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := h.Service.ResetInviteCode(course)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/courses/%d", course.ID), http.StatusSeeOther)
+}
+
+func (h CoursesHttpHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+
+	role, err := strconv.Atoi(r.FormValue("role"))
+	if err != nil {
+		role = -1
+	}
+	err = h.Service.AddMember(course, r.FormValue("username"), role)
+	if err != nil {
+		if _, ok := err.(*services.CourseValidationError); ok {
+			h.renderCourse(w, r, currentUser, course, []string{err.Error()})
+		} else {
+			utils.RenderInternalError(w, r, err)
+		}
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/courses/%d", course.ID), http.StatusSeeOther)
+}
+
+func (h CoursesHttpHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	course, ok := h.course(w, r)
+	if !ok {
+		return
+	}
+
+	params := mux.Vars(r)
+	err := h.Service.RemoveMember(course, courseID(params["user_id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/courses/%d", course.ID), http.StatusSeeOther)
+}
+
+func (h CoursesHttpHandler) course(w http.ResponseWriter, r *http.Request) (*courses.Course, bool) {
+	params := mux.Vars(r)
+	course, err := h.Service.GetByID(courseID(params["id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return nil, false
+	}
+	if course == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+
+	return course, true
+}
+
+func (h CoursesHttpHandler) renderCourse(
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+	course *courses.Course,
+	errors []string,
+) {
+	members, err := h.Service.GetMembers(course.ID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	err = h.Views["Show"].RenderView(
+		w,
+		&courseData{
+			Course:  course,
+			Members: members,
+			Roles:   courses.RoleNames(),
+			Errors:  errors,
+		},
+		currentUser,
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func (h CoursesHttpHandler) renderPersonalCourses(
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+	inviteCode string,
+	errors []string,
+) {
+	result, err := h.Service.GetByUser(currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	err = h.Views["GetAllPersonal"].RenderView(
+		w,
+		&personalCoursesData{Courses: result, InviteCode: inviteCode, Errors: errors},
+		currentUser,
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func (h CoursesHttpHandler) renderFormError(
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+	course *courses.Course,
+	action string,
+	err error,
+) {
+	if _, ok := err.(*services.CourseValidationError); !ok {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	err = h.Views["CourseForm"].RenderView(
+		w,
+		&courseFormData{Course: course, Errors: []string{err.Error()}, Action: action},
+		currentUser,
+	)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
+func courseID(param string) int64 {
+	id, _ := strconv.ParseInt(param, 10, 64)
+
+	return id
+}
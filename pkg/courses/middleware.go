@@ -0,0 +1,68 @@
+package courses
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+type ctxKey int
+
+const MemberKey ctxKey = 1
+
+// CourseAccessMiddleware renders not found unless the current user is a member
+// of the course in the {id} route variable, admins can access any course
+func CourseAccessMiddleware(sm sessions.HttpSessionManager, repo RepositoryInterface) mux.MiddlewareFunc {
+	return memberMiddleware(sm, repo.GetMember)
+}
+
+// AssignmentAccessMiddleware renders not found unless the current user is a member
+// of the course of the assignment in the {id} route variable, admins can access any assignment
+func AssignmentAccessMiddleware(sm sessions.HttpSessionManager, repo RepositoryInterface) mux.MiddlewareFunc {
+	return memberMiddleware(sm, repo.GetMemberByAssignment)
+}
+
+// CurrentMember returns the membership loaded by the access middlewares,
+// it is nil for admins who aren't members of the course
+func CurrentMember(r *http.Request) *Member {
+	member, _ := r.Context().Value(MemberKey).(*Member)
+
+	return member
+}
+
+func memberMiddleware(
+	sm sessions.HttpSessionManager,
+	getMember func(id, userID int64) (*Member, error),
+) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := sm.CurrentUser(r)
+			if err != nil {
+				utils.RenderError(w, r, http.StatusForbidden, sessions.MsgForbiddenUser)
+				return
+			}
+
+			id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+			if err != nil {
+				utils.RenderNotFound(w, r)
+				return
+			}
+			member, err := getMember(id, user.ID)
+			if err != nil {
+				utils.RenderInternalError(w, r, err)
+				return
+			}
+			if member == nil && !user.IsAdmin() {
+				utils.RenderNotFound(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), MemberKey, member)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package courses
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+type testSessionManager struct {
+	sessions.HttpSessionManager
+}
+
+func (sm *testSessionManager) CurrentUser(r *http.Request) (*users.User, error) {
+	user, ok := r.Context().Value(sessions.CurrentUserKey).(*users.User)
+	if !ok {
+		return nil, sessions.ErrUnauthenticatedUser
+	}
+
+	return user, nil
+}
+
+func TestAssignmentAccessMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepositoryInterface(ctrl)
+	member := &Member{CourseID: 1, UserID: 1}
+
+	type testCase struct {
+		Title      string
+		User       *users.User
+		Member     *Member
+		WantStatus int
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "member",
+			User:       &users.User{ID: 1},
+			Member:     member,
+			WantStatus: http.StatusOK,
+		},
+		{
+			Title:      "not a member",
+			User:       &users.User{ID: 2},
+			WantStatus: http.StatusNotFound,
+		},
+		{
+			Title:      "admin",
+			User:       &users.User{ID: 3, Role: users.AdminRole},
+			WantStatus: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			repo.EXPECT().GetMemberByAssignment(int64(5), testCase.User.ID).Return(testCase.Member, nil)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assignments/{id}", func(w http.ResponseWriter, r *http.Request) {
+				if got := CurrentMember(r); got != testCase.Member {
+					t.Errorf("expected member %+v, got %+v", testCase.Member, got)
+				}
+			})
+			router.Use(AssignmentAccessMiddleware(&testSessionManager{}, repo))
+
+			request := httptest.NewRequest("GET", "/assignments/5", nil)
+			request = request.WithContext(context.WithValue(request.Context(), sessions.CurrentUserKey, testCase.User))
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != testCase.WantStatus {
+				t.Errorf("expected status %d, got %d", testCase.WantStatus, response.Code)
+			}
+		})
+	}
+}
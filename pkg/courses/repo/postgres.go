@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/maxshend/grader/pkg/courses"
+)
+
+const courseColumns = "id, creator_id, title, description, invite_code"
+
+type CoursesSQLRepo struct {
+	DB *sql.DB
+}
+
+func NewCoursesSQLRepo(db *sql.DB) *CoursesSQLRepo {
+	return &CoursesSQLRepo{DB: db}
+}
+
+func (r *CoursesSQLRepo) GetAll(limit, offset int) ([]*courses.Course, error) {
+	rows, err := r.DB.Query(
+		"SELECT "+courseColumns+" FROM courses ORDER BY id DESC LIMIT $1 OFFSET $2",
+		limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*courses.Course{}
+	for rows.Next() {
+		course := &courses.Course{}
+		creatorID := sql.NullInt64{}
+		err = rows.Scan(&course.ID, &creatorID, &course.Title, &course.Description, &course.InviteCode)
+		if err != nil {
+			return nil, err
+		}
+		course.CreatorID = creatorID.Int64
+
+		result = append(result, course)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *CoursesSQLRepo) GetByUserID(userID int64, limit, offset int) ([]*courses.Course, error) {
+	rows, err := r.DB.Query(
+		"SELECT courses.id, courses.title, courses.description, course_members.role "+
+			"FROM courses JOIN course_members ON courses.id = course_members.course_id "+
+			"WHERE course_members.user_id = $1 ORDER BY courses.id DESC LIMIT $2 OFFSET $3",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*courses.Course{}
+	for rows.Next() {
+		course := &courses.Course{}
+		err = rows.Scan(&course.ID, &course.Title, &course.Description, &course.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, course)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *CoursesSQLRepo) GetByID(id int64) (*courses.Course, error) {
+	return scanCourse(r.DB.QueryRow(
+		"SELECT "+courseColumns+" FROM courses WHERE id = $1 LIMIT 1",
+		id,
+	))
+}
+
+func (r *CoursesSQLRepo) GetByInviteCode(code string) (*courses.Course, error) {
+	return scanCourse(r.DB.QueryRow(
+		"SELECT "+courseColumns+" FROM courses WHERE invite_code = $1 LIMIT 1",
+		code,
+	))
+}
+
+func (r *CoursesSQLRepo) GetByTitle(title string) (*courses.Course, error) {
+	return scanCourse(r.DB.QueryRow(
+		"SELECT "+courseColumns+" FROM courses WHERE title = $1 LIMIT 1",
+		title,
+	))
+}
+
+func (r *CoursesSQLRepo) Create(course *courses.Course) (*courses.Course, error) {
+	err := r.DB.QueryRow(
+		"INSERT INTO courses (creator_id, title, description, invite_code) VALUES ($1, $2, $3, $4) RETURNING id",
+		course.CreatorID, course.Title, course.Description, course.InviteCode,
+	).Scan(&course.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (r *CoursesSQLRepo) Update(course *courses.Course) (*courses.Course, error) {
+	_, err := r.DB.Exec(
+		"UPDATE courses SET title = $1, description = $2, invite_code = $3 WHERE id = $4",
+		course.Title, course.Description, course.InviteCode, course.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (r *CoursesSQLRepo) GetMembers(courseID int64) ([]*courses.Member, error) {
+	rows, err := r.DB.Query(
+		"SELECT course_members.user_id, users.username, course_members.role "+
+			"FROM course_members JOIN users ON course_members.user_id = users.id "+
+			"WHERE course_members.course_id = $1 ORDER BY course_members.role DESC, users.username",
+		courseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*courses.Member{}
+	for rows.Next() {
+		member := &courses.Member{CourseID: courseID}
+		err = rows.Scan(&member.UserID, &member.Username, &member.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, member)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *CoursesSQLRepo) GetMember(courseID, userID int64) (*courses.Member, error) {
+	return scanMember(r.DB.QueryRow(
+		"SELECT course_id, user_id, role FROM course_members WHERE course_id = $1 AND user_id = $2 LIMIT 1",
+		courseID, userID,
+	))
+}
+
+func (r *CoursesSQLRepo) GetMemberByAssignment(assignmentID, userID int64) (*courses.Member, error) {
+	return scanMember(r.DB.QueryRow(
+		"SELECT course_members.course_id, course_members.user_id, course_members.role "+
+			"FROM course_members JOIN assignments ON course_members.course_id = assignments.course_id "+
+			"WHERE assignments.id = $1 AND course_members.user_id = $2 LIMIT 1",
+		assignmentID, userID,
+	))
+}
+
+// AddMember enrolls the user or changes the role when the user is already a member
+func (r *CoursesSQLRepo) AddMember(courseID, userID int64, role int) error {
+	_, err := r.DB.Exec(
+		"INSERT INTO course_members (course_id, user_id, role) VALUES ($1, $2, $3) "+
+			"ON CONFLICT (course_id, user_id) DO UPDATE SET role = EXCLUDED.role",
+		courseID, userID, role,
+	)
+
+	return err
+}
+
+func (r *CoursesSQLRepo) RemoveMember(courseID, userID int64) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM course_members WHERE course_id = $1 AND user_id = $2",
+		courseID, userID,
+	)
+
+	return err
+}
+
+func scanCourse(row *sql.Row) (*courses.Course, error) {
+	course := &courses.Course{}
+	creatorID := sql.NullInt64{}
+	err := row.Scan(&course.ID, &creatorID, &course.Title, &course.Description, &course.InviteCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	course.CreatorID = creatorID.Int64
+
+	return course, nil
+}
+
+func scanMember(row *sql.Row) (*courses.Member, error) {
+	member := &courses.Member{}
+	err := row.Scan(&member.CourseID, &member.UserID, &member.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return member, nil
+}
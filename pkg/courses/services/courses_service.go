@@ -0,0 +1,217 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+
+	"github.com/maxshend/grader/pkg/courses"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+type CoursesService struct {
+	Repo      courses.RepositoryInterface
+	UsersRepo users.RepositoryInterface
+}
+
+const (
+	MsgBlankTitleError        = "title can't be blank"
+	MsgUniqueTitleError       = "title already exists"
+	MsgInvalidInviteCodeError = "invite code is invalid"
+	MsgUserNotFoundError      = "user not found"
+	MsgInvalidRoleError       = "role is invalid"
+)
+
+const inviteCodeBytes = 5
+
+type CoursesServiceInterface interface {
+	GetAll() ([]*courses.Course, error)
+	GetByUser(*users.User) ([]*courses.Course, error)
+	GetManaged(*users.User) ([]*courses.Course, error)
+	CanManage(user *users.User, courseID int64) (bool, error)
+	GetByID(int64) (*courses.Course, error)
+	Create(course *courses.Course, creator *users.User) (*courses.Course, error)
+	Update(*courses.Course) (*courses.Course, error)
+	ResetInviteCode(*courses.Course) (*courses.Course, error)
+	Join(user *users.User, inviteCode string) (*courses.Course, error)
+	GetMembers(courseID int64) ([]*courses.Member, error)
+	AddMember(course *courses.Course, username string, role int) error
+	RemoveMember(course *courses.Course, userID int64) error
+}
+
+func NewCoursesService(repo courses.RepositoryInterface, usersRepo users.RepositoryInterface) CoursesServiceInterface {
+	return &CoursesService{Repo: repo, UsersRepo: usersRepo}
+}
+
+func (s *CoursesService) GetAll() ([]*courses.Course, error) {
+	// TODO: Pagination handling
+	return s.Repo.GetAll(100, 0)
+}
+
+func (s *CoursesService) GetByUser(user *users.User) ([]*courses.Course, error) {
+	// TODO: Pagination handling
+	return s.Repo.GetByUserID(user.ID, 100, 0)
+}
+
+// GetManaged returns courses the user can add assignments to
+func (s *CoursesService) GetManaged(user *users.User) ([]*courses.Course, error) {
+	if user.IsAdmin() {
+		return s.GetAll()
+	}
+
+	all, err := s.GetByUser(user)
+	if err != nil {
+		return nil, err
+	}
+	result := []*courses.Course{}
+	for _, course := range all {
+		if course.Role == courses.InstructorRole {
+			result = append(result, course)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CoursesService) CanManage(user *users.User, courseID int64) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+
+	member, err := s.Repo.GetMember(courseID, user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return member != nil && member.Role == courses.InstructorRole, nil
+}
+
+func (s *CoursesService) GetByID(id int64) (*courses.Course, error) {
+	return s.Repo.GetByID(id)
+}
+
+// Create makes the creator an instructor of the new course
+func (s *CoursesService) Create(course *courses.Course, creator *users.User) (*courses.Course, error) {
+	err := s.validateCourse(course)
+	if err != nil {
+		return nil, err
+	}
+
+	course.CreatorID = creator.ID
+	course.InviteCode, err = generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	course, err = s.Repo.Create(course)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repo.AddMember(course.ID, creator.ID, courses.InstructorRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (s *CoursesService) Update(course *courses.Course) (*courses.Course, error) {
+	err := s.validateCourse(course)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repo.Update(course)
+}
+
+// ResetInviteCode invalidates the current invite code of the course
+func (s *CoursesService) ResetInviteCode(course *courses.Course) (*courses.Course, error) {
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	course.InviteCode = code
+
+	return s.Repo.Update(course)
+}
+
+// Join enrolls the user as a student, members keep their current role
+func (s *CoursesService) Join(user *users.User, inviteCode string) (*courses.Course, error) {
+	inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+	if len(inviteCode) == 0 {
+		return nil, &CourseValidationError{MsgInvalidInviteCodeError}
+	}
+
+	course, err := s.Repo.GetByInviteCode(inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, &CourseValidationError{MsgInvalidInviteCodeError}
+	}
+
+	member, err := s.Repo.GetMember(course.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil {
+		return course, nil
+	}
+
+	err = s.Repo.AddMember(course.ID, user.ID, courses.StudentRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (s *CoursesService) GetMembers(courseID int64) ([]*courses.Member, error) {
+	return s.Repo.GetMembers(courseID)
+}
+
+func (s *CoursesService) AddMember(course *courses.Course, username string, role int) error {
+	if !courses.ValidRole(role) {
+		return &CourseValidationError{MsgInvalidRoleError}
+	}
+
+	user, err := s.UsersRepo.GetByUsername(strings.TrimSpace(username))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return &CourseValidationError{MsgUserNotFoundError}
+	}
+
+	return s.Repo.AddMember(course.ID, user.ID, role)
+}
+
+func (s *CoursesService) RemoveMember(course *courses.Course, userID int64) error {
+	return s.Repo.RemoveMember(course.ID, userID)
+}
+
+func (s *CoursesService) validateCourse(course *courses.Course) error {
+	course.Title = strings.TrimSpace(course.Title)
+	if len(course.Title) == 0 {
+		return &CourseValidationError{MsgBlankTitleError}
+	}
+
+	foundCourse, err := s.Repo.GetByTitle(course.Title)
+	if err != nil {
+		return err
+	}
+	if foundCourse != nil && foundCourse.ID != course.ID {
+		return &CourseValidationError{MsgUniqueTitleError}
+	}
+
+	return nil
+}
+
+func generateInviteCode() (string, error) {
+	code := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(code); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.EncodeToString(code), nil
+}
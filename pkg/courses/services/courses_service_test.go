@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/pkg/courses"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+func TestCoursesJoin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := courses.NewMockRepositoryInterface(ctrl)
+	service := NewCoursesService(repo, nil)
+	user := &users.User{ID: 1}
+	course := &courses.Course{ID: 2, InviteCode: "CODE1234"}
+
+	type testCase struct {
+		Title   string
+		Code    string
+		Mock    func()
+		WantErr string
+	}
+
+	testCases := []*testCase{
+		{
+			Title:   "blank code",
+			Code:    " ",
+			Mock:    func() {},
+			WantErr: MsgInvalidInviteCodeError,
+		},
+		{
+			Title: "unknown code",
+			Code:  "unknown",
+			Mock: func() {
+				repo.EXPECT().GetByInviteCode("UNKNOWN").Return(nil, nil)
+			},
+			WantErr: MsgInvalidInviteCodeError,
+		},
+		{
+			Title: "new member",
+			Code:  " code1234 ",
+			Mock: func() {
+				repo.EXPECT().GetByInviteCode(course.InviteCode).Return(course, nil)
+				repo.EXPECT().GetMember(course.ID, user.ID).Return(nil, nil)
+				repo.EXPECT().AddMember(course.ID, user.ID, courses.StudentRole).Return(nil)
+			},
+		},
+		{
+			Title: "existing member keeps the role",
+			Code:  course.InviteCode,
+			Mock: func() {
+				repo.EXPECT().GetByInviteCode(course.InviteCode).Return(course, nil)
+				repo.EXPECT().GetMember(course.ID, user.ID).Return(
+					&courses.Member{CourseID: course.ID, UserID: user.ID, Role: courses.AssistantRole},
+					nil,
+				)
+			},
+		},
+		{
+			Title: "db error",
+			Code:  course.InviteCode,
+			Mock: func() {
+				repo.EXPECT().GetByInviteCode(course.InviteCode).Return(nil, fmt.Errorf("db_error"))
+			},
+			WantErr: "db_error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			testCase.Mock()
+
+			got, err := service.Join(user, testCase.Code)
+			if len(testCase.WantErr) > 0 {
+				if err == nil || err.Error() != testCase.WantErr {
+					t.Fatalf("expected error %q, got %v", testCase.WantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected to not have errors got %v", err)
+			}
+			if got != course {
+				t.Errorf("expected %+v, got %+v", course, got)
+			}
+		})
+	}
+}
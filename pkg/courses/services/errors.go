@@ -0,0 +1,9 @@
+package services
+
+type CourseValidationError struct {
+	Message string
+}
+
+func (e *CourseValidationError) Error() string {
+	return e.Message
+}
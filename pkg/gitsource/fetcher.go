@@ -0,0 +1,327 @@
+package gitsource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultTimeout     = 2 * time.Minute
+	DefaultMaxSize     = 50 * 1024 * 1024
+	DefaultMaxDiskSize = 200 * 1024 * 1024
+
+	diskCheckInterval = 100 * time.Millisecond
+)
+
+// Snapshot is the tree of a repository at a commit
+type Snapshot struct {
+	SHA string
+	// Archive is the zip archive of the tree
+	Archive []byte
+}
+
+// FetchError describes a problem of the repository or the ref given by the user
+type FetchError struct {
+	Message string
+}
+
+func (e *FetchError) Error() string {
+	return e.Message
+}
+
+type FetcherInterface interface {
+	Fetch(repositoryURL, ref string) (*Snapshot, error)
+}
+
+// Fetcher runs the git binary, only the commit of the ref is fetched into a
+// temporary directory which is removed once the tree is archived
+type Fetcher struct {
+	GitPath string
+	Timeout time.Duration
+	// MaxSize limits the archive of the tree
+	MaxSize int
+	// MaxDiskSize limits the fetched repository in the temporary directory
+	MaxDiskSize int64
+	// AllowedHosts may resolve to internal addresses, when the list is not
+	// empty repositories of other hosts are rejected
+	AllowedHosts []string
+}
+
+func NewFetcher(allowedHosts []string) *Fetcher {
+	return &Fetcher{
+		GitPath:      "git",
+		Timeout:      DefaultTimeout,
+		MaxSize:      DefaultMaxSize,
+		MaxDiskSize:  DefaultMaxDiskSize,
+		AllowedHosts: allowedHosts,
+	}
+}
+
+var errTooLarge = errors.New("output is too large")
+
+// Fetch archives the tree of the repository at the ref, the default branch is
+// used when the ref is empty. Refs are branches, tags or full commit hashes.
+func (f *Fetcher) Fetch(repositoryURL, ref string) (*Snapshot, error) {
+	if err := ValidateURL(repositoryURL); err != nil {
+		return nil, err
+	}
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+	if strings.HasPrefix(ref, "-") || strings.ContainsRune(ref, ':') || strings.IndexFunc(ref, isSpaceOrControl) >= 0 {
+		return nil, &FetchError{fmt.Sprintf("%q is not a valid ref", ref)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.Timeout)
+	defer cancel()
+
+	parsed, _ := url.Parse(repositoryURL)
+	address, err := f.resolve(ctx, parsed.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "grader-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	exceeded := &atomic.Bool{}
+	go watchDiskSize(ctx, cancel, dir, f.MaxDiskSize, exceeded)
+	fail := func(message string, err error) error {
+		if exceeded.Load() {
+			return &FetchError{"repository is too large"}
+		}
+		return fetchError(ctx, message, err)
+	}
+
+	repoDir := filepath.Join(dir, "repo.git")
+	_, err = f.git(ctx, dir, "init", "--quiet", "--bare", "--template=", repoDir)
+	if err != nil {
+		return nil, fail("repository can't be created", err)
+	}
+	// git resolves the host again, so it's pinned to the checked address
+	// and redirects which could lead to other hosts are not followed
+	_, err = f.git(
+		ctx, dir, "-C", repoDir,
+		"-c", "http.followRedirects=false",
+		"-c", "http.curloptResolve="+curlResolve(parsed, address),
+		"fetch", "--quiet", "--depth=1", "--no-tags", "--", repositoryURL, ref,
+	)
+	if err != nil {
+		if ctx.Err() == nil && isUnknownRef(err) {
+			return nil, &FetchError{fmt.Sprintf("%s is not a commit of the repository", ref)}
+		}
+		return nil, fail("repository can't be cloned", err)
+	}
+	if diskSize(dir) > f.MaxDiskSize {
+		return nil, &FetchError{"repository is too large"}
+	}
+	out, err := f.git(ctx, dir, "-C", repoDir, "rev-parse", "--verify", "--quiet", "FETCH_HEAD^{commit}")
+	if err != nil {
+		if ctx.Err() != nil || err == errTooLarge {
+			return nil, fail("", err)
+		}
+		return nil, &FetchError{fmt.Sprintf("%s is not a commit of the repository", ref)}
+	}
+	sha := strings.TrimSpace(string(out))
+
+	archive, err := f.git(ctx, dir, "-C", repoDir, "archive", "--format=zip", sha)
+	if err != nil {
+		return nil, fail("tree can't be archived", err)
+	}
+
+	return &Snapshot{SHA: sha, Archive: archive}, nil
+}
+
+// resolve returns the address of the host, hosts resolving to loopback,
+// private or link-local addresses are rejected unless they are allowed
+func (f *Fetcher) resolve(ctx context.Context, host string) (net.IP, error) {
+	allowed := false
+	for _, allowedHost := range f.AllowedHosts {
+		if strings.EqualFold(allowedHost, host) {
+			allowed = true
+			break
+		}
+	}
+	if len(f.AllowedHosts) > 0 && !allowed {
+		return nil, &FetchError{fmt.Sprintf("repositories of %s are not allowed", host)}
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addresses) == 0 {
+		return nil, &FetchError{fmt.Sprintf("host %s can't be resolved", host)}
+	}
+	if !allowed {
+		for _, address := range addresses {
+			if isInternal(address.IP) {
+				return nil, &FetchError{"repository url should point to a public host"}
+			}
+		}
+	}
+
+	return addresses[0].IP, nil
+}
+
+func isInternal(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// curlResolve formats the http.curloptResolve entry of the URL host
+func curlResolve(parsed *url.URL, address net.IP) string {
+	port := parsed.Port()
+	if len(port) == 0 {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := address.String()
+	if address.To4() == nil {
+		ip = "[" + ip + "]"
+	}
+
+	return fmt.Sprintf("%s:%s:%s", parsed.Hostname(), port, ip)
+}
+
+func isUnknownRef(err error) bool {
+	message := err.Error()
+
+	return strings.Contains(message, "couldn't find remote ref") ||
+		strings.Contains(message, "not our ref") ||
+		strings.Contains(message, "unadvertised object")
+}
+
+// watchDiskSize cancels the fetch once the directory grows over the limit
+func watchDiskSize(ctx context.Context, cancel context.CancelFunc, dir string, limit int64, exceeded *atomic.Bool) {
+	ticker := time.NewTicker(diskCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if diskSize(dir) > limit {
+				exceeded.Store(true)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func diskSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size
+}
+
+// git runs the command without the user and system configs, only http and
+// https transports are allowed so that URLs can't run commands or read local files
+func (f *Fetcher) git(ctx context.Context, home string, args ...string) ([]byte, error) {
+	stdout := &limitedBuffer{limit: f.MaxSize}
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, f.GitPath, args...)
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + home,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ALLOW_PROTOCOL=http:https",
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if stdout.exceeded {
+			return nil, errTooLarge
+		}
+		if message := lastLine(stderr.String()); len(message) > 0 {
+			return nil, errors.New(message)
+		}
+		return nil, err
+	}
+
+	return stdout.buf.Bytes(), nil
+}
+
+func fetchError(ctx context.Context, message string, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		return &FetchError{"repository fetch timed out"}
+	case err == errTooLarge:
+		return &FetchError{"repository is too large"}
+	default:
+		return &FetchError{fmt.Sprintf("%s: %v", message, err)}
+	}
+}
+
+// ValidateURL accepts http and https URLs only
+func ValidateURL(repositoryURL string) error {
+	parsed, err := url.Parse(repositoryURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 {
+		return &FetchError{"repository url should be an http or https url"}
+	}
+
+	return nil
+}
+
+// RedactURL removes credentials from the URL so that it can be stored
+func RedactURL(repositoryURL string) string {
+	parsed, err := url.Parse(repositoryURL)
+	if err != nil {
+		return ""
+	}
+	parsed.User = nil
+
+	return parsed.String()
+}
+
+func isSpaceOrControl(r rune) bool {
+	return r <= ' ' || r == 0x7f
+}
+
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// limitedBuffer fails writes after the limit so that the command is stopped
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		b.exceeded = true
+		return 0, errTooLarge
+	}
+
+	return b.buf.Write(p)
+}
@@ -5,4 +5,5 @@ import "database/sql"
 type SqlQueryable interface {
 	Prepare(query string) (*sql.Stmt, error)
 	QueryRow(query string, args ...any) *sql.Row
+	Exec(query string, args ...any) (sql.Result, error)
 }
@@ -65,7 +65,7 @@ func AuthMiddleware(sm HttpSessionManager, repo users.RepositoryInterface) mux.M
 					return
 				}
 
-				w.WriteHeader(http.StatusBadRequest)
+				utils.RenderError(w, r, http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
 				return
 			}
 
@@ -93,17 +93,18 @@ func AuthMiddleware(sm HttpSessionManager, repo users.RepositoryInterface) mux.M
 	}
 }
 
-func AdminPolicyMiddleware(sm HttpSessionManager) mux.MiddlewareFunc {
+// PolicyMiddleware renders forbidden unless the role of the current user grants the permission
+func PolicyMiddleware(sm HttpSessionManager, permission users.Permission) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			user, err := sm.CurrentUser(r)
 			if err != nil {
-				http.Error(w, MsgForbiddenUser, http.StatusForbidden)
+				utils.RenderError(w, r, http.StatusForbidden, MsgForbiddenUser)
 				return
 			}
 
-			if !user.IsAdmin {
-				http.Error(w, MsgForbiddenUser, http.StatusForbidden)
+			if !user.Can(permission) {
+				utils.RenderError(w, r, http.StatusForbidden, MsgForbiddenUser)
 				return
 			}
 
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/submissions"
 	"github.com/maxshend/grader/pkg/submissions/services"
 	"github.com/maxshend/grader/pkg/utils"
 )
@@ -15,11 +16,6 @@ type SubmissionsHttpHandler struct {
 	Service services.SubmissionsServiceInterface
 }
 
-type RunnerResponse struct {
-	Pass bool   `json:"pass"`
-	Text string `json:"text"`
-}
-
 func NewSubmissionsHttpHandler(service services.SubmissionsServiceInterface) *SubmissionsHttpHandler {
 	return &SubmissionsHttpHandler{
 		Service: service,
@@ -34,7 +30,7 @@ func (h *SubmissionsHttpHandler) Webhook(w http.ResponseWriter, r *http.Request)
 	}
 
 	params := mux.Vars(r)
-	runnerResponse := &RunnerResponse{}
+	runnerResponse := &submissions.RunnerResponse{}
 	err := json.NewDecoder(r.Body).Decode(runnerResponse)
 	if err != nil {
 		utils.RenderInternalError(w, r, err)
@@ -49,10 +45,12 @@ func (h *SubmissionsHttpHandler) Webhook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.Service.HandleWebhook(token, int64(submissionID), runnerResponse.Pass, runnerResponse.Text)
+	err = h.Service.HandleWebhook(token, int64(submissionID), runNumber(r), runnerResponse)
 	if err != nil {
 		if err == services.ErrSubmissionNotFound {
 			http.Error(w, err.Error(), http.StatusNotFound)
+		} else if err == services.ErrInvalidTestResult || err == services.ErrInvalidScore {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 		} else if err == utils.ErrInvalidAccessToken {
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 		} else {
@@ -64,3 +62,41 @@ func (h *SubmissionsHttpHandler) Webhook(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *SubmissionsHttpHandler) Logs(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("Authorization")
+	if len(token) == 0 {
+		http.Error(w, utils.ErrInvalidAccessToken.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	params := mux.Vars(r)
+	submissionID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	logs := &submissions.RunnerLogs{}
+	err = json.NewDecoder(r.Body).Decode(logs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.HandleLogs(token, int64(submissionID), runNumber(r), logs)
+	if err != nil {
+		http.Error(w, utils.ErrInvalidAccessToken.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// runNumber is sent by the runner in the run query parameter, tasks queued
+// before the parameter was added belong to the first run
+func runNumber(r *http.Request) int {
+	run, _ := strconv.Atoi(r.URL.Query().Get("run"))
+
+	return run
+}
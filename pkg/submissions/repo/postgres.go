@@ -2,13 +2,16 @@ package repo
 
 import (
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/lib/pq"
-	"github.com/maxshend/grader/pkg/attachments"
 	"github.com/maxshend/grader/pkg/repo"
 	"github.com/maxshend/grader/pkg/submissions"
 )
 
+const idempotencyKeyIndex = "submissions_idempotency_key_index"
+
 type SubmissionsSQLRepo struct {
 	DB *sql.DB
 }
@@ -17,18 +20,29 @@ func NewSubmissionsSQLRepo(db *sql.DB) *SubmissionsSQLRepo {
 	return &SubmissionsSQLRepo{DB: db}
 }
 
-func (r *SubmissionsSQLRepo) Create(sqlExec repo.SqlQueryable, userID int64, assignmentID int64) (*submissions.Submission, error) {
+func (r *SubmissionsSQLRepo) Create(
+	sqlExec repo.SqlQueryable,
+	userID int64,
+	assignmentID int64,
+	maxScore float64,
+	late bool,
+) (*submissions.Submission, error) {
 	submission := &submissions.Submission{
 		UserID:       userID,
 		AssignmentID: assignmentID,
 		Status:       submissions.InProgress,
+		MaxScore:     maxScore,
+		Late:         late,
 	}
 	err := sqlExec.QueryRow(
-		"INSERT INTO submissions (user_id, assignment_id, status) VALUES ($1, $2, $3) RETURNING id",
+		"INSERT INTO submissions (user_id, assignment_id, status, max_score, late) "+
+			"VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
 		userID,
 		assignmentID,
 		submission.Status,
-	).Scan(&submission.ID)
+		submission.MaxScore,
+		submission.Late,
+	).Scan(&submission.ID, &submission.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +51,10 @@ func (r *SubmissionsSQLRepo) Create(sqlExec repo.SqlQueryable, userID int64, ass
 }
 
 func (r *SubmissionsSQLRepo) GetSubmissionAttachments(submissionID int64) ([]*submissions.Attachment, error) {
-	rows, err := r.DB.Query("SELECT id, url, name FROM submission_attachments WHERE submission_id = $1", submissionID)
+	rows, err := r.DB.Query(
+		"SELECT id, url, name, entries FROM submission_attachments WHERE submission_id = $1 ORDER BY id",
+		submissionID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -47,9 +64,10 @@ func (r *SubmissionsSQLRepo) GetSubmissionAttachments(submissionID int64) ([]*su
 	for rows.Next() {
 		attach := &submissions.Attachment{}
 
-		if err := rows.Scan(&attach.ID, &attach.URL, &attach.Name); err != nil {
+		if err := rows.Scan(&attach.ID, &attach.URL, &attach.Name, pq.Array(&attach.Entries)); err != nil {
 			return nil, err
 		}
+		attach.Archive = attach.Entries != nil
 		result = append(result, attach)
 	}
 	if err := rows.Err(); err != nil {
@@ -62,45 +80,137 @@ func (r *SubmissionsSQLRepo) GetSubmissionAttachments(submissionID int64) ([]*su
 func (r *SubmissionsSQLRepo) CreateSubmissionAttachments(
 	sqlExec repo.SqlQueryable,
 	submissionID int64,
-	attachments []*attachments.Attachment,
+	attachments []*submissions.Attachment,
 ) ([]*submissions.Attachment, error) {
-	stm, err := sqlExec.Prepare(pq.CopyIn("submission_attachments", "url", "name", "submission_id"))
+	stm, err := sqlExec.Prepare(pq.CopyIn("submission_attachments", "url", "name", "entries", "submission_id"))
 	if err != nil {
 		return nil, err
 	}
 	defer stm.Close()
 
-	submissionAttachments := []*submissions.Attachment{}
-
 	for _, attachment := range attachments {
-		_, err = stm.Exec(attachment.URL, attachment.Name, submissionID)
+		_, err = stm.Exec(attachment.URL, attachment.Name, pq.StringArray(attachment.Entries), submissionID)
 		if err != nil {
 			return nil, err
 		}
+		attachment.SubmissionID = submissionID
+		attachment.Archive = attachment.Entries != nil
+	}
 
-		submissionAttachments = append(
-			submissionAttachments,
-			&submissions.Attachment{URL: attachment.URL, Name: attachment.Name},
-		)
+	_, err = stm.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// GetAttachmentsAfter returns attachments of all submissions ordered by ID
+func (r *SubmissionsSQLRepo) GetAttachmentsAfter(afterID int64, limit int) ([]*submissions.Attachment, error) {
+	rows, err := r.DB.Query(
+		"SELECT id, url, name, submission_id FROM submission_attachments WHERE id > $1 ORDER BY id LIMIT $2",
+		afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*submissions.Attachment{}
+	for rows.Next() {
+		attach := &submissions.Attachment{}
+
+		if err := rows.Scan(&attach.ID, &attach.URL, &attach.Name, &attach.SubmissionID); err != nil {
+			return nil, err
+		}
+		result = append(result, attach)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *SubmissionsSQLRepo) UpdateAttachmentURL(id int64, url string) error {
+	_, err := r.DB.Exec("UPDATE submission_attachments SET url = $1 WHERE id = $2", url, id)
+
+	return err
+}
+
+func (r *SubmissionsSQLRepo) CreateTestResults(
+	sqlExec repo.SqlQueryable,
+	submissionID int64,
+	results []*submissions.TestResult,
+) error {
+	stm, err := sqlExec.Prepare(
+		pq.CopyIn("submission_results", "name", "status", "duration", "message", "submission_id"),
+	)
+	if err != nil {
+		return err
+	}
+	defer stm.Close()
+
+	for _, result := range results {
+		_, err = stm.Exec(result.Name, result.Status, result.Duration, result.Message, submissionID)
+		if err != nil {
+			return err
+		}
 	}
 
 	_, err = stm.Exec()
 	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SubmissionsSQLRepo) GetTestResults(submissionID int64) ([]*submissions.TestResult, error) {
+	rows, err := r.DB.Query(
+		"SELECT id, name, status, duration, message FROM submission_results "+
+			"WHERE submission_id = $1 AND run_id IS NULL ORDER BY id",
+		submissionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*submissions.TestResult{}
+	for rows.Next() {
+		testResult := &submissions.TestResult{}
+		err := rows.Scan(
+			&testResult.ID, &testResult.Name, &testResult.Status, &testResult.Duration, &testResult.Message,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, testResult)
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return submissionAttachments, nil
+	return result, nil
 }
 
 func (r *SubmissionsSQLRepo) GetByID(id int64) (*submissions.Submission, error) {
 	submission := &submissions.Submission{}
 	detailsString := sql.NullString{}
+	score := sql.NullFloat64{}
+	exitCode := sql.NullInt64{}
 	err := r.DB.QueryRow(
-		"SELECT id, user_id, assignment_id, status, details FROM submissions WHERE id = $1 LIMIT 1",
+		"SELECT id, user_id, assignment_id, status, details, score, max_score, "+
+			"stdout, stderr, exit_code, duration, late, repository_url, commit_sha, run_number, created_at "+
+			"FROM submissions WHERE id = $1 LIMIT 1",
 		id,
 	).Scan(
 		&submission.ID, &submission.UserID, &submission.AssignmentID,
-		&submission.Status, &detailsString,
+		&submission.Status, &detailsString, &score, &submission.MaxScore,
+		&submission.Stdout, &submission.Stderr, &exitCode, &submission.Duration, &submission.Late,
+		&submission.RepositoryURL, &submission.CommitSHA, &submission.RunNumber, &submission.CreatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -112,14 +222,21 @@ func (r *SubmissionsSQLRepo) GetByID(id int64) (*submissions.Submission, error)
 	if detailsString.Valid {
 		submission.Details = detailsString.String
 	}
+	submission.Score = score.Float64
+	if exitCode.Valid {
+		code := int(exitCode.Int64)
+		submission.ExitCode = &code
+	}
 
 	return submission, nil
 }
 
 func (r *SubmissionsSQLRepo) Update(submission *submissions.Submission) error {
 	_, err := r.DB.Exec(
-		"UPDATE submissions SET status = $1, details = $2 WHERE id = $3",
-		submission.Status, submission.Details, submission.ID,
+		"UPDATE submissions SET status = $1, details = $2, score = $3, stdout = $4, stderr = $5, "+
+			"exit_code = $6, duration = $7 WHERE id = $8",
+		submission.Status, submission.Details, submission.Score, submission.Stdout, submission.Stderr,
+		submission.ExitCode, submission.Duration, submission.ID,
 	)
 	if err != nil {
 		return err
@@ -128,6 +245,78 @@ func (r *SubmissionsSQLRepo) Update(submission *submissions.Submission) error {
 	return nil
 }
 
+// SetKeys records the content hash and the idempotency key of the submission,
+// ErrIdempotencyKeyUsed is returned when the key belongs to another submission
+func (r *SubmissionsSQLRepo) SetKeys(
+	sqlExec repo.SqlQueryable,
+	submissionID int64,
+	contentHash string,
+	idempotencyKey string,
+) error {
+	_, err := sqlExec.Exec(
+		"UPDATE submissions SET content_hash = $1, idempotency_key = $2 WHERE id = $3",
+		contentHash, idempotencyKey, submissionID,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == idempotencyKeyIndex {
+		return submissions.ErrIdempotencyKeyUsed
+	}
+
+	return err
+}
+
+// GetByContentHash returns the latest submission of the user with the same
+// content which wasn't stopped by a grading error
+func (r *SubmissionsSQLRepo) GetByContentHash(
+	assignmentID int64,
+	userID int64,
+	contentHash string,
+) (*submissions.Submission, error) {
+	return r.getByIDQuery(
+		"SELECT id FROM submissions WHERE assignment_id = $1 AND user_id = $2 AND content_hash = $3 "+
+			"AND status <> $4 ORDER BY id DESC LIMIT 1",
+		assignmentID, userID, contentHash, submissions.GradingError,
+	)
+}
+
+func (r *SubmissionsSQLRepo) GetByIdempotencyKey(userID int64, idempotencyKey string) (*submissions.Submission, error) {
+	return r.getByIDQuery(
+		"SELECT id FROM submissions WHERE user_id = $1 AND idempotency_key = $2 LIMIT 1",
+		userID, idempotencyKey,
+	)
+}
+
+// getByIDQuery loads the submission with the id selected by the query, nil is
+// returned when there is no such submission
+func (r *SubmissionsSQLRepo) getByIDQuery(query string, args ...any) (*submissions.Submission, error) {
+	var id int64
+	err := r.DB.QueryRow(query, args...).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+// SetSource records the repository and the commit the files of the submission were fetched from
+func (r *SubmissionsSQLRepo) SetSource(
+	sqlExec repo.SqlQueryable,
+	submissionID int64,
+	repositoryURL string,
+	commitSHA string,
+) error {
+	_, err := sqlExec.Exec(
+		"UPDATE submissions SET repository_url = $1, commit_sha = $2 WHERE id = $3",
+		repositoryURL, commitSHA, submissionID,
+	)
+
+	return err
+}
+
 func (r *SubmissionsSQLRepo) GetByUserAssignmentCount(assignmentID int64, userID int64) (count int, err error) {
 	err = r.DB.QueryRow(
 		"SELECT COUNT(*) "+
@@ -141,6 +330,56 @@ func (r *SubmissionsSQLRepo) GetByUserAssignmentCount(assignmentID int64, userID
 	return
 }
 
+// GetAttemptsCount counts submissions of the user which spent an attempt,
+// submissions stopped by a grading error are not counted
+func (r *SubmissionsSQLRepo) GetAttemptsCount(assignmentID int64, userID int64) (count int, err error) {
+	err = r.DB.QueryRow(
+		"SELECT COUNT(*) FROM submissions WHERE user_id = $1 AND assignment_id = $2 AND status <> $3",
+		userID, assignmentID, submissions.GradingError,
+	).Scan(&count)
+
+	return
+}
+
+// LockUser locks the row of the user, limits checked in the transaction
+// afterwards can't be passed by concurrent submissions of the user
+func (r *SubmissionsSQLRepo) LockUser(sqlExec repo.SqlQueryable, userID int64) error {
+	var id int64
+
+	return sqlExec.QueryRow("SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&id)
+}
+
+// GetInProgressCount counts submissions of the user being graded which were created after since
+func (r *SubmissionsSQLRepo) GetInProgressCount(
+	sqlExec repo.SqlQueryable,
+	assignmentID int64,
+	userID int64,
+	since time.Time,
+) (count int, err error) {
+	err = sqlExec.QueryRow(
+		"SELECT COUNT(*) FROM submissions WHERE user_id = $1 AND assignment_id = $2 AND status = $3 AND created_at > $4",
+		userID, assignmentID, submissions.InProgress, since,
+	).Scan(&count)
+
+	return
+}
+
+// GetRecentCountByUser counts submissions of the user to all assignments
+// created after since and returns the time of the oldest one
+func (r *SubmissionsSQLRepo) GetRecentCountByUser(userID int64, since time.Time) (count int, oldest time.Time, err error) {
+	oldestTime := sql.NullTime{}
+	err = r.DB.QueryRow(
+		"SELECT COUNT(*), MIN(created_at) FROM submissions WHERE user_id = $1 AND created_at > $2",
+		userID, since,
+	).Scan(&count, &oldestTime)
+	if err != nil {
+		return
+	}
+	oldest = oldestTime.Time
+
+	return
+}
+
 func (r *SubmissionsSQLRepo) GetByUserAssignment(
 	assignmentID int64,
 	userID int64,
@@ -148,7 +387,7 @@ func (r *SubmissionsSQLRepo) GetByUserAssignment(
 	offset int,
 ) ([]*submissions.Submission, error) {
 	rows, err := r.DB.Query(
-		"SELECT id, status, details, created_at "+
+		"SELECT id, status, details, score, max_score, late, created_at "+
 			"FROM submissions WHERE user_id = $1 AND assignment_id = $2 "+
 			"ORDER BY id DESC LIMIT $3 OFFSET $4",
 		userID, assignmentID, limit, offset,
@@ -161,9 +400,11 @@ func (r *SubmissionsSQLRepo) GetByUserAssignment(
 	result := []*submissions.Submission{}
 	for rows.Next() {
 		detailsString := sql.NullString{}
+		score := sql.NullFloat64{}
 		submission := &submissions.Submission{UserID: userID, AssignmentID: assignmentID}
 		err = rows.Scan(
-			&submission.ID, &submission.Status, &detailsString, &submission.CreatedAt,
+			&submission.ID, &submission.Status, &detailsString, &score, &submission.MaxScore,
+			&submission.Late, &submission.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -171,6 +412,7 @@ func (r *SubmissionsSQLRepo) GetByUserAssignment(
 		if detailsString.Valid {
 			submission.Details = detailsString.String
 		}
+		submission.Score = score.Float64
 
 		result = append(result, submission)
 	}
@@ -186,8 +428,8 @@ func (r *SubmissionsSQLRepo) GetByAssignment(
 	limit, offset int,
 ) ([]*submissions.Submission, error) {
 	rows, err := r.DB.Query(
-		"SELECT submissions.id, submissions.status, submissions.details, "+
-			"submissions.created_at, users.username AS username "+
+		"SELECT submissions.id, submissions.status, submissions.details, submissions.score, "+
+			"submissions.max_score, submissions.late, submissions.created_at, users.username AS username "+
 			"FROM submissions JOIN users ON submissions.user_id = users.id WHERE assignment_id = $1 "+
 			"ORDER BY id DESC LIMIT $2 OFFSET $3",
 		assignmentID, limit, offset,
@@ -200,9 +442,11 @@ func (r *SubmissionsSQLRepo) GetByAssignment(
 	result := []*submissions.Submission{}
 	for rows.Next() {
 		detailsString := sql.NullString{}
+		score := sql.NullFloat64{}
 		submission := &submissions.Submission{AssignmentID: assignmentID}
 		err = rows.Scan(
-			&submission.ID, &submission.Status, &detailsString, &submission.CreatedAt, &submission.Username,
+			&submission.ID, &submission.Status, &detailsString, &score,
+			&submission.MaxScore, &submission.Late, &submission.CreatedAt, &submission.Username,
 		)
 		if err != nil {
 			return nil, err
@@ -210,6 +454,38 @@ func (r *SubmissionsSQLRepo) GetByAssignment(
 		if detailsString.Valid {
 			submission.Details = detailsString.String
 		}
+		submission.Score = score.Float64
+
+		result = append(result, submission)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// GetAllByAssignment returns submissions without their results, latestOnly
+// keeps only the last submission of every user
+func (r *SubmissionsSQLRepo) GetAllByAssignment(assignmentID int64, latestOnly bool) ([]*submissions.Submission, error) {
+	query := "SELECT id, user_id, status, max_score FROM submissions WHERE assignment_id = $1 ORDER BY id"
+	if latestOnly {
+		query = "SELECT DISTINCT ON (user_id) id, user_id, status, max_score FROM submissions " +
+			"WHERE assignment_id = $1 ORDER BY user_id, id DESC"
+	}
+	rows, err := r.DB.Query(query, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*submissions.Submission{}
+	for rows.Next() {
+		submission := &submissions.Submission{AssignmentID: assignmentID}
+		err = rows.Scan(&submission.ID, &submission.UserID, &submission.Status, &submission.MaxScore)
+		if err != nil {
+			return nil, err
+		}
 
 		result = append(result, submission)
 	}
@@ -220,6 +496,93 @@ func (r *SubmissionsSQLRepo) GetByAssignment(
 	return result, nil
 }
 
+// ArchiveResult copies the current result of the submission with its test results into the history
+func (r *SubmissionsSQLRepo) ArchiveResult(sqlExec repo.SqlQueryable, submissionID int64) error {
+	var runID int64
+	err := sqlExec.QueryRow(
+		"INSERT INTO submission_runs "+
+			"(submission_id, status, details, score, max_score, stdout, stderr, exit_code, duration) "+
+			"SELECT id, status, details, score, max_score, stdout, stderr, exit_code, duration "+
+			"FROM submissions WHERE id = $1 RETURNING id",
+		submissionID,
+	).Scan(&runID)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExec.Exec(
+		"UPDATE submission_results SET run_id = $1 WHERE submission_id = $2 AND run_id IS NULL",
+		runID, submissionID,
+	)
+
+	return err
+}
+
+// Reset clears the result of the submission and puts it back in progress
+// with the next run number
+func (r *SubmissionsSQLRepo) Reset(sqlExec repo.SqlQueryable, submission *submissions.Submission) error {
+	return sqlExec.QueryRow(
+		"UPDATE submissions SET status = $1, details = NULL, score = NULL, stdout = '', stderr = '', "+
+			"exit_code = NULL, duration = 0, max_score = $2, run_number = run_number + 1 "+
+			"WHERE id = $3 RETURNING run_number",
+		submissions.InProgress, submission.MaxScore, submission.ID,
+	).Scan(&submission.RunNumber)
+}
+
+func (r *SubmissionsSQLRepo) Finish(sqlExec repo.SqlQueryable, submission *submissions.Submission) (bool, error) {
+	result, err := sqlExec.Exec(
+		"UPDATE submissions SET status = $1, details = $2, score = $3, stdout = $4, stderr = $5, "+
+			"exit_code = $6, duration = $7 WHERE id = $8 AND status = $9 AND run_number = $10",
+		submission.Status, submission.Details, submission.Score, submission.Stdout, submission.Stderr,
+		submission.ExitCode, submission.Duration, submission.ID, submissions.InProgress, submission.RunNumber,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+
+	return affected > 0, err
+}
+
+func (r *SubmissionsSQLRepo) GetRuns(submissionID int64) ([]*submissions.Run, error) {
+	rows, err := r.DB.Query(
+		"SELECT id, status, details, score, max_score, exit_code, duration, created_at "+
+			"FROM submission_runs WHERE submission_id = $1 ORDER BY id DESC",
+		submissionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*submissions.Run{}
+	for rows.Next() {
+		detailsString := sql.NullString{}
+		score := sql.NullFloat64{}
+		exitCode := sql.NullInt64{}
+		run := &submissions.Run{SubmissionID: submissionID}
+		err = rows.Scan(
+			&run.ID, &run.Status, &detailsString, &score, &run.MaxScore, &exitCode, &run.Duration, &run.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		run.Details = detailsString.String
+		run.Score = score.Float64
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			run.ExitCode = &code
+		}
+
+		result = append(result, run)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
 func (r *SubmissionsSQLRepo) CreateTxn() (*sql.Tx, error) {
 	return r.DB.Begin()
 }
@@ -2,4 +2,8 @@ package services
 
 import "errors"
 
-var ErrSubmissionNotFound = errors.New("submission not found")
+var (
+	ErrSubmissionNotFound = errors.New("submission not found")
+	ErrInvalidTestResult  = errors.New("invalid test result status")
+	ErrInvalidScore       = errors.New("score is out of the assignment range")
+)
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxLogLines  = 1000
+	DefaultLogsLifetime = time.Hour
+	subscriberBuffer    = 256
+)
+
+// LogsBroker keeps live log lines of running submissions in memory and fans
+// them out to subscribers. Only the last MaxLines lines are kept as a backlog
+// for late subscribers, the full output is stored with the final result.
+type LogsBroker struct {
+	MaxLines int
+	Lifetime time.Duration
+	streams  map[int64]*logStream
+	mx       sync.Mutex
+}
+
+type logStream struct {
+	lines       []string
+	subscribers map[chan string]struct{}
+	updatedAt   time.Time
+}
+
+func NewLogsBroker() *LogsBroker {
+	return &LogsBroker{
+		MaxLines: DefaultMaxLogLines,
+		Lifetime: DefaultLogsLifetime,
+		streams:  make(map[int64]*logStream),
+	}
+}
+
+func (b *LogsBroker) Publish(submissionID int64, lines []string) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.prune()
+
+	stream := b.stream(submissionID)
+	stream.updatedAt = time.Now()
+	stream.lines = append(stream.lines, lines...)
+	if len(stream.lines) > b.MaxLines {
+		stream.lines = stream.lines[len(stream.lines)-b.MaxLines:]
+	}
+
+	for ch := range stream.subscribers {
+		for _, line := range lines {
+			select {
+			case ch <- line:
+			default:
+				// Slow subscribers miss lines instead of blocking the runner
+			}
+		}
+	}
+}
+
+// Finish notifies subscribers that the submission got its final result and forgets the stream
+func (b *LogsBroker) Finish(submissionID int64) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	stream, ok := b.streams[submissionID]
+	if !ok {
+		return
+	}
+	for ch := range stream.subscribers {
+		delete(stream.subscribers, ch)
+		close(ch)
+	}
+	delete(b.streams, submissionID)
+}
+
+// Subscribe returns the backlog of the stream and a channel with new lines
+// which is closed by Finish, unsubscribe has to be called once the subscriber is gone
+func (b *LogsBroker) Subscribe(submissionID int64) (backlog []string, lines <-chan string, unsubscribe func()) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	stream := b.stream(submissionID)
+	ch := make(chan string, subscriberBuffer)
+	stream.subscribers[ch] = struct{}{}
+	backlog = append([]string(nil), stream.lines...)
+
+	unsubscribe = func() {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		if _, ok := stream.subscribers[ch]; ok {
+			delete(stream.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return backlog, ch, unsubscribe
+}
+
+// stream should be called with the lock held
+func (b *LogsBroker) stream(submissionID int64) *logStream {
+	stream, ok := b.streams[submissionID]
+	if !ok {
+		stream = &logStream{
+			subscribers: make(map[chan string]struct{}),
+			updatedAt:   time.Now(),
+		}
+		b.streams[submissionID] = stream
+	}
+
+	return stream
+}
+
+// prune should be called with the lock held, it drops streams of runs which
+// never reported their result
+func (b *LogsBroker) prune() {
+	for id, stream := range b.streams {
+		if len(stream.subscribers) == 0 && time.Since(stream.updatedAt) > b.Lifetime {
+			delete(b.streams, id)
+		}
+	}
+}
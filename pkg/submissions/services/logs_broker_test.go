@@ -0,0 +1,39 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogsBroker(t *testing.T) {
+	broker := NewLogsBroker()
+	broker.MaxLines = 2
+
+	broker.Publish(1, []string{"first", "second", "third"})
+	backlog, lines, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	want := []string{"second", "third"}
+	if !reflect.DeepEqual(backlog, want) {
+		t.Errorf("expected backlog %q, got %q", want, backlog)
+	}
+
+	broker.Publish(1, []string{"fourth"})
+	broker.Publish(2, []string{"other"})
+	broker.Finish(1)
+
+	got := []string{}
+	for line := range lines {
+		got = append(got, line)
+	}
+	want = []string{"fourth"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected lines %q, got %q", want, got)
+	}
+
+	backlog, _, unsubscribeFinished := broker.Subscribe(1)
+	defer unsubscribeFinished()
+	if len(backlog) != 0 {
+		t.Errorf("expected to not have backlog after finish, got %q", backlog)
+	}
+}
@@ -1,7 +1,8 @@
 package services
 
 import (
-	"strconv"
+	"log"
+	"math"
 	"strings"
 
 	"github.com/maxshend/grader/pkg/submissions"
@@ -11,13 +12,19 @@ import (
 type SubmissionsService struct {
 	Repo      submissions.RepositoryInterface
 	JwtSecret string
+	Logs      *LogsBroker
 }
 
 const DefaultPageSize = 25
 
 type SubmissionsServiceInterface interface {
-	HandleWebhook(token string, submissionID int64, pass bool, text string) error
+	HandleWebhook(token string, submissionID int64, runNumber int, response *submissions.RunnerResponse) error
+	HandleLogs(token string, submissionID int64, runNumber int, logs *submissions.RunnerLogs) error
+	SubscribeLogs(submissionID int64) (backlog []string, lines <-chan string, unsubscribe func())
 	GetByID(int64) (*submissions.Submission, error)
+	GetTestResults(submissionID int64) ([]*submissions.TestResult, error)
+	GetAttachments(submissionID int64) ([]*submissions.Attachment, error)
+	GetRuns(submissionID int64) ([]*submissions.Run, error)
 	Update(*submissions.Submission) error
 	GetByUserAssignment(
 		assignmentID, userID int64,
@@ -30,10 +37,19 @@ func NewSubmissionsService(repo submissions.RepositoryInterface, jwtSeret string
 	return &SubmissionsService{
 		Repo:      repo,
 		JwtSecret: jwtSeret,
+		Logs:      NewLogsBroker(),
 	}
 }
 
-func (s *SubmissionsService) HandleWebhook(token string, submissionID int64, pass bool, text string) error {
+// HandleWebhook stores the result of the run, results of finished submissions
+// and of the runs replaced by a regrade are ignored so that redelivered and
+// late webhooks don't overwrite the current result
+func (s *SubmissionsService) HandleWebhook(
+	token string,
+	submissionID int64,
+	runNumber int,
+	response *submissions.RunnerResponse,
+) error {
 	submission, err := s.GetByID(submissionID)
 	if err != nil {
 		return err
@@ -42,33 +58,141 @@ func (s *SubmissionsService) HandleWebhook(token string, submissionID int64, pas
 		return ErrSubmissionNotFound
 	}
 
-	err = utils.CheckAccessToken(s.JwtSecret, token, strconv.FormatInt(submission.ID, 10))
+	err = utils.CheckAccessToken(s.JwtSecret, token, submissions.RunTokenID(submission.ID, runNumber))
 	if err != nil {
 		return err
 	}
+	if submission.Status != submissions.InProgress || submission.RunNumber != runNumber {
+		log.Printf("Ignoring result of run %d of submission #%d", runNumber, submission.ID)
+		return nil
+	}
+
+	score, err := webhookScore(submission, response)
+	if err != nil {
+		return err
+	}
+
+	for _, testResult := range response.Tests {
+		switch testResult.Status {
+		case submissions.TestPassed, submissions.TestFailed, submissions.TestSkipped:
+		default:
+			return ErrInvalidTestResult
+		}
+		testResult.Name = sanitizeText(testResult.Name)
+		testResult.Message = sanitizeText(testResult.Message)
+	}
+
+	submission.Status = webhookStatus(response)
+	submission.Score = score
+	submission.Details = sanitizeText(response.Text)
+	submission.Stdout = sanitizeText(response.Stdout)
+	submission.Stderr = sanitizeText(response.Stderr)
+	submission.ExitCode = response.ExitCode
+	submission.Duration = response.Duration
+
+	finished, err := s.finish(submission, response.Tests)
+	if err != nil {
+		return err
+	}
+	if !finished {
+		log.Printf("Ignoring result of run %d of submission #%d", runNumber, submission.ID)
+		return nil
+	}
+	s.Logs.Finish(submission.ID)
+
+	return nil
+}
 
-	var newStatus int
-	if pass {
-		newStatus = submissions.Success
-	} else {
-		newStatus = submissions.Fail
+// finish stores the result together with the test results, nothing is stored
+// when another webhook has finished the run first
+func (s *SubmissionsService) finish(
+	submission *submissions.Submission,
+	results []*submissions.TestResult,
+) (finished bool, err error) {
+	txn, err := s.Repo.CreateTxn()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err == nil && finished {
+			return
+		}
+		if rollbackErr := txn.Rollback(); rollbackErr != nil {
+			log.Printf("Error while reverting db changes: %v", rollbackErr)
+		}
+	}()
+
+	if len(results) > 0 {
+		err = s.Repo.CreateTestResults(txn, submission.ID, results)
+		if err != nil {
+			return false, err
+		}
 	}
-	submission.Status = newStatus
-	// strings.Replace is used to fix: pq: invalid byte sequence for encoding "UTF8": 0x00
-	submission.Details = strings.Replace(text, "\u0000", "", -1)
+	finished, err = s.Repo.Finish(txn, submission)
+	if err != nil || !finished {
+		return false, err
+	}
+
+	return true, txn.Commit()
+}
 
-	err = s.Update(submission)
+// HandleLogs doesn't check the submission in the database to not hit it
+// on every batch of lines, the access token is issued for the submission ID
+func (s *SubmissionsService) HandleLogs(
+	token string,
+	submissionID int64,
+	runNumber int,
+	logs *submissions.RunnerLogs,
+) error {
+	err := utils.CheckAccessToken(s.JwtSecret, token, submissions.RunTokenID(submissionID, runNumber))
 	if err != nil {
 		return err
 	}
 
+	lines := make([]string, 0, len(logs.Lines))
+	for _, line := range logs.Lines {
+		lines = append(lines, sanitizeText(line))
+	}
+	s.Logs.Publish(submissionID, lines)
+
 	return nil
 }
 
+func (s *SubmissionsService) SubscribeLogs(submissionID int64) ([]string, <-chan string, func()) {
+	return s.Logs.Subscribe(submissionID)
+}
+
+func webhookStatus(response *submissions.RunnerResponse) int {
+	switch {
+	case response.Error:
+		return submissions.GradingError
+	case response.Pass:
+		return submissions.Success
+	case response.Verdict == submissions.VerdictTimeout:
+		return submissions.TimeLimitExceeded
+	case response.Verdict == submissions.VerdictOOM:
+		return submissions.MemoryLimitExceeded
+	}
+
+	return submissions.Fail
+}
+
 func (s *SubmissionsService) GetByID(id int64) (*submissions.Submission, error) {
 	return s.Repo.GetByID(id)
 }
 
+func (s *SubmissionsService) GetTestResults(submissionID int64) ([]*submissions.TestResult, error) {
+	return s.Repo.GetTestResults(submissionID)
+}
+
+func (s *SubmissionsService) GetAttachments(submissionID int64) ([]*submissions.Attachment, error) {
+	return s.Repo.GetSubmissionAttachments(submissionID)
+}
+
+func (s *SubmissionsService) GetRuns(submissionID int64) ([]*submissions.Run, error) {
+	return s.Repo.GetRuns(submissionID)
+}
+
 func (s *SubmissionsService) Update(submission *submissions.Submission) error {
 	return s.Repo.Update(submission)
 }
@@ -77,27 +201,12 @@ func (s *SubmissionsService) GetByUserAssignment(
 	assignmentID, userID int64,
 	page int,
 ) ([]*submissions.Submission, *utils.PaginationData, error) {
-	if page <= 0 {
-		page = 1
-	}
-
 	totalCount, err := s.Repo.GetByUserAssignmentCount(assignmentID, userID)
 	if err != nil {
 		return nil, nil, err
 	}
-	maxPage := utils.GetMaxPage(DefaultPageSize, totalCount)
-	if page > maxPage {
-		page = maxPage
-	}
-	offset := utils.GetPageOffset(page, DefaultPageSize)
-	paginationData := &utils.PaginationData{
-		CurrentPage: page,
-		MaxPage:     maxPage,
-		PrevPage:    page - 1,
-		NextPage:    page + 1,
-		LastPage:    page == maxPage,
-		FirstPage:   page == 1,
-	}
+	paginationData := utils.NewPaginationData(page, DefaultPageSize, totalCount)
+	offset := utils.GetPageOffset(paginationData.CurrentPage, DefaultPageSize)
 	assignments, err := s.Repo.GetByUserAssignment(assignmentID, userID, DefaultPageSize, offset)
 	if err != nil {
 		return nil, nil, err
@@ -110,3 +219,26 @@ func (s *SubmissionsService) GetByAssignment(assignmentID int64) ([]*submissions
 	// TODO: Pagination handling
 	return s.Repo.GetByAssignment(assignmentID, 100, 0)
 }
+
+// webhookScore falls back to all-or-nothing grading when the runner doesn't report a score
+func webhookScore(submission *submissions.Submission, response *submissions.RunnerResponse) (float64, error) {
+	if response.Score == nil {
+		if response.Pass && !response.Error {
+			return submission.MaxScore, nil
+		}
+
+		return 0, nil
+	}
+
+	score := *response.Score
+	if submission.MaxScore <= 0 || math.IsNaN(score) || score < 0 || score > submission.MaxScore {
+		return 0, ErrInvalidScore
+	}
+
+	return score, nil
+}
+
+// sanitizeText fixes: pq: invalid byte sequence for encoding "UTF8"
+func sanitizeText(text string) string {
+	return strings.ToValidUTF8(strings.Replace(text, "\u0000", "", -1), "")
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/pkg/submissions"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+func TestHandleWebhookScore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := submissions.NewMockRepositoryInterface(ctrl)
+	secret := "secret"
+	service := NewSubmissionsService(repo, secret)
+	token, err := utils.AccessToken(secret, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	score := func(value float64) *float64 { return &value }
+
+	type testCase struct {
+		Title     string
+		MaxScore  float64
+		Response  *submissions.RunnerResponse
+		WantErr   error
+		WantScore float64
+	}
+
+	testCases := []*testCase{
+		{
+			Title:     "pass without score",
+			MaxScore:  10,
+			Response:  &submissions.RunnerResponse{Pass: true},
+			WantScore: 10,
+		},
+		{
+			Title:     "fail without score",
+			MaxScore:  10,
+			Response:  &submissions.RunnerResponse{Pass: false},
+			WantScore: 0,
+		},
+		{
+			Title:     "grading error",
+			MaxScore:  10,
+			Response:  &submissions.RunnerResponse{Pass: true, Error: true},
+			WantScore: 0,
+		},
+		{
+			Title:     "partial score",
+			MaxScore:  10,
+			Response:  &submissions.RunnerResponse{Pass: false, Score: score(7.5)},
+			WantScore: 7.5,
+		},
+		{
+			Title:    "score above max",
+			MaxScore: 10,
+			Response: &submissions.RunnerResponse{Pass: true, Score: score(11)},
+			WantErr:  ErrInvalidScore,
+		},
+		{
+			Title:    "negative score",
+			MaxScore: 10,
+			Response: &submissions.RunnerResponse{Pass: true, Score: score(-1)},
+			WantErr:  ErrInvalidScore,
+		},
+		{
+			Title:    "score without max score",
+			MaxScore: 0,
+			Response: &submissions.RunnerResponse{Pass: true, Score: score(1)},
+			WantErr:  ErrInvalidScore,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			submission := &submissions.Submission{ID: 1, MaxScore: testCase.MaxScore}
+			repo.EXPECT().GetByID(submission.ID).Return(submission, nil)
+			if testCase.WantErr == nil {
+				txn, dbMock := newTxn(t)
+				dbMock.ExpectCommit()
+				repo.EXPECT().CreateTxn().Return(txn, nil)
+				repo.EXPECT().Finish(txn, submission).Return(true, nil)
+			}
+
+			err := service.HandleWebhook(token, submission.ID, 0, testCase.Response)
+			if err != testCase.WantErr {
+				t.Fatalf("expected to have %v, got %v", testCase.WantErr, err)
+			}
+			if err == nil && submission.Score != testCase.WantScore {
+				t.Errorf("expected score %v, got %v", testCase.WantScore, submission.Score)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookIgnored(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := submissions.NewMockRepositoryInterface(ctrl)
+	secret := "secret"
+	service := NewSubmissionsService(repo, secret)
+	tests := []*submissions.TestResult{{Name: "test", Status: submissions.TestPassed}}
+
+	type testCase struct {
+		Title      string
+		Submission *submissions.Submission
+		RunNumber  int
+		Mock       func(*submissions.Submission)
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "finished",
+			Submission: &submissions.Submission{ID: 1, Status: submissions.Success},
+			Mock:       func(*submissions.Submission) {},
+		},
+		{
+			Title:      "superseded run",
+			Submission: &submissions.Submission{ID: 1, RunNumber: 2},
+			RunNumber:  1,
+			Mock:       func(*submissions.Submission) {},
+		},
+		{
+			Title:      "finished concurrently",
+			Submission: &submissions.Submission{ID: 1, RunNumber: 1},
+			RunNumber:  1,
+			Mock: func(submission *submissions.Submission) {
+				txn, dbMock := newTxn(t)
+				dbMock.ExpectRollback()
+				repo.EXPECT().CreateTxn().Return(txn, nil)
+				repo.EXPECT().CreateTestResults(txn, submission.ID, tests).Return(nil)
+				repo.EXPECT().Finish(txn, submission).Return(false, nil)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			token, err := utils.AccessToken(secret, submissions.RunTokenID(testCase.Submission.ID, testCase.RunNumber))
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo.EXPECT().GetByID(testCase.Submission.ID).Return(testCase.Submission, nil)
+			testCase.Mock(testCase.Submission)
+
+			err = service.HandleWebhook(
+				token,
+				testCase.Submission.ID,
+				testCase.RunNumber,
+				&submissions.RunnerResponse{Pass: true, Tests: tests},
+			)
+			if err != nil {
+				t.Errorf("expected to not have errors, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRunToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := submissions.NewMockRepositoryInterface(ctrl)
+	secret := "secret"
+	service := NewSubmissionsService(repo, secret)
+	token, err := utils.AccessToken(secret, submissions.RunTokenID(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.EXPECT().GetByID(int64(1)).Return(&submissions.Submission{ID: 1, RunNumber: 2}, nil)
+
+	err = service.HandleWebhook(token, 1, 2, &submissions.RunnerResponse{Pass: true})
+	if err != utils.ErrInvalidAccessToken {
+		t.Errorf("expected to have %v, got %v", utils.ErrInvalidAccessToken, err)
+	}
+}
+
+// newTxn starts a transaction of a mocked database, expectations of its end are set by the caller
+func newTxn(t *testing.T) (*sql.Tx, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	dbMock.ExpectBegin()
+	txn, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return txn, dbMock
+}
+
+func TestWebhookStatus(t *testing.T) {
+	type testCase struct {
+		Title    string
+		Response *submissions.RunnerResponse
+		Want     int
+	}
+
+	testCases := []*testCase{
+		{
+			Title:    "pass",
+			Response: &submissions.RunnerResponse{Pass: true},
+			Want:     submissions.Success,
+		},
+		{
+			Title:    "fail",
+			Response: &submissions.RunnerResponse{},
+			Want:     submissions.Fail,
+		},
+		{
+			Title:    "grading error",
+			Response: &submissions.RunnerResponse{Error: true, Verdict: submissions.VerdictOOM},
+			Want:     submissions.GradingError,
+		},
+		{
+			Title:    "timeout",
+			Response: &submissions.RunnerResponse{Verdict: submissions.VerdictTimeout},
+			Want:     submissions.TimeLimitExceeded,
+		},
+		{
+			Title:    "out of memory",
+			Response: &submissions.RunnerResponse{Verdict: submissions.VerdictOOM},
+			Want:     submissions.MemoryLimitExceeded,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			got := webhookStatus(testCase.Response)
+			if got != testCase.Want {
+				t.Errorf("expected status %d, got %d", testCase.Want, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	got := sanitizeText("out\u0000put\xff")
+	if got != "output" {
+		t.Errorf("expected %q, got %q", "output", got)
+	}
+}
@@ -2,9 +2,11 @@ package submissions
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
-	"github.com/maxshend/grader/pkg/attachments"
 	"github.com/maxshend/grader/pkg/repo"
 )
 
@@ -12,6 +14,21 @@ const (
 	InProgress int = iota
 	Success
 	Fail
+	GradingError
+	TimeLimitExceeded
+	MemoryLimitExceeded
+)
+
+// Verdicts reported by the runner when a submission was stopped by its limits
+const (
+	VerdictTimeout = "timeout"
+	VerdictOOM     = "oom"
+)
+
+const (
+	TestPassed  = "passed"
+	TestFailed  = "failed"
+	TestSkipped = "skipped"
 )
 
 type Submission struct {
@@ -21,7 +38,33 @@ type Submission struct {
 	UserID       int64
 	Username     string
 	Details      string
-	Attachments  []*Attachment
+	Score        float64
+	MaxScore     float64
+	Stdout       string
+	Stderr       string
+	ExitCode     *int
+	Duration     float64
+	Late         bool
+	// RepositoryURL and CommitSHA are set when the files were fetched from a git repository
+	RepositoryURL string
+	CommitSHA     string
+	// RunNumber grows on every regrade, results of the previous runs are ignored
+	RunNumber   int
+	Attachments []*Attachment
+	TestResults []*TestResult
+	CreatedAt   time.Time
+}
+
+// Run is a previous result of a submission, it is kept when the submission is regraded
+type Run struct {
+	ID           int64
+	SubmissionID int64
+	Status       int
+	Details      string
+	Score        float64
+	MaxScore     float64
+	ExitCode     *int
+	Duration     float64
 	CreatedAt    time.Time
 }
 
@@ -29,16 +72,86 @@ type Attachment struct {
 	ID   int64  `json:"-"`
 	URL  string `json:"url"`
 	Name string `json:"name"`
+	// Archive attachments are extracted by the runner into the submission directory
+	Archive bool `json:"archive,omitempty"`
+	// Entries are the files of an archive
+	Entries      []string `json:"-"`
+	SubmissionID int64    `json:"-"`
 }
 
+type TestResult struct {
+	ID       int64   `json:"-"`
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration"`
+	Message  string  `json:"message"`
+}
+
+type RunnerResponse struct {
+	Pass     bool          `json:"pass"`
+	Error    bool          `json:"error"`
+	Text     string        `json:"text"`
+	Verdict  string        `json:"verdict"`
+	Score    *float64      `json:"score"`
+	Tests    []*TestResult `json:"tests"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode *int          `json:"exit_code"`
+	Duration float64       `json:"duration"`
+}
+
+type RunnerLogs struct {
+	Lines []string `json:"lines"`
+}
+
+// ErrIdempotencyKeyUsed is returned by SetKeys when another submission of the
+// user got the key first, e.g. by a concurrent request
+var ErrIdempotencyKeyUsed = errors.New("idempotency key was used by another submission")
+
 type RepositoryInterface interface {
 	CreateTxn() (*sql.Tx, error)
-	Create(sqlExec repo.SqlQueryable, userID int64, assignmentID int64) (*Submission, error)
-	CreateSubmissionAttachments(repo.SqlQueryable, int64, []*attachments.Attachment) ([]*Attachment, error)
+	Create(
+		sqlExec repo.SqlQueryable,
+		userID int64,
+		assignmentID int64,
+		maxScore float64,
+		late bool,
+	) (*Submission, error)
+	CreateSubmissionAttachments(repo.SqlQueryable, int64, []*Attachment) ([]*Attachment, error)
 	GetSubmissionAttachments(int64) ([]*Attachment, error)
+	GetAttachmentsAfter(afterID int64, limit int) ([]*Attachment, error)
+	UpdateAttachmentURL(id int64, url string) error
+	CreateTestResults(repo.SqlQueryable, int64, []*TestResult) error
+	GetTestResults(int64) ([]*TestResult, error)
 	GetByID(int64) (*Submission, error)
 	Update(*Submission) error
+	SetSource(sqlExec repo.SqlQueryable, submissionID int64, repositoryURL string, commitSHA string) error
+	SetKeys(sqlExec repo.SqlQueryable, submissionID int64, contentHash string, idempotencyKey string) error
+	GetByContentHash(assignmentID int64, userID int64, contentHash string) (*Submission, error)
+	GetByIdempotencyKey(userID int64, idempotencyKey string) (*Submission, error)
 	GetByUserAssignment(assignmentID int64, userID int64, limit, offset int) ([]*Submission, error)
 	GetByUserAssignmentCount(assignmentID int64, userID int64) (int, error)
+	GetAttemptsCount(assignmentID int64, userID int64) (int, error)
+	// LockUser serializes new submissions of the user until the transaction ends
+	LockUser(sqlExec repo.SqlQueryable, userID int64) error
+	GetInProgressCount(sqlExec repo.SqlQueryable, assignmentID int64, userID int64, since time.Time) (int, error)
+	GetRecentCountByUser(userID int64, since time.Time) (count int, oldest time.Time, err error)
 	GetByAssignment(assignmentID int64, limit, offset int) ([]*Submission, error)
+	GetAllByAssignment(assignmentID int64, latestOnly bool) ([]*Submission, error)
+	ArchiveResult(sqlExec repo.SqlQueryable, submissionID int64) error
+	Reset(sqlExec repo.SqlQueryable, submission *Submission) error
+	// Finish stores the result of the current run, false is returned when the
+	// submission isn't in progress or was restarted since
+	Finish(sqlExec repo.SqlQueryable, submission *Submission) (bool, error)
+	GetRuns(submissionID int64) ([]*Run, error)
+}
+
+// RunTokenID identifies the run of the submission in access tokens of the runner,
+// tokens of the first run are issued for the submission ID alone
+func RunTokenID(submissionID int64, runNumber int) string {
+	if runNumber == 0 {
+		return strconv.FormatInt(submissionID, 10)
+	}
+
+	return fmt.Sprintf("%d:%d", submissionID, runNumber)
 }
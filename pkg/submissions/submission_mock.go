@@ -5,10 +5,12 @@
 package submissions
 
 import (
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	attachments "github.com/maxshend/grader/pkg/attachments"
+	repo "github.com/maxshend/grader/pkg/repo"
 )
 
 // MockRepositoryInterface is a mock of RepositoryInterface interface.
@@ -34,34 +36,137 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 	return m.recorder
 }
 
+// ArchiveResult mocks base method.
+func (m *MockRepositoryInterface) ArchiveResult(sqlExec repo.SqlQueryable, submissionID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveResult", sqlExec, submissionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveResult indicates an expected call of ArchiveResult.
+func (mr *MockRepositoryInterfaceMockRecorder) ArchiveResult(sqlExec, submissionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveResult", reflect.TypeOf((*MockRepositoryInterface)(nil).ArchiveResult), sqlExec, submissionID)
+}
+
 // Create mocks base method.
-func (m *MockRepositoryInterface) Create(userID, assignmentID int64) (*Submission, error) {
+func (m *MockRepositoryInterface) Create(sqlExec repo.SqlQueryable, userID, assignmentID int64, maxScore float64, late bool) (*Submission, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", userID, assignmentID)
+	ret := m.ctrl.Call(m, "Create", sqlExec, userID, assignmentID, maxScore, late)
 	ret0, _ := ret[0].(*Submission)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryInterfaceMockRecorder) Create(userID, assignmentID interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) Create(sqlExec, userID, assignmentID, maxScore, late interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), userID, assignmentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), sqlExec, userID, assignmentID, maxScore, late)
 }
 
 // CreateSubmissionAttachments mocks base method.
-func (m *MockRepositoryInterface) CreateSubmissionAttachments(arg0 int64, arg1 []*attachments.Attachment) ([]*Attachment, error) {
+func (m *MockRepositoryInterface) CreateSubmissionAttachments(arg0 repo.SqlQueryable, arg1 int64, arg2 []*Attachment) ([]*Attachment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSubmissionAttachments", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateSubmissionAttachments", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*Attachment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateSubmissionAttachments indicates an expected call of CreateSubmissionAttachments.
-func (mr *MockRepositoryInterfaceMockRecorder) CreateSubmissionAttachments(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) CreateSubmissionAttachments(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubmissionAttachments", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateSubmissionAttachments), arg0, arg1, arg2)
+}
+
+// CreateTestResults mocks base method.
+func (m *MockRepositoryInterface) CreateTestResults(arg0 repo.SqlQueryable, arg1 int64, arg2 []*TestResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTestResults", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTestResults indicates an expected call of CreateTestResults.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateTestResults(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubmissionAttachments", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateSubmissionAttachments), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTestResults", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateTestResults), arg0, arg1, arg2)
+}
+
+// CreateTxn mocks base method.
+func (m *MockRepositoryInterface) CreateTxn() (*sql.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTxn")
+	ret0, _ := ret[0].(*sql.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTxn indicates an expected call of CreateTxn.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateTxn() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTxn", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateTxn))
+}
+
+// Finish mocks base method.
+func (m *MockRepositoryInterface) Finish(sqlExec repo.SqlQueryable, submission *Submission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", sqlExec, submission)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockRepositoryInterfaceMockRecorder) Finish(sqlExec, submission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockRepositoryInterface)(nil).Finish), sqlExec, submission)
+}
+
+// GetAllByAssignment mocks base method.
+func (m *MockRepositoryInterface) GetAllByAssignment(assignmentID int64, latestOnly bool) ([]*Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByAssignment", assignmentID, latestOnly)
+	ret0, _ := ret[0].([]*Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByAssignment indicates an expected call of GetAllByAssignment.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAllByAssignment(assignmentID, latestOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByAssignment", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAllByAssignment), assignmentID, latestOnly)
+}
+
+// GetAttachmentsAfter mocks base method.
+func (m *MockRepositoryInterface) GetAttachmentsAfter(afterID int64, limit int) ([]*Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachmentsAfter", afterID, limit)
+	ret0, _ := ret[0].([]*Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachmentsAfter indicates an expected call of GetAttachmentsAfter.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAttachmentsAfter(afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentsAfter", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAttachmentsAfter), afterID, limit)
+}
+
+// GetAttemptsCount mocks base method.
+func (m *MockRepositoryInterface) GetAttemptsCount(assignmentID, userID int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttemptsCount", assignmentID, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttemptsCount indicates an expected call of GetAttemptsCount.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAttemptsCount(assignmentID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttemptsCount", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAttemptsCount), assignmentID, userID)
 }
 
 // GetByAssignment mocks base method.
@@ -79,6 +184,21 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetByAssignment(assignmentID, lim
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAssignment", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByAssignment), assignmentID, limit, offset)
 }
 
+// GetByContentHash mocks base method.
+func (m *MockRepositoryInterface) GetByContentHash(assignmentID, userID int64, contentHash string) (*Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByContentHash", assignmentID, userID, contentHash)
+	ret0, _ := ret[0].(*Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByContentHash indicates an expected call of GetByContentHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetByContentHash(assignmentID, userID, contentHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByContentHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByContentHash), assignmentID, userID, contentHash)
+}
+
 // GetByID mocks base method.
 func (m *MockRepositoryInterface) GetByID(arg0 int64) (*Submission, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +214,21 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetByID(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByID), arg0)
 }
 
+// GetByIdempotencyKey mocks base method.
+func (m *MockRepositoryInterface) GetByIdempotencyKey(userID int64, idempotencyKey string) (*Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIdempotencyKey", userID, idempotencyKey)
+	ret0, _ := ret[0].(*Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIdempotencyKey indicates an expected call of GetByIdempotencyKey.
+func (mr *MockRepositoryInterfaceMockRecorder) GetByIdempotencyKey(userID, idempotencyKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdempotencyKey", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByIdempotencyKey), userID, idempotencyKey)
+}
+
 // GetByUserAssignment mocks base method.
 func (m *MockRepositoryInterface) GetByUserAssignment(assignmentID, userID int64, limit, offset int) ([]*Submission, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +244,67 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetByUserAssignment(assignmentID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserAssignment", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByUserAssignment), assignmentID, userID, limit, offset)
 }
 
+// GetByUserAssignmentCount mocks base method.
+func (m *MockRepositoryInterface) GetByUserAssignmentCount(assignmentID, userID int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserAssignmentCount", assignmentID, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserAssignmentCount indicates an expected call of GetByUserAssignmentCount.
+func (mr *MockRepositoryInterfaceMockRecorder) GetByUserAssignmentCount(assignmentID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserAssignmentCount", reflect.TypeOf((*MockRepositoryInterface)(nil).GetByUserAssignmentCount), assignmentID, userID)
+}
+
+// GetInProgressCount mocks base method.
+func (m *MockRepositoryInterface) GetInProgressCount(sqlExec repo.SqlQueryable, assignmentID, userID int64, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInProgressCount", sqlExec, assignmentID, userID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInProgressCount indicates an expected call of GetInProgressCount.
+func (mr *MockRepositoryInterfaceMockRecorder) GetInProgressCount(sqlExec, assignmentID, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInProgressCount", reflect.TypeOf((*MockRepositoryInterface)(nil).GetInProgressCount), sqlExec, assignmentID, userID, since)
+}
+
+// GetRecentCountByUser mocks base method.
+func (m *MockRepositoryInterface) GetRecentCountByUser(userID int64, since time.Time) (int, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentCountByUser", userID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRecentCountByUser indicates an expected call of GetRecentCountByUser.
+func (mr *MockRepositoryInterfaceMockRecorder) GetRecentCountByUser(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentCountByUser", reflect.TypeOf((*MockRepositoryInterface)(nil).GetRecentCountByUser), userID, since)
+}
+
+// GetRuns mocks base method.
+func (m *MockRepositoryInterface) GetRuns(submissionID int64) ([]*Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuns", submissionID)
+	ret0, _ := ret[0].([]*Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuns indicates an expected call of GetRuns.
+func (mr *MockRepositoryInterfaceMockRecorder) GetRuns(submissionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuns", reflect.TypeOf((*MockRepositoryInterface)(nil).GetRuns), submissionID)
+}
+
 // GetSubmissionAttachments mocks base method.
 func (m *MockRepositoryInterface) GetSubmissionAttachments(arg0 int64) ([]*Attachment, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +320,77 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetSubmissionAttachments(arg0 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissionAttachments", reflect.TypeOf((*MockRepositoryInterface)(nil).GetSubmissionAttachments), arg0)
 }
 
+// GetTestResults mocks base method.
+func (m *MockRepositoryInterface) GetTestResults(arg0 int64) ([]*TestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTestResults", arg0)
+	ret0, _ := ret[0].([]*TestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTestResults indicates an expected call of GetTestResults.
+func (mr *MockRepositoryInterfaceMockRecorder) GetTestResults(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTestResults", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTestResults), arg0)
+}
+
+// LockUser mocks base method.
+func (m *MockRepositoryInterface) LockUser(sqlExec repo.SqlQueryable, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", sqlExec, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockRepositoryInterfaceMockRecorder) LockUser(sqlExec, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockRepositoryInterface)(nil).LockUser), sqlExec, userID)
+}
+
+// Reset mocks base method.
+func (m *MockRepositoryInterface) Reset(sqlExec repo.SqlQueryable, submission *Submission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", sqlExec, submission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockRepositoryInterfaceMockRecorder) Reset(sqlExec, submission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockRepositoryInterface)(nil).Reset), sqlExec, submission)
+}
+
+// SetKeys mocks base method.
+func (m *MockRepositoryInterface) SetKeys(sqlExec repo.SqlQueryable, submissionID int64, contentHash, idempotencyKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKeys", sqlExec, submissionID, contentHash, idempotencyKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetKeys indicates an expected call of SetKeys.
+func (mr *MockRepositoryInterfaceMockRecorder) SetKeys(sqlExec, submissionID, contentHash, idempotencyKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKeys", reflect.TypeOf((*MockRepositoryInterface)(nil).SetKeys), sqlExec, submissionID, contentHash, idempotencyKey)
+}
+
+// SetSource mocks base method.
+func (m *MockRepositoryInterface) SetSource(sqlExec repo.SqlQueryable, submissionID int64, repositoryURL, commitSHA string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSource", sqlExec, submissionID, repositoryURL, commitSHA)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSource indicates an expected call of SetSource.
+func (mr *MockRepositoryInterfaceMockRecorder) SetSource(sqlExec, submissionID, repositoryURL, commitSHA interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSource", reflect.TypeOf((*MockRepositoryInterface)(nil).SetSource), sqlExec, submissionID, repositoryURL, commitSHA)
+}
+
 // Update mocks base method.
 func (m *MockRepositoryInterface) Update(arg0 *Submission) error {
 	m.ctrl.T.Helper()
@@ -137,3 +404,17 @@ func (mr *MockRepositoryInterfaceMockRecorder) Update(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepositoryInterface)(nil).Update), arg0)
 }
+
+// UpdateAttachmentURL mocks base method.
+func (m *MockRepositoryInterface) UpdateAttachmentURL(id int64, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAttachmentURL", id, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAttachmentURL indicates an expected call of UpdateAttachmentURL.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateAttachmentURL(id, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAttachmentURL", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateAttachmentURL), id, url)
+}
@@ -0,0 +1,97 @@
+package tokens
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/users"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+type ctxKey int
+
+const TokenKey ctxKey = 1
+
+const (
+	MsgInvalidToken = "invalid access token"
+	MsgMissingScope = "access token doesn't have the required scope"
+)
+
+// APIAuthMiddleware authenticates requests with an "Authorization: Bearer" personal
+// access token, requests without the header fall back to the session cookie
+func APIAuthMiddleware(
+	sm sessions.HttpSessionManager,
+	usersRepo users.RepositoryInterface,
+	repo RepositoryInterface,
+) mux.MiddlewareFunc {
+	sessionAuth := sessions.AuthMiddleware(sm, usersRepo)
+
+	return func(next http.Handler) http.Handler {
+		withSession := sessionAuth(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if len(header) == 0 {
+				withSession.ServeHTTP(w, r)
+				return
+			}
+
+			secret := strings.TrimPrefix(header, "Bearer ")
+			if secret == header || len(secret) == 0 {
+				utils.RenderError(w, r, http.StatusUnauthorized, MsgInvalidToken)
+				return
+			}
+			token, err := repo.GetByHash(HashSecret(secret))
+			if err != nil {
+				utils.RenderInternalError(w, r, err)
+				return
+			}
+			if token == nil {
+				utils.RenderError(w, r, http.StatusUnauthorized, MsgInvalidToken)
+				return
+			}
+			user, err := usersRepo.GetByID(token.UserID)
+			if err != nil {
+				utils.RenderInternalError(w, r, err)
+				return
+			}
+			if user == nil {
+				utils.RenderError(w, r, http.StatusUnauthorized, MsgInvalidToken)
+				return
+			}
+			if err = repo.Touch(token.ID); err != nil {
+				utils.RenderInternalError(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), sessions.CurrentUserKey, user)
+			ctx = context.WithValue(ctx, TokenKey, token)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ScopeMiddleware renders forbidden when the request is authenticated with
+// a token without the scope, session cookies aren't limited by scopes
+func ScopeMiddleware(scope string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := CurrentToken(r); token != nil && !token.HasScope(scope) {
+				utils.RenderError(w, r, http.StatusForbidden, MsgMissingScope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CurrentToken returns the token the request is authenticated with, it is nil for session cookies
+func CurrentToken(r *http.Request) *Token {
+	token, _ := r.Context().Value(TokenKey).(*Token)
+
+	return token
+}
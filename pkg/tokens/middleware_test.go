@@ -0,0 +1,126 @@
+package tokens
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+type testSessionManager struct {
+	sessions.HttpSessionManager
+}
+
+func (sm *testSessionManager) Check(r *http.Request) (*sessions.Session, error) {
+	return nil, sessions.ErrUnauthenticatedUser
+}
+
+type testUsersRepo struct {
+	users.RepositoryInterface
+	user *users.User
+}
+
+func (r *testUsersRepo) GetByID(id int64) (*users.User, error) {
+	if r.user.ID != id {
+		return nil, nil
+	}
+
+	return r.user, nil
+}
+
+func TestAPIAuthMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := NewMockRepositoryInterface(ctrl)
+	user := &users.User{ID: 1}
+	token := &Token{ID: 2, UserID: user.ID, Scopes: []string{ScopeRead}}
+
+	type testCase struct {
+		Title         string
+		Authorization string
+		Scope         string
+		Mock          func()
+		WantStatus    int
+	}
+
+	testCases := []*testCase{
+		{
+			Title:         "valid token",
+			Authorization: "Bearer secret",
+			Scope:         ScopeRead,
+			Mock: func() {
+				repo.EXPECT().GetByHash(HashSecret("secret")).Return(token, nil)
+				repo.EXPECT().Touch(token.ID).Return(nil)
+			},
+			WantStatus: http.StatusOK,
+		},
+		{
+			Title:         "missing scope",
+			Authorization: "Bearer secret",
+			Scope:         ScopeSubmit,
+			Mock: func() {
+				repo.EXPECT().GetByHash(HashSecret("secret")).Return(token, nil)
+				repo.EXPECT().Touch(token.ID).Return(nil)
+			},
+			WantStatus: http.StatusForbidden,
+		},
+		{
+			Title:         "unknown token",
+			Authorization: "Bearer unknown",
+			Scope:         ScopeRead,
+			Mock: func() {
+				repo.EXPECT().GetByHash(HashSecret("unknown")).Return(nil, nil)
+			},
+			WantStatus: http.StatusUnauthorized,
+		},
+		{
+			Title:         "not a bearer token",
+			Authorization: "Basic secret",
+			Scope:         ScopeRead,
+			Mock:          func() {},
+			WantStatus:    http.StatusUnauthorized,
+		},
+		{
+			Title:      "falls back to the session cookie",
+			Scope:      ScopeRead,
+			Mock:       func() {},
+			WantStatus: http.StatusSeeOther,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			testCase.Mock()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+				if got := CurrentToken(r); got != token {
+					t.Errorf("expected token %+v, got %+v", token, got)
+				}
+				if got, _ := r.Context().Value(sessions.CurrentUserKey).(*users.User); got != user {
+					t.Errorf("expected user %+v, got %+v", user, got)
+				}
+			})
+			router.Use(
+				APIAuthMiddleware(&testSessionManager{}, &testUsersRepo{user: user}, repo),
+				ScopeMiddleware(testCase.Scope),
+			)
+
+			request := httptest.NewRequest("GET", "/api", nil)
+			if len(testCase.Authorization) > 0 {
+				request.Header.Set("Authorization", testCase.Authorization)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != testCase.WantStatus {
+				t.Errorf("expected status %d, got %d", testCase.WantStatus, response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/maxshend/grader/pkg/tokens"
+)
+
+const tokenColumns = "id, user_id, name, prefix, token_hash, scopes, last_used_at, created_at"
+
+type TokensSQLRepo struct {
+	DB *sql.DB
+}
+
+func NewTokensSQLRepo(db *sql.DB) *TokensSQLRepo {
+	return &TokensSQLRepo{DB: db}
+}
+
+func (r *TokensSQLRepo) GetByUserID(userID int64) ([]*tokens.Token, error) {
+	rows, err := r.DB.Query(
+		"SELECT "+tokenColumns+" FROM api_tokens WHERE user_id = $1 ORDER BY id DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*tokens.Token{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, token)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *TokensSQLRepo) GetByHash(hash string) (*tokens.Token, error) {
+	token, err := scanToken(r.DB.QueryRow(
+		"SELECT "+tokenColumns+" FROM api_tokens WHERE token_hash = $1 LIMIT 1",
+		hash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return token, err
+}
+
+func (r *TokensSQLRepo) Create(token *tokens.Token) (*tokens.Token, error) {
+	err := r.DB.QueryRow(
+		"INSERT INTO api_tokens (user_id, name, prefix, token_hash, scopes) "+
+			"VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		token.UserID, token.Name, token.Prefix, token.Hash, pq.Array(token.Scopes),
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *TokensSQLRepo) Delete(id, userID int64) error {
+	_, err := r.DB.Exec("DELETE FROM api_tokens WHERE id = $1 AND user_id = $2", id, userID)
+
+	return err
+}
+
+func (r *TokensSQLRepo) Touch(id int64) error {
+	_, err := r.DB.Exec("UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row scanner) (*tokens.Token, error) {
+	token := &tokens.Token{}
+	lastUsedAt := sql.NullTime{}
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.Prefix, &token.Hash,
+		pq.Array(&token.Scopes), &lastUsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return token, nil
+}
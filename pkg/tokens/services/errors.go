@@ -0,0 +1,9 @@
+package services
+
+type TokenValidationError struct {
+	Message string
+}
+
+func (e *TokenValidationError) Error() string {
+	return e.Message
+}
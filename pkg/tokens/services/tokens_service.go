@@ -0,0 +1,102 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+
+	"github.com/maxshend/grader/pkg/tokens"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+type TokensService struct {
+	Repo tokens.RepositoryInterface
+}
+
+const (
+	MsgBlankNameError    = "token name can't be blank"
+	MsgLongNameError     = "token name is too long"
+	MsgBlankScopesError  = "token should have at least one scope"
+	MsgInvalidScopeError = "token scope is invalid"
+	MsgTooManyError      = "too many tokens, revoke unused ones first"
+)
+
+const (
+	secretBytes   = 20
+	prefixLength  = len(tokens.SecretPrefix) + 6
+	maxNameLength = 255
+	maxUserTokens = 50
+)
+
+type TokensServiceInterface interface {
+	GetByUser(*users.User) ([]*tokens.Token, error)
+	// Create returns the secret of the new token, only its hash is stored
+	Create(user *users.User, name string, scopes []string) (token *tokens.Token, secret string, err error)
+	Revoke(user *users.User, id int64) error
+}
+
+func NewTokensService(repo tokens.RepositoryInterface) TokensServiceInterface {
+	return &TokensService{Repo: repo}
+}
+
+func (s *TokensService) GetByUser(user *users.User) ([]*tokens.Token, error) {
+	return s.Repo.GetByUserID(user.ID)
+}
+
+func (s *TokensService) Create(user *users.User, name string, scopes []string) (*tokens.Token, string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return nil, "", &TokenValidationError{MsgBlankNameError}
+	}
+	if len(name) > maxNameLength {
+		return nil, "", &TokenValidationError{MsgLongNameError}
+	}
+	if len(scopes) == 0 {
+		return nil, "", &TokenValidationError{MsgBlankScopesError}
+	}
+	for _, scope := range scopes {
+		if !tokens.ValidScope(scope) {
+			return nil, "", &TokenValidationError{MsgInvalidScopeError}
+		}
+	}
+
+	existing, err := s.Repo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= maxUserTokens {
+		return nil, "", &TokenValidationError{MsgTooManyError}
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := s.Repo.Create(&tokens.Token{
+		UserID: user.ID,
+		Name:   name,
+		Prefix: secret[:prefixLength],
+		Hash:   tokens.HashSecret(secret),
+		Scopes: scopes,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, secret, nil
+}
+
+func (s *TokensService) Revoke(user *users.User, id int64) error {
+	return s.Repo.Delete(id, user.ID)
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+
+	return tokens.SecretPrefix + strings.ToLower(encoded), nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maxshend/grader/pkg/tokens"
+	"github.com/maxshend/grader/pkg/users"
+)
+
+func TestTokensCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := tokens.NewMockRepositoryInterface(ctrl)
+	service := NewTokensService(repo)
+	user := &users.User{ID: 1}
+
+	type testCase struct {
+		Title   string
+		Name    string
+		Scopes  []string
+		Mock    func()
+		WantErr string
+	}
+
+	testCases := []*testCase{
+		{
+			Title:   "blank name",
+			Name:    " ",
+			Scopes:  []string{tokens.ScopeRead},
+			Mock:    func() {},
+			WantErr: MsgBlankNameError,
+		},
+		{
+			Title:   "without scopes",
+			Name:    "ci",
+			Mock:    func() {},
+			WantErr: MsgBlankScopesError,
+		},
+		{
+			Title:   "unknown scope",
+			Name:    "ci",
+			Scopes:  []string{tokens.ScopeRead, "admin"},
+			Mock:    func() {},
+			WantErr: MsgInvalidScopeError,
+		},
+		{
+			Title:  "stores the hash",
+			Name:   " ci ",
+			Scopes: []string{tokens.ScopeRead, tokens.ScopeSubmit},
+			Mock: func() {
+				repo.EXPECT().GetByUserID(user.ID).Return([]*tokens.Token{}, nil)
+				repo.EXPECT().Create(gomock.Any()).DoAndReturn(func(token *tokens.Token) (*tokens.Token, error) {
+					if token.UserID != user.ID || token.Name != "ci" {
+						t.Errorf("expected to create a token of the user, got %+v", token)
+					}
+					if !strings.HasPrefix(token.Prefix, tokens.SecretPrefix) {
+						t.Errorf("expected to have the prefix of the secret, got %q", token.Prefix)
+					}
+
+					return token, nil
+				})
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			testCase.Mock()
+
+			token, secret, err := service.Create(user, testCase.Name, testCase.Scopes)
+			if len(testCase.WantErr) > 0 {
+				if err == nil || err.Error() != testCase.WantErr {
+					t.Fatalf("expected to have error %q, got %v", testCase.WantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected to not have errors, got %v", err)
+			}
+			if token.Hash != tokens.HashSecret(secret) || strings.Contains(token.Hash, secret) {
+				t.Errorf("expected to store the hash of the secret, got %q", token.Hash)
+			}
+			if !strings.HasPrefix(secret, token.Prefix) {
+				t.Errorf("expected the secret %q to start with %q", secret, token.Prefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package tokens
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Scopes limit what a personal access token can do on top of the permissions of its user
+const (
+	ScopeRead   = "read"
+	ScopeSubmit = "submit"
+	ScopeManage = "manage"
+)
+
+var scopeDescriptions = map[string]string{
+	ScopeRead:   "Read assignments and submissions",
+	ScopeSubmit: "Submit assignments",
+	ScopeManage: "Create assignments",
+}
+
+// SecretPrefix makes tokens easy to recognize in configs and logs
+const SecretPrefix = "grader_"
+
+type Token struct {
+	ID     int64
+	UserID int64
+	Name   string
+	// Prefix is the beginning of the secret, it is shown to tell tokens apart
+	Prefix     string
+	Hash       string
+	Scopes     []string
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type RepositoryInterface interface {
+	GetByUserID(userID int64) ([]*Token, error)
+	GetByHash(hash string) (*Token, error)
+	Create(*Token) (*Token, error)
+	Delete(id, userID int64) error
+	Touch(id int64) error
+}
+
+func (t *Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ScopeDescriptions returns the human readable descriptions of the scopes
+func ScopeDescriptions() map[string]string {
+	result := make(map[string]string, len(scopeDescriptions))
+	for scope, description := range scopeDescriptions {
+		result[scope] = description
+	}
+
+	return result
+}
+
+func ValidScope(scope string) bool {
+	_, ok := scopeDescriptions[scope]
+
+	return ok
+}
+
+// HashSecret returns the value stored instead of the secret, tokens are random
+// enough to not need a slow hash and it lets tokens be looked up by the hash
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
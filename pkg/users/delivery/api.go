@@ -0,0 +1,78 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/users"
+	"github.com/maxshend/grader/pkg/users/services"
+	"github.com/maxshend/grader/pkg/utils"
+)
+
+// UsersApiHandler serves the JSON API of users under /api/v1
+type UsersApiHandler struct {
+	Service        services.UsersServiceInterface
+	SessionManager sessions.HttpSessionManager
+}
+
+type userJSON struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Role     int    `json:"role"`
+	RoleName string `json:"role_name"`
+	Provider int    `json:"provider"`
+}
+
+func NewUsersApiHandler(service services.UsersServiceInterface, sessionManager sessions.HttpSessionManager) *UsersApiHandler {
+	return &UsersApiHandler{Service: service, SessionManager: sessionManager}
+}
+
+func (h UsersApiHandler) Me(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		utils.RenderAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	utils.RenderJSON(w, http.StatusOK, newUserJSON(currentUser))
+}
+
+func (h UsersApiHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	result, paginationData, err := h.Service.GetPage(utils.GetPageNumber(r))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	data := make([]*userJSON, 0, len(result))
+	for _, user := range result {
+		data = append(data, newUserJSON(user))
+	}
+
+	utils.RenderJSON(w, http.StatusOK, &utils.APIList{Data: data, Pagination: paginationData})
+}
+
+func (h UsersApiHandler) Show(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Service.GetByID(userID(mux.Vars(r)["id"]))
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	if user == nil {
+		utils.RenderNotFound(w, r)
+		return
+	}
+
+	utils.RenderJSON(w, http.StatusOK, newUserJSON(user))
+}
+
+func newUserJSON(user *users.User) *userJSON {
+	return &userJSON{
+		ID:       user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		RoleName: user.RoleName(),
+		Provider: user.Provider,
+	}
+}
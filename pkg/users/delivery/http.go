@@ -7,6 +7,8 @@ import (
 
 	"github.com/gorilla/mux"
 	sessions "github.com/maxshend/grader/pkg/sessions"
+	"github.com/maxshend/grader/pkg/tokens"
+	tokensServices "github.com/maxshend/grader/pkg/tokens/services"
 	"github.com/maxshend/grader/pkg/users"
 	"github.com/maxshend/grader/pkg/users/services"
 	"github.com/maxshend/grader/pkg/utils"
@@ -14,17 +16,29 @@ import (
 
 type UsersHttpHandler struct {
 	Service        services.UsersServiceInterface
+	TokensService  tokensServices.TokensServiceInterface
 	SessionManager sessions.HttpSessionManager
 	Views          map[string]*utils.View
 }
 
 type userFormData struct {
 	User   *users.User
+	Roles  map[int]string
 	Errors []string
 }
 
+type profileFormData struct {
+	User   *users.User
+	Tokens []*tokens.Token
+	Scopes map[string]string
+	// NewToken is the secret of a just created token, it can't be shown again
+	NewToken string
+	Errors   []string
+}
+
 func NewUsersHttpHandler(
 	service services.UsersServiceInterface,
+	tokensService tokensServices.TokensServiceInterface,
 	sessionManager sessions.HttpSessionManager,
 	templatesFS fs.FS,
 ) (*UsersHttpHandler, error) {
@@ -53,6 +67,7 @@ func NewUsersHttpHandler(
 
 	return &UsersHttpHandler{
 		Service:        service,
+		TokensService:  tokensService,
 		Views:          views,
 		SessionManager: sessionManager,
 	}, nil
@@ -65,14 +80,7 @@ func (h UsersHttpHandler) EditProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.Views["ProfileForm"].RenderView(
-		w,
-		&userFormData{User: currentUser},
-		currentUser,
-	)
-	if err != nil {
-		utils.RenderInternalError(w, r, err)
-	}
+	h.renderProfile(w, r, currentUser, &profileFormData{User: currentUser})
 }
 
 func (h UsersHttpHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
@@ -84,14 +92,10 @@ func (h UsersHttpHandler) UpdateProfile(w http.ResponseWriter, r *http.Request)
 
 	user, err := h.Service.CheckCredentials(currentUser.Username, r.FormValue("current_password"))
 	if err != nil {
-		err := h.Views["ProfileForm"].RenderView(
-			w,
-			&userFormData{User: currentUser, Errors: []string{services.MsgInvalidCurrentPassword}},
-			currentUser,
+		h.renderProfile(
+			w, r, currentUser,
+			&profileFormData{User: currentUser, Errors: []string{services.MsgInvalidCurrentPassword}},
 		)
-		if err != nil {
-			utils.RenderInternalError(w, r, err)
-		}
 
 		return
 	}
@@ -104,14 +108,7 @@ func (h UsersHttpHandler) UpdateProfile(w http.ResponseWriter, r *http.Request)
 	)
 	if err != nil {
 		if _, ok := err.(*services.UserValidationError); ok {
-			err := h.Views["ProfileForm"].RenderView(
-				w,
-				&userFormData{User: user, Errors: []string{err.Error()}},
-				currentUser,
-			)
-			if err != nil {
-				utils.RenderInternalError(w, r, err)
-			}
+			h.renderProfile(w, r, currentUser, &profileFormData{User: user, Errors: []string{err.Error()}})
 		} else {
 			utils.RenderInternalError(w, r, err)
 		}
@@ -122,6 +119,69 @@ func (h UsersHttpHandler) UpdateProfile(w http.ResponseWriter, r *http.Request)
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+func (h UsersHttpHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, secret, err := h.TokensService.Create(currentUser, r.FormValue("name"), r.Form["scopes"])
+	if err != nil {
+		if _, ok := err.(*tokensServices.TokenValidationError); ok {
+			h.renderProfile(w, r, currentUser, &profileFormData{User: currentUser, Errors: []string{err.Error()}})
+		} else {
+			utils.RenderInternalError(w, r, err)
+		}
+
+		return
+	}
+
+	h.renderProfile(w, r, currentUser, &profileFormData{User: currentUser, NewToken: secret})
+}
+
+func (h UsersHttpHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	currentUser, err := h.SessionManager.CurrentUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tokenID, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	err = h.TokensService.Revoke(currentUser, tokenID)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+func (h UsersHttpHandler) renderProfile(
+	w http.ResponseWriter,
+	r *http.Request,
+	currentUser *users.User,
+	data *profileFormData,
+) {
+	var err error
+	data.Tokens, err = h.TokensService.GetByUser(currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+		return
+	}
+	data.Scopes = tokens.ScopeDescriptions()
+
+	err = h.Views["ProfileForm"].RenderView(w, data, currentUser)
+	if err != nil {
+		utils.RenderInternalError(w, r, err)
+	}
+}
+
 func (h UsersHttpHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	currentUser, err := h.SessionManager.CurrentUser(r)
 	if err != nil {
@@ -157,14 +217,14 @@ func (h UsersHttpHandler) Edit(w http.ResponseWriter, r *http.Request) {
 		utils.RenderInternalError(w, r, err)
 		return
 	}
-	if user == nil || user.IsAdmin {
+	if user == nil || user.IsAdmin() {
 		http.NotFound(w, r)
 		return
 	}
 
 	err = h.Views["UserForm"].RenderView(
 		w,
-		&userFormData{User: user},
+		&userFormData{User: user, Roles: users.RoleNames()},
 		currentUser,
 	)
 	if err != nil {
@@ -185,20 +245,24 @@ func (h UsersHttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.RenderInternalError(w, r, err)
 		return
 	}
-	if user == nil || user.IsAdmin {
+	if user == nil || user.IsAdmin() {
 		http.NotFound(w, r)
 		return
 	}
 
-	user.IsAdmin = utils.BoolFromParam(r.FormValue("is_admin"))
+	user.Role, err = strconv.Atoi(r.FormValue("role"))
+	if err != nil {
+		user.Role = -1
+	}
 
 	_, err = h.Service.Update(user)
 	if err != nil {
 		if _, ok := err.(*services.UserValidationError); ok {
-			err = h.Views["AssignmentForm"].RenderView(
+			err = h.Views["UserForm"].RenderView(
 				w,
 				userFormData{
 					User:   user,
+					Roles:  users.RoleNames(),
 					Errors: []string{err.Error()},
 				},
 				currentUser,
@@ -209,6 +273,8 @@ func (h UsersHttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 		} else {
 			utils.RenderInternalError(w, r, err)
 		}
+
+		return
 	}
 
 	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
@@ -14,9 +14,15 @@ func NewUsersSQLRepo(db *sql.DB) *UsersSQLRepo {
 	return &UsersSQLRepo{DB: db}
 }
 
+func (r *UsersSQLRepo) GetCount() (count int, err error) {
+	err = r.DB.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+
+	return
+}
+
 func (r *UsersSQLRepo) GetAll(limit int, offset int) ([]*users.User, error) {
 	rows, err := r.DB.Query(
-		"SELECT id, username, password, is_admin, provider "+
+		"SELECT id, username, password, role, provider "+
 			"FROM users ORDER BY id DESC LIMIT $1 OFFSET $2",
 		limit, offset,
 	)
@@ -29,7 +35,7 @@ func (r *UsersSQLRepo) GetAll(limit int, offset int) ([]*users.User, error) {
 	for rows.Next() {
 		user := &users.User{}
 		err = rows.Scan(
-			&user.ID, &user.Username, &user.Password, &user.IsAdmin, &user.Provider,
+			&user.ID, &user.Username, &user.Password, &user.Role, &user.Provider,
 		)
 		if err != nil {
 			return nil, err
@@ -44,14 +50,14 @@ func (r *UsersSQLRepo) GetAll(limit int, offset int) ([]*users.User, error) {
 	return result, nil
 }
 
-func (r *UsersSQLRepo) Create(username, password string, provider int, isAdmin bool) (*users.User, error) {
-	user := &users.User{Username: username, IsAdmin: isAdmin}
+func (r *UsersSQLRepo) Create(username, password string, provider int, role int) (*users.User, error) {
+	user := &users.User{Username: username, Role: role}
 
 	err := r.DB.QueryRow(
-		"INSERT INTO users (username, password, is_admin, provider) VALUES ($1, $2, $3, $4) RETURNING id",
+		"INSERT INTO users (username, password, role, provider) VALUES ($1, $2, $3, $4) RETURNING id",
 		username,
 		password,
-		isAdmin,
+		role,
 		provider,
 	).Scan(&user.ID)
 	if err != nil {
@@ -65,10 +71,10 @@ func (r *UsersSQLRepo) GetByID(id int64) (*users.User, error) {
 	user := &users.User{}
 
 	err := r.DB.QueryRow(
-		"SELECT id, username, is_admin, password, provider FROM users WHERE id = $1 LIMIT 1",
+		"SELECT id, username, role, password, provider FROM users WHERE id = $1 LIMIT 1",
 		id,
 	).Scan(
-		&user.ID, &user.Username, &user.IsAdmin, &user.Password, &user.Provider,
+		&user.ID, &user.Username, &user.Role, &user.Password, &user.Provider,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -85,10 +91,10 @@ func (r *UsersSQLRepo) GetByUsername(username string) (*users.User, error) {
 	user := &users.User{}
 
 	err := r.DB.QueryRow(
-		"SELECT id, username, is_admin, password, provider FROM users WHERE username = $1 LIMIT 1",
+		"SELECT id, username, role, password, provider FROM users WHERE username = $1 LIMIT 1",
 		username,
 	).Scan(
-		&user.ID, &user.Username, &user.IsAdmin, &user.Password, &user.Provider,
+		&user.ID, &user.Username, &user.Role, &user.Password, &user.Provider,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -105,11 +111,11 @@ func (r *UsersSQLRepo) GetByUsernameProvider(username string, provider int) (*us
 	user := &users.User{}
 
 	err := r.DB.QueryRow(
-		"SELECT id, username, is_admin, password, provider FROM users "+
+		"SELECT id, username, role, password, provider FROM users "+
 			"WHERE username = $1 AND provider = $2 LIMIT 1",
 		username, provider,
 	).Scan(
-		&user.ID, &user.Username, &user.IsAdmin, &user.Password, &user.Provider,
+		&user.ID, &user.Username, &user.Role, &user.Password, &user.Provider,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -124,8 +130,8 @@ func (r *UsersSQLRepo) GetByUsernameProvider(username string, provider int) (*us
 
 func (r *UsersSQLRepo) Update(user *users.User) (*users.User, error) {
 	_, err := r.DB.Exec(
-		"UPDATE users SET is_admin = $2, username = $3, password = $4 WHERE id = $1",
-		user.ID, user.IsAdmin, user.Username, user.Password,
+		"UPDATE users SET role = $2, username = $3, password = $4 WHERE id = $1",
+		user.ID, user.Role, user.Username, user.Password,
 	)
 	if err != nil {
 		return nil, err
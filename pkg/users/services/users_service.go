@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/maxshend/grader/pkg/users"
+	"github.com/maxshend/grader/pkg/utils"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
 )
@@ -18,6 +19,7 @@ type UsersServiceInterface interface {
 	CreateOauth(token *oauth2.Token, provider int) (*users.User, error)
 	GetByID(int64) (*users.User, error)
 	GetAll() ([]*users.User, error)
+	GetPage(page int) ([]*users.User, *utils.PaginationData, error)
 	GetByUsername(string) (*users.User, error)
 	CheckCredentials(username, password string) (*users.User, error)
 	Update(*users.User) (*users.User, error)
@@ -35,9 +37,12 @@ const (
 	MsgPasswordConfirmation = "Password should match password confirmation"
 	MsgUsernameBlank        = "Username should be present"
 	MsgPasswordTooShort     = "Password is too short"
+	MsgInvalidRole          = "Role is invalid"
 	MinPasswordLength       = 8
 )
 
+const DefaultPageSize = 25
+
 var (
 	MsgInvalidUserCredentials = "Invalid username or password"
 	MsgInvalidCurrentPassword = "Invalid current password"
@@ -48,6 +53,20 @@ func (s *UsersService) GetAll() ([]*users.User, error) {
 	return s.Repo.GetAll(100, 0)
 }
 
+func (s *UsersService) GetPage(page int) ([]*users.User, *utils.PaginationData, error) {
+	totalCount, err := s.Repo.GetCount()
+	if err != nil {
+		return nil, nil, err
+	}
+	paginationData := utils.NewPaginationData(page, DefaultPageSize, totalCount)
+	result, err := s.Repo.GetAll(DefaultPageSize, utils.GetPageOffset(paginationData.CurrentPage, DefaultPageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, paginationData, nil
+}
+
 func (s *UsersService) Create(username, password, password_confirmation string) (user *users.User, err error) {
 	return s.create(username, password, password_confirmation, users.DefaultProvider)
 }
@@ -110,6 +129,10 @@ func (s *UsersService) CheckCredentials(username, password string) (*users.User,
 }
 
 func (s *UsersService) Update(user *users.User) (*users.User, error) {
+	if !users.ValidRole(user.Role) {
+		return nil, &UserValidationError{MsgInvalidRole}
+	}
+
 	return s.Repo.Update(user)
 }
 
@@ -170,7 +193,7 @@ func (s *UsersService) create(username, password, password_confirmation string,
 		return nil, err
 	}
 
-	return s.Repo.Create(username, hash, provider, false)
+	return s.Repo.Create(username, hash, provider, users.StudentRole)
 }
 
 func (s *UsersService) generatePasswordHash(password string) (string, error) {
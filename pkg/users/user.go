@@ -5,19 +5,84 @@ const (
 	VkProvider
 )
 
+const (
+	StudentRole int = iota
+	AssistantRole
+	InstructorRole
+	AdminRole
+)
+
+// Permission is checked by sessions.PolicyMiddleware and templates with currentUser.Can
+type Permission string
+
+const (
+	ManageUsers        Permission = "manage_users"
+	ManageCourses      Permission = "manage_courses"
+	ManageAssignments  Permission = "manage_assignments"
+	ViewSubmissions    Permission = "view_submissions"
+	RegradeSubmissions Permission = "regrade_submissions"
+)
+
+var roleNames = map[int]string{
+	StudentRole:    "Student",
+	AssistantRole:  "Teaching Assistant",
+	InstructorRole: "Instructor",
+	AdminRole:      "Admin",
+}
+
+var rolePermissions = map[int][]Permission{
+	AssistantRole:  {ViewSubmissions, RegradeSubmissions},
+	InstructorRole: {ManageAssignments, ViewSubmissions, RegradeSubmissions},
+	AdminRole:      {ManageUsers, ManageCourses, ManageAssignments, ViewSubmissions, RegradeSubmissions},
+}
+
 type User struct {
 	ID       int64
 	Username string
 	Password string
 	Provider int
-	IsAdmin  bool
+	Role     int
 }
 
 type RepositoryInterface interface {
 	GetAll(limit int, offset int) ([]*User, error)
-	Create(username, password string, provider int, isAdmin bool) (*User, error)
+	GetCount() (int, error)
+	Create(username, password string, provider int, role int) (*User, error)
 	GetByID(id int64) (*User, error)
 	GetByUsername(username string) (*User, error)
 	GetByUsernameProvider(username string, provider int) (*User, error)
 	Update(*User) (*User, error)
 }
+
+func RoleNames() map[int]string {
+	result := make(map[int]string, len(roleNames))
+	for role, name := range roleNames {
+		result[role] = name
+	}
+
+	return result
+}
+
+func ValidRole(role int) bool {
+	_, ok := roleNames[role]
+
+	return ok
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == AdminRole
+}
+
+func (u *User) RoleName() string {
+	return roleNames[u.Role]
+}
+
+func (u *User) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[u.Role] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package users
+
+import "testing"
+
+func TestUserCan(t *testing.T) {
+	type testCase struct {
+		Title      string
+		Role       int
+		Permission Permission
+		Want       bool
+	}
+
+	testCases := []*testCase{
+		{Title: "student views submissions", Role: StudentRole, Permission: ViewSubmissions},
+		{Title: "assistant views submissions", Role: AssistantRole, Permission: ViewSubmissions, Want: true},
+		{Title: "assistant regrades", Role: AssistantRole, Permission: RegradeSubmissions, Want: true},
+		{Title: "assistant edits users", Role: AssistantRole, Permission: ManageUsers},
+		{Title: "assistant manages assignments", Role: AssistantRole, Permission: ManageAssignments},
+		{Title: "instructor manages assignments", Role: InstructorRole, Permission: ManageAssignments, Want: true},
+		{Title: "instructor edits users", Role: InstructorRole, Permission: ManageUsers},
+		{Title: "admin edits users", Role: AdminRole, Permission: ManageUsers, Want: true},
+		{Title: "unknown role", Role: -1, Permission: ViewSubmissions},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			user := &User{Role: testCase.Role}
+			if got := user.Can(testCase.Permission); got != testCase.Want {
+				t.Errorf("expected %v, got %v", testCase.Want, got)
+			}
+		})
+	}
+}
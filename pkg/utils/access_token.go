@@ -11,15 +11,18 @@ type jwtClaims struct {
 	jwt.RegisteredClaims
 }
 
-const expMinutes = 5
-
 var ErrInvalidAccessToken = errors.New("invalid access token")
 
+// AccessToken expires after DefaultTaskLifetime
 func AccessToken(secret, id string) (string, error) {
+	return ExpiringAccessToken(secret, id, DefaultTaskLifetime)
+}
+
+func ExpiringAccessToken(secret, id string, ttl time.Duration) (string, error) {
 	data := jwtClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        id,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expMinutes * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+type apiCtxKey struct{}
+
+// APIError is the body of every failed JSON API response
+type APIError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+type apiErrorBody struct {
+	Error *APIError `json:"error"`
+}
+
+// APIList is the body of JSON API responses with collections
+type APIList struct {
+	Data       any             `json:"data"`
+	Pagination *PaginationData `json:"pagination,omitempty"`
+}
+
+// APIMiddleware marks requests of the JSON API so that shared middlewares
+// render their errors as JSON instead of text or redirects
+func APIMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), apiCtxKey{}, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func IsAPIRequest(r *http.Request) bool {
+	api, _ := r.Context().Value(apiCtxKey{}).(bool)
+
+	return api
+}
+
+func RenderJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Can't encode the response: %v", err)
+	}
+}
+
+func RenderAPIError(w http.ResponseWriter, status int, message string) {
+	RenderJSON(w, status, &apiErrorBody{Error: &APIError{Status: status, Message: message}})
+}
+
+// RenderError renders a JSON error body for API requests and plain text otherwise
+func RenderError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if IsAPIRequest(r) {
+		RenderAPIError(w, status, message)
+		return
+	}
+
+	http.Error(w, message, status)
+}
+
+func RenderNotFound(w http.ResponseWriter, r *http.Request) {
+	RenderError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+}
@@ -2,25 +2,61 @@ package utils
 
 import (
 	"math"
+	"mime"
 	"net/http"
+	"path"
 	"strconv"
 )
 
 type PaginationData struct {
-	CurrentPage int
-	MaxPage     int
-	PrevPage    int
-	NextPage    int
-	LastPage    bool
-	FirstPage   bool
+	CurrentPage int  `json:"current_page"`
+	MaxPage     int  `json:"max_page"`
+	PrevPage    int  `json:"prev_page"`
+	NextPage    int  `json:"next_page"`
+	LastPage    bool `json:"last_page"`
+	FirstPage   bool `json:"first_page"`
 }
 
+// NewPaginationData clamps the page to the existing ones, the first page
+// is returned for empty collections
+func NewPaginationData(page, pageSize, totalCount int) *PaginationData {
+	maxPage := GetMaxPage(pageSize, totalCount)
+	if page > maxPage {
+		page = maxPage
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	return &PaginationData{
+		CurrentPage: page,
+		MaxPage:     maxPage,
+		PrevPage:    page - 1,
+		NextPage:    page + 1,
+		LastPage:    page >= maxPage,
+		FirstPage:   page == 1,
+	}
+}
+
+// RedirectUnauthenticated sends users to the sign in page, API clients get an error instead
 func RedirectUnauthenticated(w http.ResponseWriter, r *http.Request) {
+	if IsAPIRequest(r) {
+		RenderAPIError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
 	http.Redirect(w, r, "/signin", http.StatusSeeOther)
 }
 
 func RenderInternalError(w http.ResponseWriter, r *http.Request, err error) {
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	RenderError(w, r, http.StatusInternalServerError, err.Error())
+}
+
+// SetAttachmentHeaders makes browsers download user files instead of rendering them
+func SetAttachmentHeaders(w http.ResponseWriter, name string) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(name)}))
 }
 
 func BoolFromParam(value string) bool {
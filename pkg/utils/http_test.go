@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNewPaginationData(t *testing.T) {
+	type testCase struct {
+		Title      string
+		Page       int
+		TotalCount int
+		Want       *PaginationData
+	}
+
+	testCases := []*testCase{
+		{
+			Title:      "empty collection",
+			Page:       1,
+			TotalCount: 0,
+			Want:       &PaginationData{CurrentPage: 1, MaxPage: 0, PrevPage: 0, NextPage: 2, LastPage: true, FirstPage: true},
+		},
+		{
+			Title:      "middle page",
+			Page:       2,
+			TotalCount: 25,
+			Want:       &PaginationData{CurrentPage: 2, MaxPage: 3, PrevPage: 1, NextPage: 3},
+		},
+		{
+			Title:      "page after the last one",
+			Page:       5,
+			TotalCount: 25,
+			Want:       &PaginationData{CurrentPage: 3, MaxPage: 3, PrevPage: 2, NextPage: 4, LastPage: true},
+		},
+		{
+			Title:      "negative page",
+			Page:       -1,
+			TotalCount: 25,
+			Want:       &PaginationData{CurrentPage: 1, MaxPage: 3, PrevPage: 0, NextPage: 2, FirstPage: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Title, func(t *testing.T) {
+			got := NewPaginationData(testCase.Page, 10, testCase.TotalCount)
+			if !reflect.DeepEqual(got, testCase.Want) {
+				t.Errorf("expected %+v, got %+v", testCase.Want, got)
+			}
+		})
+	}
+}
+
+func TestSetAttachmentHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetAttachmentHeaders(w, "http://localhost/uploads/submissions/1/main file.go")
+
+	expected := `attachment; filename="main file.go"`
+	if got := w.Header().Get("Content-Disposition"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("expected to have a binary content type, got %s", got)
+	}
+}
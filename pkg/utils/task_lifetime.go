@@ -0,0 +1,27 @@
+package utils
+
+import "time"
+
+// Defaults of the grader worker, the web app needs them to sign tasks which
+// stay valid until the last attempt of the worker
+const (
+	DefaultWorkerMaxRetries    = 5
+	DefaultWorkerRetryDelay    = 10 * time.Second
+	DefaultWorkerMaxRetryDelay = 10 * time.Minute
+	DefaultWorkerJobTimeout    = 15 * time.Minute
+
+	// taskQueueAllowance covers the wait in the main queue before the first attempt
+	taskQueueAllowance = time.Hour
+)
+
+// DefaultTaskLifetime is the lifetime of tasks for the default worker settings
+var DefaultTaskLifetime = TaskLifetime(DefaultWorkerMaxRetries, DefaultWorkerMaxRetryDelay, DefaultWorkerJobTimeout)
+
+// TaskLifetime is the worst case time from queueing a task to the end of its
+// last attempt: every attempt runs until the job timeout and every retry
+// waits for the longest delay
+func TaskLifetime(maxRetries int, maxRetryDelay, jobTimeout time.Duration) time.Duration {
+	attempts := time.Duration(maxRetries + 1)
+
+	return taskQueueAllowance + attempts*jobTimeout + time.Duration(maxRetries)*maxRetryDelay
+}
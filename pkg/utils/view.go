@@ -25,20 +25,9 @@ func NewView(templatesFS fs.FS, files ...string) (*View, error) {
 
 	t, err := template.New(name).Funcs(
 		template.FuncMap{
-			"currentUser":     func() *users.User { return nil },
-			"isAuthenticated": func() bool { return false },
-			"submissionStatus": func(status int) string {
-				switch status {
-				case submissions.InProgress:
-					return "Waiting"
-				case submissions.Success:
-					return "Success"
-				case submissions.Fail:
-					return "Fail"
-				}
-
-				return "Unknown"
-			},
+			"currentUser":      func() *users.User { return nil },
+			"isAuthenticated":  func() bool { return false },
+			"submissionStatus": SubmissionStatus,
 			"userProvider": func(provider int) string {
 				switch provider {
 				case users.DefaultProvider:
@@ -60,6 +49,25 @@ func NewView(templatesFS fs.FS, files ...string) (*View, error) {
 	}, nil
 }
 
+func SubmissionStatus(status int) string {
+	switch status {
+	case submissions.InProgress:
+		return "Waiting"
+	case submissions.Success:
+		return "Success"
+	case submissions.Fail:
+		return "Fail"
+	case submissions.GradingError:
+		return "Error"
+	case submissions.TimeLimitExceeded:
+		return "Time Limit Exceeded"
+	case submissions.MemoryLimitExceeded:
+		return "Memory Limit Exceeded"
+	}
+
+	return "Unknown"
+}
+
 func (v *View) RenderView(w http.ResponseWriter, data interface{}, currentUser *users.User) error {
 	return template.Must(v.Template.Clone()).Funcs(
 		template.FuncMap{
@@ -3,17 +3,17 @@ CREATE TABLE users (
   id SERIAL PRIMARY KEY,
   username VARCHAR(255) NOT NULL,
   password VARCHAR NOT NULL,
-  is_admin BOOLEAN NOT NULL DEFAULT false,
+  role SMALLINT NOT NULL DEFAULT 0, -- 0 student, 1 assistant, 2 instructor, 3 admin
   provider SMALLINT NOT NULL DEFAULT 0,
   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
   CONSTRAINT users_username_unique UNIQUE (username)
 );
 
-INSERT INTO users (username, password, is_admin)
+INSERT INTO users (username, password, role)
   VALUES (
     'test',
     '$2a$10$NGLziTcOA8pgYkSPCQfuI.CE3Na8ENW4jExyZlE29OtmqsPJrUZfy', -- password
-    true
+    3
   );
 
 DROP TABLE IF EXISTS sessions;
@@ -25,38 +25,102 @@ CREATE TABLE sessions (
   CONSTRAINT sessions_token_unique UNIQUE (token)
 );
 
+DROP TABLE IF EXISTS api_tokens;
+CREATE TABLE api_tokens (
+  id SERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  prefix VARCHAR(32) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL, -- SHA-256 of the secret, the secret itself isn't stored
+  scopes TEXT[] NOT NULL DEFAULT '{}',
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT api_tokens_token_hash_unique UNIQUE (token_hash)
+);
+CREATE INDEX api_tokens_user_id_index ON api_tokens (user_id);
+
+DROP TABLE IF EXISTS courses;
+CREATE TABLE courses (
+  id SERIAL PRIMARY KEY,
+  creator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  title VARCHAR(255) NOT NULL,
+  description VARCHAR NOT NULL DEFAULT '',
+  invite_code VARCHAR(32) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT courses_title_unique UNIQUE (title),
+  CONSTRAINT courses_invite_code_unique UNIQUE (invite_code)
+);
+
+INSERT INTO courses (creator_id, title, description, invite_code)
+  VALUES (1, 'Golang Course', 'Grader demo course', 'GOLANG01');
+
+DROP TABLE IF EXISTS course_members;
+CREATE TABLE course_members (
+  course_id BIGINT REFERENCES courses(id) ON DELETE CASCADE,
+  user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+  role SMALLINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (course_id, user_id)
+);
+
+INSERT INTO course_members (course_id, user_id, role) VALUES (1, 1, 2);
+
 DROP TABLE IF EXISTS assignments;
 CREATE TABLE assignments (
   id SERIAL PRIMARY KEY,
   creator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  course_id BIGINT NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
   title VARCHAR(255) NOT NULL UNIQUE,
   description VARCHAR NOT NULL,
   grader_url VARCHAR(255) NOT NULL,
   container VARCHAR(255) NOT NULL,
   part_id VARCHAR(255) NOT NULL,
-  files TEXT[] NOT NULL,
+  -- file specs, a plain string is a required file
+  files JSONB NOT NULL DEFAULT '[]',
+  max_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+  memory_limit_mb BIGINT NOT NULL DEFAULT 512,
+  cpu_limit DOUBLE PRECISION NOT NULL DEFAULT 1,
+  pids_limit BIGINT NOT NULL DEFAULT 256,
+  tmpfs_size_mb BIGINT NOT NULL DEFAULT 64,
+  timeout_seconds BIGINT NOT NULL DEFAULT 300,
+  hide_stderr BOOLEAN NOT NULL DEFAULT FALSE,
+  archive BOOLEAN NOT NULL DEFAULT FALSE,
+  allow_git BOOLEAN NOT NULL DEFAULT FALSE,
+  -- 0 means unlimited attempts and no cooldown between submissions
+  max_attempts INTEGER NOT NULL DEFAULT 0,
+  cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+  -- new submissions are rejected while the previous one of the student is graded unless it's set
+  allow_parallel BOOLEAN NOT NULL DEFAULT FALSE,
+  published BOOLEAN NOT NULL DEFAULT FALSE,
+  opens_at TIMESTAMP WITH TIME ZONE,
+  due_at TIMESTAMP WITH TIME ZONE,
+  late_due_at TIMESTAMP WITH TIME ZONE,
   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
   CONSTRAINT assignments_title_unique UNIQUE (title)
 );
 
-INSERT INTO assignments (title, description, grader_url, container, part_id, files)
+INSERT INTO assignments (course_id, title, description, grader_url, container, part_id, files, published)
   VALUES (
+    1,
     'Grader Go #1',
     'Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.',
     'http://runner:8021/api/v1/grader',
     'golangcourse_final',
     'HW1_game_go',
-    '{"main.go"}'
+    '["main.go"]',
+    true
   );
 
-INSERT INTO assignments (title, description, grader_url, container, part_id, files)
+INSERT INTO assignments (course_id, title, description, grader_url, container, part_id, files, published)
   VALUES (
+    1,
     'Grader Ruby #1',
     'Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.',
     'http://runner:8021/api/v1/grader',
     'golangcourse_final',
     'HW1_game_rb',
-    '{"main.rb"}'
+    '["main.rb"]',
+    true
   );
 
 DROP TABLE IF EXISTS submissions;
@@ -66,14 +130,72 @@ CREATE TABLE submissions (
   assignment_id BIGINT REFERENCES assignments(id) ON DELETE SET NULL,
   status SMALLINT NOT NULL DEFAULT 0,
   details VARCHAR,
+  score DOUBLE PRECISION,
+  max_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+  stdout TEXT NOT NULL DEFAULT '',
+  stderr TEXT NOT NULL DEFAULT '',
+  exit_code INTEGER,
+  duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+  late BOOLEAN NOT NULL DEFAULT FALSE,
+  repository_url VARCHAR NOT NULL DEFAULT '',
+  commit_sha VARCHAR(64) NOT NULL DEFAULT '',
+  -- incremented on every regrade so that results of the previous runs are ignored
+  run_number INTEGER NOT NULL DEFAULT 0,
+  -- SHA-256 of the files and the grading settings, verdicts of identical submissions are reused
+  content_hash VARCHAR(64) NOT NULL DEFAULT '',
+  idempotency_key VARCHAR(255) NOT NULL DEFAULT '',
   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX submissions_user_id_created_at_index ON submissions (user_id, created_at);
+CREATE INDEX submissions_content_hash_index ON submissions (assignment_id, user_id, content_hash);
+CREATE UNIQUE INDEX submissions_idempotency_key_index ON submissions (user_id, idempotency_key)
+  WHERE idempotency_key <> '';
+
 DROP TABLE IF EXISTS submission_attachments;
 CREATE TABLE submission_attachments (
   id SERIAL PRIMARY KEY,
   url VARCHAR NOT NULL,
   name VARCHAR(255) NOT NULL,
+  -- files of an archive, NULL for regular attachments
+  entries TEXT[],
+  submission_id BIGINT REFERENCES submissions(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TABLE IF EXISTS assignment_validations;
+CREATE TABLE assignment_validations (
+  id SERIAL PRIMARY KEY,
+  assignment_id BIGINT NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+  kind SMALLINT NOT NULL, -- 0 reference solution, 1 known-bad solution
+  submission_id BIGINT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT assignment_validations_kind_unique UNIQUE (assignment_id, kind)
+);
+
+DROP TABLE IF EXISTS submission_runs;
+CREATE TABLE submission_runs (
+  id SERIAL PRIMARY KEY,
+  submission_id BIGINT REFERENCES submissions(id) ON DELETE CASCADE,
+  status SMALLINT NOT NULL,
+  details VARCHAR,
+  score DOUBLE PRECISION,
+  max_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+  stdout TEXT NOT NULL DEFAULT '',
+  stderr TEXT NOT NULL DEFAULT '',
+  exit_code INTEGER,
+  duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TABLE IF EXISTS submission_results;
+CREATE TABLE submission_results (
+  id SERIAL PRIMARY KEY,
   submission_id BIGINT REFERENCES submissions(id) ON DELETE CASCADE,
+  run_id BIGINT REFERENCES submission_runs(id) ON DELETE CASCADE,
+  name VARCHAR NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+  message VARCHAR NOT NULL DEFAULT '',
   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );